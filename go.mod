@@ -7,6 +7,7 @@ toolchain go1.24.0
 require (
 	github.com/chromedp/cdproto v0.0.0-20250403032234-65de8f5d025b
 	github.com/chromedp/chromedp v0.13.7
+	github.com/fsnotify/fsnotify v1.9.0
 	golang.org/x/term v0.34.0
 )
 
@@ -2,7 +2,12 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/chatgpt-element-recorder/pkg/browser"
@@ -14,28 +19,94 @@ import (
 )
 
 func main() {
+	defer func() {
+		if r := recover(); r != nil {
+			ui.RestoreTerminal()
+			panic(r)
+		}
+	}()
+
+	args, err := cli.ParseArgs()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+	// Help, version and config conversion don't need a browser
+	if args.Help || args.Version || args.ConvertTo != "" {
+		if err := cli.ExecuteWithArgs(args, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Piped and JSON runs keep stdout for the responses alone
+	quiet := cli.StdinPiped() || args.OutputFormat == "json"
+	ui.SetQuiet(args.OutputFormat == "json")
+
 	// Print banner
-	ui.PrintBanner()
+	if !quiet {
+		ui.PrintBanner()
+	}
 
 	// --- Unified startup process with single progress indicator ---
 	spinner := ui.NewSquareSpinner()
-	spinner.Start("Initializing ChatGPT CLI...")
+	if !quiet {
+		spinner.Start("Initializing ChatGPT CLI...")
+	}
 
 	// Browser setup
+	cfg, _ := config.LoadDynamicConfig()
+	readOnly := args.Safe || cfg.Agent.ReadOnly
+	headless := true
 	opts := append(chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.Flag("headless", true), // 
+		chromedp.Flag("headless", headless),
 		chromedp.Flag("enable-automation", false), // Critical!
 		chromedp.Flag("disable-extensions", false),
 		chromedp.Flag("disable-blink-features", "AutomationControlled"), // Critical!
 		chromedp.Flag("window-size", "1920,1080"),
 		chromedp.UserAgent(`Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36`),
 	)
+	if cfg.Browser.ProxyServer != "" {
+		opts = append(opts, chromedp.ProxyServer(cfg.Browser.ProxyServer))
+		if cfg.Browser.ProxyBypass != "" {
+			opts = append(opts, chromedp.Flag("proxy-bypass-list", cfg.Browser.ProxyBypass))
+		}
+	}
 	allocCtx, allocCancel := chromedp.NewExecAllocator(context.Background(), opts...)
 	defer allocCancel()
 
 	ctx, ctxCancel := chromedp.NewContext(allocCtx)
 	defer ctxCancel()
 
+	// Ctrl+C or a kill can land mid-spinner, mid-typing or in raw mode; put
+	// the terminal back, save the session once the CLI is running and close
+	// the browser before exiting
+	var onInterrupt atomic.Value // func(), set once the interactive CLI starts
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		ui.RestoreTerminal()
+		fmt.Println()
+		if save, ok := onInterrupt.Load().(func()); ok {
+			save()
+		}
+		ctxCancel()
+		allocCancel()
+		os.Exit(130)
+	}()
+
+	// Proxy credentials can't be passed as flags; answer the proxy's challenges instead
+	if auth := cfg.Browser.ProxyAuth; cfg.Browser.ProxyServer != "" && auth.Username != "" {
+		if err := chromedp.Run(ctx, browser.ProxyAuthAction(auth.Username, auth.Password)); err != nil {
+			spinner.Stop()
+			ui.PrintError("Failed to set up proxy authentication")
+			reportScreenshot(ctx, readOnly)
+			log.Fatalf("Proxy error: %v", err)
+		}
+	}
+
 	// Load cookies
 	spinner.Update("Loading saved session...")
 	time.Sleep(500 * time.Millisecond) // Brief pause for smooth transition
@@ -50,30 +121,33 @@ func main() {
 	if err := chromedp.Run(ctx, chromedp.Navigate(targetURL)); err != nil {
 		spinner.Stop()
 		ui.PrintError("Failed to connect to ChatGPT")
+		reportScreenshot(ctx, readOnly)
 		log.Fatalf("Navigation error: %v", err)
 	}
 
-	// Reload technique for stability
-	spinner.Update("Optimizing connection...")
-	time.Sleep(3 * time.Second)
-	if err := chromedp.Run(ctx, chromedp.Reload()); err != nil {
-		spinner.Stop()
-		ui.PrintError("Connection optimization failed")
-		log.Fatalf("Reload error: %v", err)
-	}
-
-	// Wait for ChatGPT to load
+	// Wait for the prompt input, reloading once if the first load comes up blank
 	spinner.Update("Verifying interface...")
-	time.Sleep(300 * time.Millisecond) // Brief pause for smooth transition
-	if err := chromedp.Run(ctx, browser.WaitForChatGPTLoad()); err != nil {
+	waitTimeout := time.Duration(cfg.ChatGPT.WaitTimeout) * time.Second
+	if args.TimeoutSet {
+		waitTimeout = time.Duration(args.Timeout) * time.Second
+	}
+	if err := chromedp.Run(ctx, browser.WaitForChatGPTReady(chatgpt.InputSelector(), waitTimeout)); err != nil {
 		spinner.Stop()
 		ui.PrintWarning("Interface verification incomplete - please ensure you're logged in")
 		ui.PrintInfo("You may need to login manually in the browser window")
+		reportScreenshot(ctx, readOnly)
 		return
 	}
 
 	// Create ChatGPT client and final checks
 	chatgptClient := chatgpt.NewChatGPT(ctx)
+	chatgptClient.SetHeadless(headless)
+	if args.TimeoutSet {
+		chatgptClient.SetTimeout(args.Timeout)
+	}
+	if err := chatgptClient.EnsureChatGPTTab(); err != nil {
+		ui.PrintWarning("Could not verify the ChatGPT tab - use /tabs to check")
+	}
 	spinner.Update("Finalizing setup...")
 	time.Sleep(300 * time.Millisecond) // Brief pause for smooth transition
 	if err := chatgptClient.WaitForPageLoad(); err != nil {
@@ -81,14 +155,54 @@ func main() {
 	}
 
 	spinner.Stop()
-	ui.PrintSuccess("GPT5-DEV Agent CLI ready! 🚀")
+	if !quiet {
+		ui.PrintSuccess("GPT5-DEV Agent CLI ready! 🚀")
+	}
 
 	// Create and start CLI
 	cliApp := cli.NewCLI(chatgptClient)
+	cliApp.SetInitialPrompt(args.Ask)
+	if args.Safe {
+		cliApp.SetReadOnly(true)
+	}
+	if args.Yes {
+		ui.SetAutoConfirm(true)
+	}
+
+	// Non-interactive modes run through the argument executor
+	if args.Mode != "interactive" {
+		if err := cli.ExecuteWithArgs(args, cliApp); err != nil {
+			ui.PrintError(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Start the CLI interface
+	onInterrupt.Store(cliApp.Interrupted)
 	if err := cliApp.Start(); err != nil {
 		ui.PrintError("CLI error occurred")
 		log.Fatalf("CLI error: %v", err)
 	}
 }
+
+// screenshotTimeout bounds the screenshot taken after a failed browser step,
+// which may find the browser unresponsive
+const screenshotTimeout = 10 * time.Second
+
+// reportScreenshot saves a screenshot of the page after a browser step has
+// failed and prints where it is, so bug reports can include it. Read-only
+// mode writes no files, screenshots included.
+func reportScreenshot(ctx context.Context, readOnly bool) {
+	if readOnly {
+		return
+	}
+	shotCtx, cancel := context.WithTimeout(ctx, screenshotTimeout)
+	defer cancel()
+	path, err := browser.SaveScreenshot(shotCtx, "")
+	if err != nil {
+		ui.PrintWarning(fmt.Sprintf("Could not take a screenshot: %v", err))
+		return
+	}
+	ui.PrintInfo(fmt.Sprintf("Screenshot of the browser saved to %s - include it in bug reports", path))
+}
@@ -1,8 +1,12 @@
 package agent
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/chatgpt-element-recorder/pkg/chatgpt"
 	"github.com/chatgpt-element-recorder/pkg/config"
@@ -11,11 +15,18 @@ import (
 
 // Agent represents the main agent system
 type Agent struct {
-	chatgpt   *chatgpt.ChatGPT
-	config    *config.DynamicConfig
-	mode      AgentMode
-	context   *ProjectContext
-	fileOps   *FileOperations
+	chatgpt      *chatgpt.ChatGPT
+	config       *config.DynamicConfig
+	mode         AgentMode
+	context      *ProjectContext
+	fileOps      *FileOperations
+	pipeline     *PromptPipeline
+	pinned       map[string]bool // working-set files attached to every prompt
+	lastActivity time.Time       // when a message was last processed
+	systemPrompt string          // user-set context put before every prompt
+	cache        *LRUCache       // responses to messages already sent, cleared with each new chat
+	cacheChat    int             // ChatSwitches when the cache was last cleared
+	lastCached   bool            // the last ProcessMessage answer came from the cache
 }
 
 // AgentMode represents different operation modes
@@ -28,6 +39,27 @@ const (
 	ContextMode     AgentMode = "context"
 )
 
+// Modes lists every agent mode with a short description, in display order
+var Modes = []struct {
+	Mode        AgentMode
+	Description string
+}{
+	{InteractiveMode, "Send messages as typed (default)"},
+	{QueryMode, "Single questions answered on their own"},
+	{AutoMode, "Autonomous task execution"},
+	{ContextMode, "Messages enriched with project context"},
+}
+
+// ParseMode returns the mode with the given name
+func ParseMode(name string) (AgentMode, bool) {
+	for _, m := range Modes {
+		if string(m.Mode) == strings.ToLower(name) {
+			return m.Mode, true
+		}
+	}
+	return "", false
+}
+
 // NewAgent creates a new agent instance
 func NewAgent(chatgptClient *chatgpt.ChatGPT) (*Agent, error) {
 	config, err := config.LoadDynamicConfig()
@@ -41,28 +73,119 @@ func NewAgent(chatgptClient *chatgpt.ChatGPT) (*Agent, error) {
 		mode:    InteractiveMode,
 		fileOps: NewFileOperations(),
 	}
+	agent.fileOps.SetReadOnly(config.Agent.ReadOnly)
+	agent.fileOps.SetAllowWrite(config.Agent.AllowWrite)
+	agent.fileOps.SetMaxContentMatches(config.Agent.MaxContentMatches)
+	agent.cache = NewLRUCache(config.Agent.CacheSize)
 
 	// Initialize project context if enabled
 	if config.Agent.ProjectAnalysis {
 		agent.context = NewProjectContext()
 	}
+	agent.pipeline = newPromptPipeline(agent, config.Agent.PromptPipeline)
+
+	agent.watchConfig()
 
 	return agent, nil
 }
 
+// watchConfig applies edits to the config, selectors and prompts files
+// without a restart, each time config.ApplyReloads is called between messages
+func (a *Agent) watchConfig() {
+	err := config.WatchConfig(context.Background(), func(updated *config.DynamicConfig) {
+		a.UpdateConfig(updated)
+	})
+	if err != nil {
+		ui.PrintWarning(fmt.Sprintf("Config changes will need a restart: %v", err))
+	}
+}
+
 // SetMode changes the agent's operation mode
 func (a *Agent) SetMode(mode AgentMode) {
 	a.mode = mode
 	ui.PrintInfo(fmt.Sprintf("Agent mode set to: %s", mode))
 }
 
+// SetReadOnly turns safe mode on or off. Reads keep working; writes fail with ErrReadOnly.
+func (a *Agent) SetReadOnly(readOnly bool) {
+	a.fileOps.SetReadOnly(readOnly)
+}
+
+// IsReadOnly reports whether file writes are disabled
+func (a *Agent) IsReadOnly() bool {
+	return a.fileOps.readOnly
+}
+
 // GetMode returns the current agent mode
 func (a *Agent) GetMode() AgentMode {
 	return a.mode
 }
 
-// ProcessMessage processes a message based on the current mode
+// ProcessMessage processes a message based on the current mode. A prompt
+// already sent in this chat, once prepared the same way in the same mode, is
+// answered from the cache when agent.cache_enabled is set.
 func (a *Agent) ProcessMessage(message string) (string, error) {
+	a.lastActivity = time.Now()
+	a.lastCached = false
+
+	// Auto mode runs commands and edits files, so its plans always run again
+	if !a.config.Agent.CacheEnabled || a.mode == AutoMode {
+		return a.process(message)
+	}
+
+	// Answers belong to the chat they were given in
+	if switches := a.chatgpt.ChatSwitches(); switches != a.cacheChat {
+		a.ClearCache()
+		a.cacheChat = switches
+	}
+
+	// The prepared prompt carries attached files, pins and project context,
+	// so a change to any of them is a new prompt
+	prompt := a.PreparePrompt(message)
+	key := responseCacheKey(a.mode, prompt)
+	if response, ok := a.cache.Get(key); ok {
+		ui.PrintInfo("[cached]")
+		a.lastCached = true
+		return response, nil
+	}
+	response, err := a.chatgpt.SendMessage(prompt)
+	if err == nil {
+		a.cache.Put(key, response)
+	}
+	return response, err
+}
+
+// ProcessPrompt sends a prompt a command built, such as a file or command
+// output to discuss, through the prompt pipeline. Unlike ProcessMessage it is
+// never planned, since only goals the user types are meant for auto mode.
+func (a *Agent) ProcessPrompt(prompt string) (string, error) {
+	if a.mode != AutoMode {
+		return a.ProcessMessage(prompt)
+	}
+	a.lastActivity = time.Now()
+	a.lastCached = false
+	return a.processInteractive(prompt)
+}
+
+// LastAnswerCached reports whether the last message was answered from the
+// cache rather than sent
+func (a *Agent) LastAnswerCached() bool {
+	return a.lastCached
+}
+
+// HandlesRateLimits reports whether ProcessMessage waits out rate limits
+// itself, as auto mode does for the plan and for each of its steps
+func (a *Agent) HandlesRateLimits() bool {
+	return a.mode == AutoMode
+}
+
+// ClearCache forgets every cached response, as starting a new chat does
+func (a *Agent) ClearCache() {
+	a.cache.Clear()
+}
+
+// process sends a message the way the current mode handles it
+func (a *Agent) process(message string) (string, error) {
 	switch a.mode {
 	case InteractiveMode:
 		return a.processInteractive(message)
@@ -77,15 +200,45 @@ func (a *Agent) ProcessMessage(message string) (string, error) {
 	}
 }
 
+// LastActivity returns when a message was last processed; zero if none has been
+func (a *Agent) LastActivity() time.Time {
+	return a.lastActivity
+}
+
+// SetSystemPrompt sets the context put before every prompt; empty clears it
+func (a *Agent) SetSystemPrompt(prompt string) {
+	a.systemPrompt = strings.TrimSpace(prompt)
+}
+
+// SystemPrompt returns the context put before every prompt, if any
+func (a *Agent) SystemPrompt() string {
+	return a.systemPrompt
+}
+
+// PreparePrompt runs a prompt through the configured prompt pipeline and puts
+// the system prompt, if one is set, before it
+func (a *Agent) PreparePrompt(message string) string {
+	prompt := a.pipeline.Apply(message)
+	if a.systemPrompt != "" {
+		prompt = "System context: " + a.systemPrompt + "\n\n" + prompt
+	}
+	return prompt
+}
+
+// send prepares a prompt and sends it to ChatGPT
+func (a *Agent) send(message string) (string, error) {
+	return a.chatgpt.SendMessage(a.PreparePrompt(message))
+}
+
 // processInteractive handles interactive mode (default behavior)
 func (a *Agent) processInteractive(message string) (string, error) {
-	return a.chatgpt.SendMessage(message)
+	return a.send(message)
 }
 
 // processQuery handles single query mode
 func (a *Agent) processQuery(message string) (string, error) {
 	// For query mode, we might want to add specific formatting
-	response, err := a.chatgpt.SendMessage(message)
+	response, err := a.send(message)
 	if err != nil {
 		return "", err
 	}
@@ -96,19 +249,14 @@ func (a *Agent) processQuery(message string) (string, error) {
 
 // processAuto handles autonomous mode
 func (a *Agent) processAuto(message string) (string, error) {
-	// Auto mode could include task breakdown, planning, etc.
-	// For now, delegate to interactive mode
-	return a.processInteractive(message)
+	// The goal is planned into subtasks, confirmed, then run step by step
+	return a.runPlan(message)
 }
 
 // processWithContext handles context-aware processing
 func (a *Agent) processWithContext(message string) (string, error) {
-	if a.context != nil {
-		// Enhance message with project context
-		contextualMessage := a.context.EnhanceMessage(message)
-		return a.chatgpt.SendMessage(contextualMessage)
-	}
-	return a.processInteractive(message)
+	// Project context is added by the "context" pipeline stage
+	return a.send(message)
 }
 
 // InitializeSession sets up the agent session with project context
@@ -128,8 +276,8 @@ func (a *Agent) InitializeSession() error {
 	spinner := ui.NewSquareSpinner()
 	spinner.Start("Analyzing project and setting up context...")
 	
-	// Send system prompt
-	_, err = a.chatgpt.SendMessage(systemPrompt)
+	// Send system prompt; its greeting is never printed
+	_, err = a.chatgpt.SendQuiet(systemPrompt)
 	spinner.Stop()
 	
 	if err != nil {
@@ -137,7 +285,9 @@ func (a *Agent) InitializeSession() error {
 		return err
 	}
 	
-	ui.PrintSuccess("Project context established! 🎯")
+	if !a.config.UI.QuietSystemPrompt {
+		ui.PrintSuccess("Project context established! 🎯")
+	}
 	return nil
 }
 
@@ -179,20 +329,120 @@ func (a *Agent) StartNewChat() error {
 	if err != nil {
 		return err
 	}
+	a.ClearCache()
 	
 	// Re-initialize session with context
 	return a.InitializeSession()
 }
 
+// summarizePrompt asks ChatGPT for a summary that can seed a fresh chat
+const summarizePrompt = `Summarize our conversation so far so that it can be continued in a new chat. Include the goal, the decisions made, relevant file names and code details, and any open questions or next steps. Be concise but complete.`
+
+// ShouldRotateChat reports whether sending message would take the active chat
+// past the auto-rotate threshold. A threshold of zero or less turns rotation
+// off, as it would otherwise rotate before every message.
+func (a *Agent) ShouldRotateChat(message string) bool {
+	agentConfig := a.config.Agent
+	if !agentConfig.AutoRotateChat || agentConfig.RotateThreshold <= 0 {
+		return false
+	}
+	return a.chatgpt.EstimatedContextTokens()+chatgpt.EstimateTokens(message) >= agentConfig.RotateThreshold
+}
+
+// RotateChat summarizes the current conversation, starts a new chat and seeds
+// it with the summary so the session continues without the old context
+func (a *Agent) RotateChat() error {
+	summary, err := a.chatgpt.SendMessage(summarizePrompt)
+	if err != nil {
+		return fmt.Errorf("failed to summarize conversation: %v", err)
+	}
+
+	if err := a.chatgpt.StartNewChat(); err != nil {
+		return err
+	}
+	a.ClearCache()
+
+	seed := fmt.Sprintf("We are continuing a previous conversation that grew too long. Here is a summary of it:\n\n%s\n\nPlease acknowledge briefly and continue from there.", summary)
+	if _, err := a.chatgpt.SendMessage(seed); err != nil {
+		return fmt.Errorf("failed to seed new chat: %v", err)
+	}
+	return nil
+}
+
+// branchBudget caps the characters of the original conversation carried into a branch
+const branchBudget = 24000
+
+// branchPrompt seeds a branch with the original transcript and the new direction
+const branchPrompt = `This chat branches off an earlier conversation. Here it is:
+
+%s
+
+Briefly summarize where that conversation stood, then continue from there in this new direction instead:
+
+%s`
+
+// BranchChat starts a new chat seeded with the current conversation and a new
+// direction, and returns the reply. The transcript is read from the page
+// rather than summarized in place, so the original chat is left untouched.
+func (a *Agent) BranchChat(direction string) (string, error) {
+	turns, err := a.chatgpt.CurrentConversation()
+	if err != nil {
+		return "", fmt.Errorf("failed to read conversation: %v", err)
+	}
+	if len(turns) == 0 {
+		return "", fmt.Errorf("the current chat has no messages to branch from")
+	}
+
+	if err := a.chatgpt.StartNewChat(); err != nil {
+		return "", err
+	}
+	return a.chatgpt.SendMessage(fmt.Sprintf(branchPrompt, recentTranscript(turns, branchBudget), direction))
+}
+
+// recentTranscript renders the latest turns that fit in budget characters,
+// noting when earlier ones were left out
+func recentTranscript(turns []chatgpt.Turn, budget int) string {
+	var parts []string
+	used := 0
+	for i := len(turns) - 1; i >= 0; i-- {
+		speaker := "User"
+		if turns[i].Role == "assistant" {
+			speaker = "Assistant"
+		}
+		part := fmt.Sprintf("%s: %s", speaker, turns[i].Text)
+		if used+len(part) > budget && len(parts) > 0 {
+			parts = append(parts, "[earlier messages omitted]")
+			break
+		}
+		parts = append(parts, part)
+		used += len(part)
+	}
+
+	// Collected newest first
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// ChatInfo describes the chat the agent is sending to
+func (a *Agent) ChatInfo() (chatgpt.ChatInfo, error) {
+	return a.chatgpt.GetChatInfo()
+}
+
 // GetConfig returns the agent's configuration
 func (a *Agent) GetConfig() *config.DynamicConfig {
 	return a.config
 }
 
-// UpdateConfig updates the agent's configuration
+// UpdateConfig switches the agent to a new configuration and rebuilds the
+// prompt pipeline from it. Read-only mode is left as is so a reload can't
+// turn off --safe; use SaveConfig to persist a configuration.
 func (a *Agent) UpdateConfig(newConfig *config.DynamicConfig) error {
 	a.config = newConfig
-	return newConfig.SaveConfig()
+	a.pipeline = newPromptPipeline(a, newConfig.Agent.PromptPipeline)
+	a.cache.SetCapacity(newConfig.Agent.CacheSize)
+	return nil
 }
 
 // GetProjectContext returns the current project context
@@ -225,82 +475,206 @@ func (a *Agent) SearchFiles(pattern string) ([]FileInfo, error) {
 	return a.fileOps.SearchFiles(pattern)
 }
 
+// SearchContent searches the contents of project files for a pattern
+func (a *Agent) SearchContent(pattern string, caseSensitive bool) ([]ContentMatch, error) {
+	return a.fileOps.SearchContent(pattern, caseSensitive)
+}
+
 // ReadMultipleFiles reads multiple files and returns their content
 func (a *Agent) ReadMultipleFiles(filenames []string) (map[string]string, error) {
 	return a.fileOps.ReadMultipleFiles(filenames)
 }
 
+// HeadFile returns the first n lines of a file
+func (a *Agent) HeadFile(filename string, n int) (string, error) {
+	return a.fileOps.HeadFile(filename, n)
+}
+
+// TailFile returns the last n lines of a file
+func (a *Agent) TailFile(filename string, n int) (string, error) {
+	return a.fileOps.TailFile(filename, n)
+}
+
+// ReadFileRange returns a numbered range of lines from a file
+func (a *Agent) ReadFileRange(filename string, start, end int) (string, error) {
+	return a.fileOps.ReadFileRange(filename, start, end)
+}
+
+// ExcerptFile returns the first and last n lines of a file, noting how many were left out
+func (a *Agent) ExcerptFile(filename string, n int) (string, error) {
+	return a.fileOps.ExcerptFile(filename, n)
+}
+
+// FileExists reports whether a file exists inside the working directory
+func (a *Agent) FileExists(filename string) bool {
+	return a.fileOps.Exists(filename)
+}
+
+// WriteFile writes content to a file in the working directory
+func (a *Agent) WriteFile(filename, content string) error {
+	return a.fileOps.WriteFile(filename, content)
+}
+
+// ApplyDiff applies a unified diff to a file inside the working directory
+func (a *Agent) ApplyDiff(filename, unifiedDiff string) error {
+	return a.fileOps.ApplyDiff(filename, unifiedDiff)
+}
+
 // GetFileTree returns a tree structure of the project
 func (a *Agent) GetFileTree(maxDepth int) (string, error) {
 	return a.fileOps.GetFileTree(maxDepth)
 }
 
-// ProcessFileQuery processes queries related to file operations
-func (a *Agent) ProcessFileQuery(query string) (string, error) {
-	// Detect file-related queries and provide appropriate responses
-	lowerQuery := strings.ToLower(query)
-	
-	// Check for file reading requests
-	if strings.Contains(lowerQuery, "read file") || strings.Contains(lowerQuery, "show me") {
-		return a.handleFileReadRequest(query)
+// ReconcileFiles sends two files to ChatGPT asking for a single merged version.
+// It returns the full response and the merged file taken from its code blocks,
+// which is empty when ChatGPT did not reply with code.
+func (a *Agent) ReconcileFiles(first, second string) (string, string, error) {
+	// Read individually so a missing file fails fast instead of being sent as text
+	contents := make(map[string]string)
+	for _, filename := range []string{first, second} {
+		content, err := a.ReadFile(filename)
+		if err != nil {
+			return "", "", err
+		}
+		contents[filename] = content
 	}
-	
-	// Check for file listing requests
-	if strings.Contains(lowerQuery, "list files") || strings.Contains(lowerQuery, "show files") {
-		return a.handleFileListRequest(query)
+
+	prompt := fmt.Sprintf(`I'm refactoring and need to reconcile two files into one.
+
+File 1: %s
+`+"```"+`
+%s
+`+"```"+`
+
+File 2: %s
+`+"```"+`
+%s
+`+"```"+`
+
+Please reconcile these into one file that keeps the behaviour of both. Reply with a short summary of the decisions you made, followed by the complete merged file in a single code block.`, first, contents[first], second, contents[second])
+
+	response, err := a.chatgpt.SendMessage(prompt)
+	if err != nil {
+		return "", "", err
 	}
-	
-	// Check for file tree requests
-	if strings.Contains(lowerQuery, "file tree") || strings.Contains(lowerQuery, "project structure") {
-		return a.handleFileTreeRequest(query)
+
+	merged, ok := longestCodeBlock(ExtractCodeBlocks(response))
+	if !ok {
+		return response, "", nil
 	}
-	
-	// Check for file search requests
-	if strings.Contains(lowerQuery, "find file") || strings.Contains(lowerQuery, "search") {
-		return a.handleFileSearchRequest(query)
+	return response, merged.Code, nil
+}
+
+// GenerateTests asks ChatGPT for tests covering a source file. It returns the
+// full response, the generated test code and the conventional path for it.
+func (a *Agent) GenerateTests(filename string) (string, string, string, error) {
+	content, err := a.ReadFile(filename)
+	if err != nil {
+		return "", "", "", err
 	}
-	
-	// Default: process as normal message
-	return a.ProcessMessage(query)
+
+	projectType := ""
+	if a.context != nil {
+		projectType = a.context.GetProjectType()
+	}
+	framework, testPath := testConventions(filename, projectType)
+
+	prompt := fmt.Sprintf(`Write idiomatic %s for the following file: %s
+
+`+"```"+`
+%s
+`+"```"+`
+
+Cover the main behaviour and the important edge cases. Reply with the complete test file in a single code block; it will be saved as %s.`, framework, filename, content, testPath)
+
+	response, err := a.chatgpt.SendMessage(prompt)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	tests, ok := longestCodeBlock(ExtractCodeBlocks(response))
+	if !ok {
+		return response, "", testPath, nil
+	}
+	return response, tests.Code, testPath, nil
 }
 
-// handleFileReadRequest handles requests to read specific files
-func (a *Agent) handleFileReadRequest(query string) (string, error) {
-	// Extract filename from query (simple implementation)
-	words := strings.Fields(query)
-	var filename string
-	
-	for i, word := range words {
-		// Look for file extensions or common filenames
-		if strings.Contains(word, ".") || word == "main.go" || word == "README.md" {
-			filename = word
-			break
-		}
-		// Look for patterns like "read file main.go"
-		if (word == "file" || word == "File") && i+1 < len(words) {
-			filename = words[i+1]
-			break
+// testConventions picks the test framework and test file path for a source
+// file, using its extension first and the detected project type as fallback
+func testConventions(filename, projectType string) (string, string) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	dir, name := filepath.Split(base)
+
+	if ext == "" {
+		switch {
+		case strings.HasPrefix(projectType, "Go"):
+			ext = ".go"
+		case strings.HasPrefix(projectType, "Python"):
+			ext = ".py"
+		case strings.Contains(projectType, "JavaScript"):
+			ext = ".js"
 		}
 	}
-	
-	if filename == "" {
-		return "Please specify which file you'd like me to read. For example: 'read file main.go'", nil
+
+	switch ext {
+	case ".go":
+		return "table-driven Go tests using the testing package", base + "_test.go"
+	case ".py":
+		return "pytest tests", filepath.Join(dir, "test_"+name+".py")
+	case ".js", ".ts":
+		return "Jest tests", base + ".test" + ext
+	case ".rs":
+		return "Rust unit tests in a #[cfg(test)] module", base + "_test.rs"
+	case ".java":
+		return "JUnit 5 tests", base + "Test.java"
+	default:
+		return "unit tests", base + "_test" + ext
+	}
+}
+
+// ProcessFileQuery processes queries related to file operations. The query
+// is parsed by ParseFileQuery; anything that isn't a file query is processed
+// as a normal message.
+func (a *Agent) ProcessFileQuery(query string) (string, error) {
+	intent, ok := ParseFileQuery(query)
+	if !ok {
+		return a.ProcessMessage(query)
 	}
 	
+	switch intent.Action {
+	case ReadAction:
+		return a.handleFileReadRequest(intent.Target)
+	case ListAction:
+		return a.handleFileListRequest(intent.Target)
+	case TreeAction:
+		depth, err := strconv.Atoi(intent.Flags["depth"])
+		if err != nil || depth <= 0 {
+			depth = 3
+		}
+		return a.handleFileTreeRequest(depth)
+	case SearchAction:
+		return a.handleFileSearchRequest(intent.Target, intent.Flags["dir"])
+	}
+	return a.ProcessMessage(query)
+}
+
+// handleFileReadRequest handles requests to read specific files
+func (a *Agent) handleFileReadRequest(filename string) (string, error) {
 	content, err := a.ReadFile(filename)
 	if err != nil {
 		return fmt.Sprintf("Sorry, I couldn't read the file '%s': %v", filename, err), nil
 	}
 	
 	// Send file content to ChatGPT with context
-	contextualQuery := fmt.Sprintf("Here's the content of %s:\n\n```\n%s\n```\n\nPlease analyze this file and provide insights about the code structure, functionality, and any suggestions for improvement.", filename, content)
+	contextualQuery := fileSection(filename, content) + "\n\nPlease analyze this file and provide insights about the code structure, functionality, and any suggestions for improvement."
 	
-	return a.chatgpt.SendMessage(contextualQuery)
+	return a.send(contextualQuery)
 }
 
-// handleFileListRequest handles requests to list files
-func (a *Agent) handleFileListRequest(query string) (string, error) {
-	files, err := a.ListFiles("")
+// handleFileListRequest handles requests to list files, in dir when it is set
+func (a *Agent) handleFileListRequest(dir string) (string, error) {
+	files, err := a.ListFiles(dir)
 	if err != nil {
 		return fmt.Sprintf("Sorry, I couldn't list the files: %v", err), nil
 	}
@@ -340,8 +714,8 @@ func (a *Agent) handleFileListRequest(query string) (string, error) {
 }
 
 // handleFileTreeRequest handles requests for file tree
-func (a *Agent) handleFileTreeRequest(query string) (string, error) {
-	tree, err := a.GetFileTree(3) // Max depth of 3
+func (a *Agent) handleFileTreeRequest(depth int) (string, error) {
+	tree, err := a.GetFileTree(depth)
 	if err != nil {
 		return fmt.Sprintf("Sorry, I couldn't generate the file tree: %v", err), nil
 	}
@@ -351,19 +725,9 @@ func (a *Agent) handleFileTreeRequest(query string) (string, error) {
 	return a.chatgpt.SendMessage(contextualQuery)
 }
 
-// handleFileSearchRequest handles file search requests
-func (a *Agent) handleFileSearchRequest(query string) (string, error) {
-	// Extract search pattern from query
-	words := strings.Fields(query)
-	var pattern string
-	
-	for i, word := range words {
-		if (word == "find" || word == "search") && i+1 < len(words) {
-			pattern = words[i+1]
-			break
-		}
-	}
-	
+// handleFileSearchRequest handles file search requests, keeping matches
+// under dir when it is set
+func (a *Agent) handleFileSearchRequest(pattern, dir string) (string, error) {
 	if pattern == "" {
 		return "Please specify what file you're looking for. For example: 'find file main' or 'search config'", nil
 	}
@@ -373,6 +737,17 @@ func (a *Agent) handleFileSearchRequest(query string) (string, error) {
 		return fmt.Sprintf("Sorry, I couldn't search for files: %v", err), nil
 	}
 	
+	if dir != "" {
+		prefix := filepath.ToSlash(filepath.Clean(dir)) + "/"
+		var inDir []FileInfo
+		for _, file := range files {
+			if strings.HasPrefix(filepath.ToSlash(file.Path), prefix) {
+				inDir = append(inDir, file)
+			}
+		}
+		files = inDir
+	}
+	
 	if len(files) == 0 {
 		return fmt.Sprintf("No files found matching '%s'", pattern), nil
 	}
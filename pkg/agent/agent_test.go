@@ -0,0 +1,39 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/chatgpt-element-recorder/pkg/chatgpt"
+	"github.com/chatgpt-element-recorder/pkg/config"
+)
+
+func TestShouldRotateChat(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		threshold int
+		message   string
+		want      bool
+	}{
+		{"off", false, 10, "a long enough message to pass the threshold", false},
+		{"below the threshold", true, 100, "short", false},
+		{"message reaches the threshold", true, 10, "a long enough message to pass the threshold", true},
+		{"zero threshold is off", true, 0, "hi", false},
+		{"negative threshold is off", true, -1, "hi", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Agent{
+				chatgpt: &chatgpt.ChatGPT{},
+				config: &config.DynamicConfig{Agent: config.AgentConfig{
+					AutoRotateChat:  tt.enabled,
+					RotateThreshold: tt.threshold,
+				}},
+			}
+			if got := a.ShouldRotateChat(tt.message); got != tt.want {
+				t.Errorf("ShouldRotateChat(%q) with threshold %d = %v, want %v", tt.message, tt.threshold, got, tt.want)
+			}
+		})
+	}
+}
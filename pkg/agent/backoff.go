@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chatgpt-element-recorder/pkg/chatgpt"
+	"github.com/chatgpt-element-recorder/pkg/config"
+	"github.com/chatgpt-element-recorder/pkg/ui"
+)
+
+// RetryOnRateLimit runs step and, while it fails with a rate-limit toast,
+// waits out the cooldown and runs the same step again, up to MaxRetries
+// times. The cooldown is taken from the toast when it names one, otherwise
+// from the config. Cooldowns longer than MaxWait are not waited for.
+func RetryOnRateLimit(cfg config.RateLimitConfig, step func() (string, error)) (string, error) {
+	response, err := step()
+	for attempt := 1; attempt <= cfg.MaxRetries; attempt++ {
+		var toastErr *chatgpt.ToastError
+		if !errors.As(err, &toastErr) || !toastErr.IsRateLimit() {
+			return response, err
+		}
+
+		wait := toastErr.Cooldown()
+		if wait == 0 {
+			wait = time.Duration(cfg.Cooldown) * time.Second
+		}
+		if cfg.MaxWait > 0 && wait > time.Duration(cfg.MaxWait)*time.Second {
+			ui.PrintWarning(fmt.Sprintf("%s - cooldown of %s is longer than rate_limit.max_wait, not retrying", toastErr.Message, wait))
+			return response, err
+		}
+
+		ui.PrintWarning(fmt.Sprintf("%s - retry %d/%d in %s", toastErr.Message, attempt, cfg.MaxRetries, wait))
+		waitWithCountdown(wait)
+		response, err = step()
+	}
+	return response, err
+}
+
+// waitWithCountdown sleeps for d while a spinner shows the time left, so a
+// long cooldown doesn't look like a hang
+func waitWithCountdown(d time.Duration) {
+	spinner := ui.NewSquareSpinner()
+	spinner.Start(fmt.Sprintf("Rate limited - resuming in %s...", d))
+	defer spinner.Stop()
+
+	deadline := time.Now().Add(d)
+	for left := time.Until(deadline); left > 0; left = time.Until(deadline) {
+		spinner.Update(fmt.Sprintf("Rate limited - resuming in %s...", left.Round(time.Second)))
+		if left > time.Second {
+			left = time.Second
+		}
+		time.Sleep(left)
+	}
+}
@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// LRUCache keeps the most recently used responses up to a fixed capacity,
+// dropping the least recently used one when a new response doesn't fit
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List               // front is the most recently used
+	entries  map[string]*list.Element // values are *cacheEntry
+}
+
+type cacheEntry struct {
+	key   string
+	value string
+}
+
+// NewLRUCache creates a cache holding up to capacity responses. A capacity of
+// zero or less holds nothing.
+func NewLRUCache(capacity int) *LRUCache {
+	return &LRUCache{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns the response stored under key and marks it as recently used
+func (c *LRUCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheEntry).value, true
+}
+
+// Put stores a response under key, evicting the least recently used ones if
+// the cache is full
+func (c *LRUCache) Put(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*cacheEntry).value = value
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&cacheEntry{key: key, value: value})
+	c.evict()
+}
+
+// SetCapacity changes how many responses the cache holds, evicting the least
+// recently used ones that no longer fit
+func (c *LRUCache) SetCapacity(capacity int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.capacity = capacity
+	c.evict()
+}
+
+// Clear drops every cached response
+func (c *LRUCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+}
+
+// Len returns the number of cached responses
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// evict drops least recently used entries until the cache is within capacity
+func (c *LRUCache) evict() {
+	for c.order.Len() > max(c.capacity, 0) {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// responseCacheKey identifies a prompt by everything that shapes its answer:
+// the mode and the prompt as sent, system prompt and attachments included
+func responseCacheKey(mode AgentMode, prompt string) string {
+	hash := sha256.New()
+	for _, part := range []string{string(mode), prompt} {
+		hash.Write([]byte(part))
+		// A separator keeps ("ab", "c") and ("a", "bc") apart
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
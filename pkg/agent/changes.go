@@ -0,0 +1,124 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// maxDiffCells bounds the line-diff table; larger files count as fully rewritten
+const maxDiffCells = 4_000_000
+
+// FileChange summarizes the writes made to one file since tracking started
+type FileChange struct {
+	Path    string
+	Added   int
+	Removed int
+	Created bool
+}
+
+// snapshot records a file's content before its first write, so later writes
+// can be summarized against it
+func (fo *FileOperations) snapshot(filename, fullPath string) {
+	key := filepath.Clean(filename)
+	if fo.originals == nil {
+		fo.originals = make(map[string]*string)
+	}
+	if _, ok := fo.originals[key]; ok {
+		return
+	}
+	if data, err := os.ReadFile(fullPath); err == nil {
+		content := string(data)
+		fo.originals[key] = &content
+		return
+	}
+	fo.originals[key] = nil
+}
+
+// Changes compares every file written since the last ResetChanges with its
+// content before the first write, ordered by path. Files whose content ended
+// up unchanged are left out.
+func (a *Agent) Changes() []FileChange {
+	var changes []FileChange
+	for path, original := range a.fileOps.originals {
+		current := ""
+		if fullPath, err := a.fileOps.resolvePath(path); err == nil {
+			if data, err := os.ReadFile(fullPath); err == nil {
+				current = string(data)
+			}
+		}
+
+		before := ""
+		if original != nil {
+			before = *original
+		}
+		added, removed := lineDiffCounts(splitLines(before), splitLines(current))
+		if added == 0 && removed == 0 {
+			continue
+		}
+		changes = append(changes, FileChange{Path: path, Added: added, Removed: removed, Created: original == nil})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// ResetChanges forgets the tracked writes, starting a new run
+func (a *Agent) ResetChanges() {
+	a.fileOps.originals = nil
+}
+
+// Diffstat renders changes like `git diff --stat`: one "path | +a -r" line per
+// file and a totals line
+func Diffstat(changes []FileChange) string {
+	width := 0
+	for _, c := range changes {
+		if len(c.Path) > width {
+			width = len(c.Path)
+		}
+	}
+
+	var stat strings.Builder
+	added, removed, created := 0, 0, 0
+	for _, c := range changes {
+		note := ""
+		if c.Created {
+			note = " (new)"
+			created++
+		}
+		stat.WriteString(fmt.Sprintf(" %-*s | +%d -%d%s\n", width, c.Path, c.Added, c.Removed, note))
+		added += c.Added
+		removed += c.Removed
+	}
+
+	summary := fmt.Sprintf(" %d files changed, %d insertions(+), %d deletions(-)", len(changes), added, removed)
+	if created > 0 {
+		summary += fmt.Sprintf(", %d new", created)
+	}
+	stat.WriteString(summary)
+	return stat.String()
+}
+
+// splitLines splits content into lines, ignoring a final newline
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// lineDiffCounts returns how many lines were added and removed going from
+// before to after, counted from the diffLines line diff
+func lineDiffCounts(before, after []string) (added, removed int) {
+	for _, line := range diffLines(before, after) {
+		switch line.kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+	return added, removed
+}
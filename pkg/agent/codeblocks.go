@@ -0,0 +1,184 @@
+package agent
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/chatgpt-element-recorder/pkg/ui"
+)
+
+// CodeBlock represents a code snippet extracted from a ChatGPT response
+type CodeBlock struct {
+	Language string
+	Code     string
+}
+
+// ExtractCodeBlocks returns the code blocks found in a response. It understands
+// markdown fences as well as the "go / Copy / Edit" layout that ChatGPT's
+// rendered text produces once the fences are gone.
+func ExtractCodeBlocks(response string) []CodeBlock {
+	if strings.Contains(response, "```") {
+		return extractFencedBlocks(response)
+	}
+
+	var blocks []CodeBlock
+	var current *CodeBlock
+	var lines []string
+
+	flush := func() {
+		if current != nil {
+			current.Code = strings.TrimRight(strings.Join(lines, "\n"), "\n ")
+			if strings.TrimSpace(current.Code) != "" {
+				blocks = append(blocks, *current)
+			}
+		}
+		current = nil
+		lines = nil
+	}
+
+	for _, line := range ui.ProcessResponseWithCodeHighlight(response) {
+		if !line.IsCode {
+			flush()
+			continue
+		}
+		if current == nil || current.Language != line.Language {
+			flush()
+			current = &CodeBlock{Language: line.Language}
+		}
+		lines = append(lines, line.Text)
+	}
+	flush()
+
+	return blocks
+}
+
+// extractFencedBlocks extracts ``` fenced code blocks from markdown text
+func extractFencedBlocks(response string) []CodeBlock {
+	var blocks []CodeBlock
+	var current *CodeBlock
+	var lines []string
+
+	for _, line := range strings.Split(response, "\n") {
+		trim := strings.TrimSpace(line)
+		if !strings.HasPrefix(trim, "```") {
+			if current != nil {
+				lines = append(lines, line)
+			}
+			continue
+		}
+
+		if current == nil {
+			current = &CodeBlock{Language: strings.TrimSpace(strings.TrimPrefix(trim, "```"))}
+			lines = nil
+			continue
+		}
+
+		current.Code = strings.Join(lines, "\n")
+		blocks = append(blocks, *current)
+		current = nil
+	}
+
+	return blocks
+}
+
+// longestCodeBlock returns the largest code block, which is usually the full
+// file when ChatGPT mixes it with small illustrative snippets
+func longestCodeBlock(blocks []CodeBlock) (CodeBlock, bool) {
+	if len(blocks) == 0 {
+		return CodeBlock{}, false
+	}
+
+	longest := blocks[0]
+	for _, block := range blocks[1:] {
+		if len(block.Code) > len(longest.Code) {
+			longest = block
+		}
+	}
+	return longest, true
+}
+
+// NamedBlock is a code block paired with the file name it should be saved as
+type NamedBlock struct {
+	Path string
+	Code string
+}
+
+var (
+	// fenceFileHint matches filename hints in a fence info string, such as
+	// "go // file: main.go" or "go title=main.go"
+	fenceFileHint = regexp.MustCompile(`(?:file(?:name)?\s*[:=]|title=)\s*["']?([\w./-]+\.\w+)`)
+	// commentFileHint matches a leading comment that only names a file, such as
+	// "// file: main.go", "# app.py" or "<!-- index.html -->"
+	commentFileHint = regexp.MustCompile(`^\s*(?://|#|--|/\*|<!--)\s*(?:file(?:name)?\s*:\s*)?([\w./-]+\.\w+)\s*(?:\*/|-->)?\s*$`)
+)
+
+// languageExts maps fence languages to file extensions for unnamed blocks
+var languageExts = map[string]string{
+	"go": ".go", "python": ".py", "py": ".py", "javascript": ".js", "js": ".js",
+	"typescript": ".ts", "ts": ".ts", "tsx": ".tsx", "jsx": ".jsx",
+	"bash": ".sh", "sh": ".sh", "shell": ".sh", "zsh": ".sh",
+	"json": ".json", "yaml": ".yaml", "yml": ".yaml", "toml": ".toml",
+	"html": ".html", "css": ".css", "sql": ".sql", "rust": ".rs", "rs": ".rs",
+	"java": ".java", "c": ".c", "cpp": ".cpp", "c++": ".cpp", "markdown": ".md", "md": ".md",
+	"xml": ".xml", "dockerfile": ".dockerfile", "makefile": ".mk",
+}
+
+// NameCodeBlocks names each code block after the filename hinted in its fence or
+// leading comment, falling back to blockN plus an extension for its language.
+// A comment that only names the file is dropped from the saved code.
+func NameCodeBlocks(blocks []CodeBlock) []NamedBlock {
+	var files []NamedBlock
+	used := make(map[string]int)
+
+	for i, block := range blocks {
+		code := block.Code
+		path := ""
+
+		if m := fenceFileHint.FindStringSubmatch(block.Language); m != nil {
+			path = m[1]
+		} else if info := strings.Fields(block.Language); len(info) > 0 && strings.Contains(info[0], ".") {
+			path = info[0] // fence info is the file name itself, e.g. ```main.go
+		}
+
+		if path == "" {
+			lines := strings.SplitN(strings.TrimLeft(code, "\n"), "\n", 2)
+			if m := commentFileHint.FindStringSubmatch(lines[0]); m != nil {
+				path = m[1]
+				if len(lines) > 1 {
+					code = lines[1]
+				} else {
+					code = ""
+				}
+			}
+		}
+
+		if path == "" {
+			path = fmt.Sprintf("block%d%s", i+1, blockExt(block.Language))
+		}
+		path = filepath.Clean(path)
+
+		// Two blocks for the same file get distinct names rather than overwriting
+		if n := used[path]; n > 0 {
+			ext := filepath.Ext(path)
+			path = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(path, ext), n+1, ext)
+		}
+		used[path]++
+
+		files = append(files, NamedBlock{Path: path, Code: code})
+	}
+	return files
+}
+
+// blockExt returns the file extension for a fence language
+func blockExt(language string) string {
+	info := strings.Fields(strings.ToLower(language))
+	if len(info) == 0 {
+		return ".txt"
+	}
+	if ext, ok := languageExts[info[0]]; ok {
+		return ext
+	}
+	return ".txt"
+}
@@ -2,10 +2,14 @@ package agent
 
 import (
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/chatgpt-element-recorder/pkg/config"
 )
 
 // ProjectContext handles project analysis and context management
@@ -17,6 +21,9 @@ type ProjectContext struct {
 	directories   []string
 	lastAnalyzed  time.Time
 	analysis      ProjectAnalysis
+	maxListed     int // files listed per category in GetProjectInfo, 0 for all
+	depth         int // directory levels analyzed, 1 for the top level only
+	goMod         *GoModInfo // parsed go.mod of a Go project, nil otherwise
 }
 
 // FileInfo represents information about a file
@@ -66,9 +73,21 @@ func NewProjectContext() *ProjectContext {
 	currentDir, _ := os.Getwd()
 	projectName := filepath.Base(currentDir)
 	
+	maxListed := 15 // default
+	depth := 3
+	if cfg, err := config.LoadDynamicConfig(); err == nil {
+		maxListed = cfg.Agent.MaxListedFiles
+		depth = cfg.Agent.AnalysisDepth
+	}
+	if depth < 1 {
+		depth = 1
+	}
+	
 	ctx := &ProjectContext{
 		currentDir:  currentDir,
 		projectName: projectName,
+		maxListed:   maxListed,
+		depth:       depth,
 	}
 	
 	ctx.Refresh()
@@ -86,52 +105,84 @@ func (pc *ProjectContext) Refresh() error {
 	
 	// Detect project type and technologies
 	pc.detectProjectType()
+	pc.readGoMod()
 	pc.detectTechnologies()
 	pc.generateInsights()
 	
 	return nil
 }
 
-// analyzeStructure analyzes the project's file structure
+// analyzeStructure analyzes the project's file structure down to pc.depth
+// levels. Ignored directories (node_modules, vendor, ...) are recorded but not
+// entered. Files are ordered shallowest first so top-level manifests win
+// during project type detection.
 func (pc *ProjectContext) analyzeStructure() error {
-	entries, err := os.ReadDir(pc.currentDir)
-	if err != nil {
-		return err
-	}
-	
 	pc.files = []FileInfo{}
 	pc.directories = []string{}
 	
-	for _, entry := range entries {
-		name := entry.Name()
+	err := filepath.WalkDir(pc.currentDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == pc.currentDir {
+				return err
+			}
+			return nil // Skip entries we can't read
+		}
+		if path == pc.currentDir {
+			return nil
+		}
+		
+		name := d.Name()
+		relPath, err := filepath.Rel(pc.currentDir, path)
+		if err != nil {
+			relPath = name
+		}
+		level := pathDepth(relPath) + 1
 		
 		// Skip hidden files (except important ones)
 		if strings.HasPrefix(name, ".") && !pc.isImportantHiddenFile(name) {
-			continue
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
 		}
 		
-		if entry.IsDir() {
-			pc.directories = append(pc.directories, name)
-		} else {
-			info, err := entry.Info()
-			if err != nil {
-				continue
+		if d.IsDir() {
+			pc.directories = append(pc.directories, relPath)
+			if level >= pc.depth || shouldSkip(name) {
+				return filepath.SkipDir
 			}
-			
-			fileInfo := FileInfo{
-				Name:      name,
-				Extension: strings.ToLower(filepath.Ext(name)),
-				Category:  pc.categorizeFile(name),
-				Size:      info.Size(),
-				ModTime:   info.ModTime(),
-			}
-			pc.files = append(pc.files, fileInfo)
+			return nil
+		}
+		
+		info, err := d.Info()
+		if err != nil {
+			return nil
 		}
+		pc.files = append(pc.files, FileInfo{
+			Name:      name,
+			Path:      relPath,
+			Extension: strings.ToLower(filepath.Ext(name)),
+			Category:  pc.categorizeFile(name),
+			Size:      info.Size(),
+			ModTime:   info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 	
+	sort.SliceStable(pc.files, func(i, j int) bool {
+		return pathDepth(pc.files[i].Path) < pathDepth(pc.files[j].Path)
+	})
 	return nil
 }
 
+// pathDepth counts the directories above a relative path
+func pathDepth(relPath string) int {
+	return strings.Count(relPath, string(filepath.Separator))
+}
+
 // isImportantHiddenFile checks if a hidden file is important for analysis
 func (pc *ProjectContext) isImportantHiddenFile(name string) bool {
 	importantFiles := []string{
@@ -268,6 +319,28 @@ func (pc *ProjectContext) detectProjectType() {
 	}
 }
 
+// readGoMod parses the go.mod of a Go project, filling in its direct
+// dependencies. A missing or broken go.mod leaves them empty.
+func (pc *ProjectContext) readGoMod() {
+	pc.goMod = nil
+	pc.analysis.Dependencies = nil
+	if pc.projectType != "Go" {
+		return
+	}
+
+	info, err := parseGoMod(filepath.Join(pc.currentDir, "go.mod"))
+	if err != nil {
+		return
+	}
+	pc.goMod = info
+	pc.analysis.Dependencies = info.Require
+}
+
+// GoMod returns the parsed go.mod of a Go project, or nil
+func (pc *ProjectContext) GoMod() *GoModInfo {
+	return pc.goMod
+}
+
 // detectTechnologies identifies technologies used in the project
 func (pc *ProjectContext) detectTechnologies() {
 	pc.analysis.Technologies = []string{}
@@ -344,7 +417,7 @@ func (pc *ProjectContext) generateInsights() {
 		}
 		
 		if file.Name == "main.go" || file.Name == "main.py" || file.Name == "index.js" {
-			pc.analysis.Structure.MainFiles = append(pc.analysis.Structure.MainFiles, file.Name)
+			pc.analysis.Structure.MainFiles = append(pc.analysis.Structure.MainFiles, file.Path)
 		}
 	}
 	
@@ -390,11 +463,27 @@ func (pc *ProjectContext) GetProjectInfo() string {
 	if len(pc.analysis.Technologies) > 0 {
 		info.WriteString(fmt.Sprintf("Technologies: %s\n", strings.Join(pc.analysis.Technologies, ", ")))
 	}
+
+	// The Go version tells which standard library APIs are available
+	if pc.goMod != nil {
+		module := fmt.Sprintf("Go module: %s", pc.goMod.Module)
+		if pc.goMod.GoVersion != "" {
+			module += fmt.Sprintf(" (go %s", pc.goMod.GoVersion)
+			if pc.goMod.Toolchain != "" {
+				module += ", toolchain " + pc.goMod.Toolchain
+			}
+			module += ")"
+		}
+		info.WriteString(module + "\n")
+	}
+	if len(pc.analysis.Dependencies) > 0 {
+		info.WriteString(fmt.Sprintf("Dependencies: %s\n", SummarizeNames(pc.analysis.Dependencies, pc.maxListed)))
+	}
 	
-	// File summary
+	// File summary, most important files first so large projects stay readable
 	configFiles := []string{}
 	codeFiles := []string{}
-	for _, file := range pc.files {
+	for _, file := range rankFiles(pc.files) {
 		if file.Category == ConfigFile {
 			configFiles = append(configFiles, file.Name)
 		} else if file.Category == CodeFile {
@@ -403,13 +492,13 @@ func (pc *ProjectContext) GetProjectInfo() string {
 	}
 	
 	if len(configFiles) > 0 {
-		info.WriteString(fmt.Sprintf("Config files: %s\n", strings.Join(configFiles, ", ")))
+		info.WriteString(fmt.Sprintf("Config files: %s\n", SummarizeNames(configFiles, pc.maxListed)))
 	}
 	if len(codeFiles) > 0 {
-		info.WriteString(fmt.Sprintf("Code files: %s\n", strings.Join(codeFiles, ", ")))
+		info.WriteString(fmt.Sprintf("Code files: %s\n", SummarizeNames(codeFiles, pc.maxListed)))
 	}
 	if len(pc.directories) > 0 {
-		info.WriteString(fmt.Sprintf("Directories: %s\n", strings.Join(pc.directories, ", ")))
+		info.WriteString(fmt.Sprintf("Directories: %s\n", SummarizeNames(pc.directories, pc.maxListed)))
 	}
 	
 	if len(pc.analysis.Insights) > 0 {
@@ -419,6 +508,36 @@ func (pc *ProjectContext) GetProjectInfo() string {
 	return info.String()
 }
 
+// importantFiles are listed before anything else in project summaries
+var importantFiles = map[string]bool{
+	"go.mod": true, "package.json": true, "requirements.txt": true, "pyproject.toml": true,
+	"Cargo.toml": true, "pom.xml": true, "build.gradle": true, "Dockerfile": true,
+	"Makefile": true, "main.go": true, "main.py": true, "index.js": true,
+}
+
+// rankFiles returns files ordered by importance: manifests and entry points
+// first, then larger files before smaller ones
+func rankFiles(files []FileInfo) []FileInfo {
+	ranked := make([]FileInfo, len(files))
+	copy(ranked, files)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if importantFiles[ranked[i].Name] != importantFiles[ranked[j].Name] {
+			return importantFiles[ranked[i].Name]
+		}
+		return ranked[i].Size > ranked[j].Size
+	})
+	return ranked
+}
+
+// SummarizeNames joins up to max names, noting how many were left out.
+// A max of 0 or less lists every name.
+func SummarizeNames(names []string, max int) string {
+	if max <= 0 || len(names) <= max {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s (+%d more)", strings.Join(names[:max], ", "), len(names)-max)
+}
+
 // GetCurrentDir returns the current working directory
 func (pc *ProjectContext) GetCurrentDir() string {
 	return pc.currentDir
@@ -0,0 +1,267 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// hunkHeader matches a unified diff hunk header such as "@@ -12,7 +12,9 @@"
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// diffHunk is one hunk of a unified diff
+type diffHunk struct {
+	oldStart int      // 1-based first line in the original
+	lines    []string // body lines, each prefixed with ' ', '-' or '+'
+}
+
+// ApplyDiff applies a unified diff to a file inside the working directory and
+// writes the result with WriteFile. Every context and removed line must match
+// the file, otherwise nothing is written. Diffs are only applied when
+// agent.allow_write is on.
+func (fo *FileOperations) ApplyDiff(filename, unifiedDiff string) error {
+	if !fo.allowWrite {
+		return ErrWriteNotAllowed
+	}
+
+	hunks, err := parseUnifiedDiff(unifiedDiff)
+	if err != nil {
+		return err
+	}
+
+	original, err := fo.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	patched, err := applyHunks(original, hunks)
+	if err != nil {
+		return fmt.Errorf("failed to apply diff to %s: %v", filename, err)
+	}
+	return fo.WriteFile(filename, patched)
+}
+
+// parseUnifiedDiff reads the hunks of a single-file unified diff. File
+// headers (---, +++, diff, index) are skipped.
+func parseUnifiedDiff(diff string) ([]diffHunk, error) {
+	var hunks []diffHunk
+	var current *diffHunk
+	for _, line := range strings.Split(strings.TrimRight(strings.ReplaceAll(diff, "\r\n", "\n"), "\n"), "\n") {
+		if match := hunkHeader.FindStringSubmatch(line); match != nil {
+			start, _ := strconv.Atoi(match[1])
+			if match[2] == "0" {
+				// An empty range names the line before the insertion
+				start++
+			}
+			hunks = append(hunks, diffHunk{oldStart: start})
+			current = &hunks[len(hunks)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		switch {
+		case line == "":
+			// Editors and chat output often drop the space of a blank context line
+			current.lines = append(current.lines, " ")
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			if strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ") {
+				current = nil
+				continue
+			}
+			current.lines = append(current.lines, line)
+		case line[0] == '\\':
+			// "\ No newline at end of file"
+		default:
+			current = nil
+		}
+	}
+
+	if len(hunks) == 0 {
+		return nil, fmt.Errorf("no hunks found in diff")
+	}
+	return hunks, nil
+}
+
+// applyHunks applies hunks in order to content, checking each context and
+// removed line against the original
+func applyHunks(content string, hunks []diffHunk) (string, error) {
+	trailingNewline := strings.HasSuffix(content, "\n")
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if content == "" {
+		lines = nil
+	}
+
+	var result []string
+	next := 0 // index of the first original line not yet copied
+	for i, hunk := range hunks {
+		start := hunk.oldStart - 1
+		if start < next || start > len(lines) {
+			return "", fmt.Errorf("hunk %d starts at line %d, outside the file", i+1, hunk.oldStart)
+		}
+		result = append(result, lines[next:start]...)
+		next = start
+
+		for _, line := range hunk.lines {
+			text := line[1:]
+			switch line[0] {
+			case ' ', '-':
+				if next >= len(lines) || strings.TrimRight(lines[next], "\r") != strings.TrimRight(text, "\r") {
+					return "", fmt.Errorf("hunk %d does not match line %d", i+1, next+1)
+				}
+				if line[0] == ' ' {
+					result = append(result, lines[next])
+				}
+				next++
+			case '+':
+				result = append(result, text)
+			}
+		}
+	}
+	result = append(result, lines[next:]...)
+
+	patched := strings.Join(result, "\n")
+	if len(result) > 0 && (trailingNewline || content == "") {
+		patched += "\n"
+	}
+	return patched, nil
+}
+
+// diffLine is one line of a line diff: kept (' '), removed ('-') or added ('+')
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// UnifiedDiff returns a unified diff from before to after with context lines
+// around each change, labelled with name, or "" when they are the same
+func UnifiedDiff(name, before, after string, context int) string {
+	lines := diffLines(splitLines(before), splitLines(after))
+
+	// Line numbers in the old and new file before each diff line
+	oldNo := make([]int, len(lines)+1)
+	newNo := make([]int, len(lines)+1)
+	for i, line := range lines {
+		oldNo[i+1], newNo[i+1] = oldNo[i], newNo[i]
+		if line.kind != '+' {
+			oldNo[i+1]++
+		}
+		if line.kind != '-' {
+			newNo[i+1]++
+		}
+	}
+
+	var out strings.Builder
+	for k := 0; k < len(lines); {
+		if lines[k].kind == ' ' {
+			k++
+			continue
+		}
+
+		// Extend the hunk over changes closer than two contexts apart
+		start := k - context
+		if start < 0 {
+			start = 0
+		}
+		end := k
+		for end < len(lines) {
+			if lines[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(lines) && lines[run].kind == ' ' {
+				run++
+			}
+			if run == len(lines) || run-end > 2*context {
+				end += context
+				if end > run {
+					end = run
+				}
+				break
+			}
+			end = run
+		}
+
+		if out.Len() == 0 {
+			out.WriteString(fmt.Sprintf("--- a/%s\n+++ b/%s\n", name, name))
+		}
+		oldCount, newCount := oldNo[end]-oldNo[start], newNo[end]-newNo[start]
+		out.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(oldNo[start], oldCount), hunkRange(newNo[start], newCount)))
+		for _, line := range lines[start:end] {
+			out.WriteByte(line.kind)
+			out.WriteString(line.text)
+			out.WriteByte('\n')
+		}
+		k = end
+	}
+	return out.String()
+}
+
+// hunkRange formats a hunk header range; an empty range names the line before it
+func hunkRange(before, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", before)
+	}
+	return fmt.Sprintf("%d,%d", before+1, count)
+}
+
+// diffLines returns the line diff from before to after along their longest
+// common subsequence. Inputs too large for the table come out as a full rewrite.
+func diffLines(before, after []string) []diffLine {
+	var head, tail []diffLine
+	for len(before) > 0 && len(after) > 0 && before[0] == after[0] {
+		head = append(head, diffLine{' ', before[0]})
+		before, after = before[1:], after[1:]
+	}
+	for len(before) > 0 && len(after) > 0 && before[len(before)-1] == after[len(after)-1] {
+		tail = append([]diffLine{{' ', before[len(before)-1]}}, tail...)
+		before, after = before[:len(before)-1], after[:len(after)-1]
+	}
+
+	lines := head
+	if len(before)*len(after) > maxDiffCells {
+		for _, line := range before {
+			lines = append(lines, diffLine{'-', line})
+		}
+		for _, line := range after {
+			lines = append(lines, diffLine{'+', line})
+		}
+		return append(lines, tail...)
+	}
+
+	// lcs[i][j] is the common subsequence length of before[i:] and after[j:]
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			lines = append(lines, diffLine{' ', before[i]})
+			i++
+			j++
+		case j == len(after) || i < len(before) && lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', before[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', after[j]})
+			j++
+		}
+	}
+	return append(lines, tail...)
+}
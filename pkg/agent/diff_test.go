@@ -0,0 +1,95 @@
+package agent
+
+import "testing"
+
+func TestApplyUnifiedDiff(t *testing.T) {
+	original := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n"
+
+	tests := []struct {
+		name string
+		diff string
+		want string
+		ok   bool
+	}{
+		{
+			name: "single hunk",
+			diff: "--- a/f\n+++ b/f\n@@ -2,3 +2,3 @@\n two\n-three\n+THREE\n four\n",
+			want: "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\neight\nnine\nten\n",
+			ok:   true,
+		},
+		{
+			name: "multi-hunk",
+			diff: "@@ -1,2 +1,2 @@\n-one\n+ONE\n two\n@@ -9,2 +9,3 @@\n nine\n ten\n+eleven\n",
+			want: "ONE\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\neleven\n",
+			ok:   true,
+		},
+		{
+			// The second hunk's new-file start is shifted by the first hunk's
+			// added lines; only the old-file start places it
+			name: "offset from an earlier hunk",
+			diff: "@@ -1,1 +1,3 @@\n one\n+one.a\n+one.b\n@@ -5,2 +7,1 @@\n five\n-six\n",
+			want: "one\none.a\none.b\ntwo\nthree\nfour\nfive\nseven\neight\nnine\nten\n",
+			ok:   true,
+		},
+		{
+			name: "insertion after a line",
+			diff: "@@ -3,0 +4,2 @@\n+three.a\n+three.b\n",
+			want: "one\ntwo\nthree\nthree.a\nthree.b\nfour\nfive\nsix\nseven\neight\nnine\nten\n",
+			ok:   true,
+		},
+		{
+			name: "insertion at the top",
+			diff: "@@ -0,0 +1 @@\n+zero\n",
+			want: "zero\none\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\nten\n",
+			ok:   true,
+		},
+		{
+			name: "blank context line without its space",
+			diff: "@@ -10,1 +10,3 @@\n ten\n\n+eleven\n",
+			ok:   false, // the file has no line after ten to match the blank
+		},
+		{
+			name: "context mismatch",
+			diff: "@@ -2,3 +2,3 @@\n two\n-drei\n+THREE\n four\n",
+			ok:   false,
+		},
+		{
+			name: "removed line mismatch at the end",
+			diff: "@@ -10,1 +10,0 @@\n-eleven\n",
+			ok:   false,
+		},
+		{
+			name: "hunks out of order",
+			diff: "@@ -5,1 +5,1 @@\n-five\n+FIVE\n@@ -2,1 +2,1 @@\n-two\n+TWO\n",
+			ok:   false,
+		},
+		{
+			name: "no hunks",
+			diff: "--- a/f\n+++ b/f\n",
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hunks, err := parseUnifiedDiff(tt.diff)
+			var got string
+			if err == nil {
+				got, err = applyHunks(original, hunks)
+			}
+			if (err == nil) != tt.ok {
+				t.Fatalf("applying %q: error = %v, want ok = %v", tt.diff, err, tt.ok)
+			}
+			if tt.ok && got != tt.want {
+				t.Errorf("applying %q = %q, want %q", tt.diff, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDiffNeedsAllowWrite(t *testing.T) {
+	fo := NewFileOperations()
+	if err := fo.ApplyDiff("main.go", "@@ -1,1 +1,1 @@\n-a\n+b\n"); err != ErrWriteNotAllowed {
+		t.Errorf("ApplyDiff without allow_write error = %v, want ErrWriteNotAllowed", err)
+	}
+}
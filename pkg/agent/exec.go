@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// defaultExecOutputBytes caps command output when agent.max_exec_output_bytes is unset
+const defaultExecOutputBytes = 8 * 1024
+
+// RunCommand runs a shell command in the project directory and returns its
+// combined stdout and stderr, cut to agent.max_exec_output_bytes with a notice.
+// A non-zero exit is not an error: the output is what the user wants to share,
+// so the exit status is appended to it instead. Read-only mode refuses to run
+// anything, since a command can write wherever it likes.
+func (a *Agent) RunCommand(command string) (string, error) {
+	if a.IsReadOnly() {
+		return "", ErrReadOnly
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	if a.context != nil {
+		cmd.Dir = a.context.GetCurrentDir()
+	}
+
+	output, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return "", fmt.Errorf("failed to run command: %v", err)
+	}
+
+	limit := a.config.Agent.MaxExecOutputBytes
+	if limit <= 0 {
+		limit = defaultExecOutputBytes
+	}
+	result := string(output)
+	if len(output) > limit {
+		result = string(output[:limit]) + fmt.Sprintf("\n[output truncated: showing %d of %d bytes]", limit, len(output))
+	}
+	if exitErr != nil {
+		result += fmt.Sprintf("\n[exit status %d]", exitErr.ExitCode())
+	}
+	return result, nil
+}
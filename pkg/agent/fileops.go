@@ -1,11 +1,17 @@
 package agent
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/chatgpt-element-recorder/pkg/file"
 )
 
 // FileOperations handles file access and operations for the agent
@@ -13,8 +19,36 @@ type FileOperations struct {
 	workingDir string
 	allowedExts []string
 	maxFileSize int64
+	readOnly    bool
+	allowWrite  bool                  // ApplyDiff may change files
+	originals   map[string]*string // content before the first tracked write, nil for new files
+	maxMatches  int                // content search results returned at most, 0 for the default
+}
+
+// ContentMatch is one line found by SearchContent
+type ContentMatch struct {
+	File string // path relative to the working directory
+	Line int    // 1-based line number
+	Text string // the line, trimmed and cut to maxMatchText bytes on a rune boundary
 }
 
+// defaultMaxContentMatches caps SearchContent when agent.max_content_matches is unset
+const defaultMaxContentMatches = 500
+
+// maxMatchText is the longest line kept in a ContentMatch, so minified files
+// don't flood the results
+const maxMatchText = 300
+
+// ErrFileTooLarge is returned by ReadFile for files over the size limit
+var ErrFileTooLarge = errors.New("file too large")
+
+// ErrWriteNotAllowed is returned by ApplyDiff unless agent.allow_write is on
+var ErrWriteNotAllowed = errors.New("applying diffs is off (set agent.allow_write to true to let the agent change files)")
+
+// ErrReadOnly is returned by write operations and shell commands while
+// read-only (safe) mode is on
+var ErrReadOnly = errors.New("read-only mode is on: file writes and shell commands are disabled (restart without --safe or set agent.read_only to false)")
+
 // NewFileOperations creates a new file operations handler
 func NewFileOperations() *FileOperations {
 	workingDir, _ := os.Getwd()
@@ -30,12 +64,51 @@ func NewFileOperations() *FileOperations {
 	}
 }
 
+// resolvePath joins filename to the working directory and rejects paths that escape it
+func (fo *FileOperations) resolvePath(filename string) (string, error) {
+	fullPath := filepath.Join(fo.workingDir, filename)
+	if !fo.inWorkingDir(fullPath) {
+		return "", fmt.Errorf("access denied: file outside working directory")
+	}
+	return fullPath, nil
+}
+
+// inWorkingDir reports whether path is the working directory or inside it.
+// A prefix check alone would let a sibling such as /work/proj-evil through.
+func (fo *FileOperations) inWorkingDir(path string) bool {
+	rel, err := filepath.Rel(fo.workingDir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Exists reports whether a file exists inside the working directory
+func (fo *FileOperations) Exists(filename string) bool {
+	fullPath, err := fo.resolvePath(filename)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(fullPath)
+	return err == nil
+}
+
+// isDir reports whether path is a directory inside the working directory
+func (fo *FileOperations) isDir(path string) bool {
+	fullPath, err := fo.resolvePath(path)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(fullPath)
+	return err == nil && info.IsDir()
+}
+
 // ReadFile reads a specific file and returns its content
 func (fo *FileOperations) ReadFile(filename string) (string, error) {
 	// Security check: ensure file is within working directory
-	fullPath := filepath.Join(fo.workingDir, filename)
-	if !strings.HasPrefix(fullPath, fo.workingDir) {
-		return "", fmt.Errorf("access denied: file outside working directory")
+	fullPath, err := fo.resolvePath(filename)
+	if err != nil {
+		return "", err
 	}
 
 	// Check if file exists
@@ -44,15 +117,15 @@ func (fo *FileOperations) ReadFile(filename string) (string, error) {
 		return "", fmt.Errorf("file not found: %s", filename)
 	}
 
-	// Check file size
-	if info.Size() > fo.maxFileSize {
-		return "", fmt.Errorf("file too large: %s (max %d bytes)", filename, fo.maxFileSize)
+	// Check the file type before the size, so a large disallowed file isn't
+	// reported as too large and then excerpted instead
+	if err := fo.checkFileType(filename); err != nil {
+		return "", err
 	}
 
-	// Check if file extension is allowed
-	ext := strings.ToLower(filepath.Ext(filename))
-	if !fo.isAllowedExtension(ext) && !fo.isSpecialFile(filename) {
-		return "", fmt.Errorf("file type not allowed: %s", ext)
+	// Check file size
+	if info.Size() > fo.maxFileSize {
+		return "", fmt.Errorf("%w: %s (max %d bytes)", ErrFileTooLarge, filename, fo.maxFileSize)
 	}
 
 	// Read file content
@@ -64,6 +137,52 @@ func (fo *FileOperations) ReadFile(filename string) (string, error) {
 	return string(content), nil
 }
 
+// SetReadOnly turns read-only mode on or off
+func (fo *FileOperations) SetReadOnly(readOnly bool) {
+	fo.readOnly = readOnly
+}
+
+// WriteFile writes content to a file inside the working directory. The write
+// is atomic, and an existing file is first copied to <file>.bak.
+func (fo *FileOperations) WriteFile(filename, content string) error {
+	if fo.readOnly {
+		return ErrReadOnly
+	}
+
+	// Security check: ensure file is within working directory
+	fullPath, err := fo.resolvePath(filename)
+	if err != nil {
+		return err
+	}
+
+	fo.snapshot(filename, fullPath)
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	// Keep the previous version next to the file
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(fullPath); err == nil {
+		mode = info.Mode().Perm()
+		original, err := os.ReadFile(fullPath)
+		if err != nil {
+			return fmt.Errorf("failed to read file for backup: %v", err)
+		}
+		if err := os.WriteFile(fullPath+".bak", original, mode); err != nil {
+			return fmt.Errorf("failed to write backup: %v", err)
+		}
+	}
+
+	// Write to a temporary file and rename it over the target, so an
+	// interrupted write never leaves a half-written file behind
+	if err := file.WriteFileAtomic(fullPath, []byte(content), mode); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+
+	return nil
+}
+
 // ListFiles lists all files in the current directory or specified path
 func (fo *FileOperations) ListFiles(path string) ([]FileInfo, error) {
 	var targetPath string
@@ -72,7 +191,7 @@ func (fo *FileOperations) ListFiles(path string) ([]FileInfo, error) {
 	} else {
 		targetPath = filepath.Join(fo.workingDir, path)
 		// Security check
-		if !strings.HasPrefix(targetPath, fo.workingDir) {
+		if !fo.inWorkingDir(targetPath) {
 			return nil, fmt.Errorf("access denied: path outside working directory")
 		}
 	}
@@ -154,6 +273,73 @@ func (fo *FileOperations) SearchFiles(pattern string) ([]FileInfo, error) {
 	return matches, nil
 }
 
+// SetAllowWrite lets ApplyDiff change files, or stops it
+func (fo *FileOperations) SetAllowWrite(allow bool) {
+	fo.allowWrite = allow
+}
+
+// SetMaxContentMatches sets how many matches SearchContent returns at most;
+// zero or less uses the default
+func (fo *FileOperations) SetMaxContentMatches(max int) {
+	fo.maxMatches = max
+}
+
+// SearchContent scans every readable project file line by line for pattern,
+// a regular expression or, when it doesn't compile as one, a literal string.
+// Files are those ReadFile accepts. The search stops once the match cap is
+// reached, so a full result may be cut short.
+func (fo *FileOperations) SearchContent(pattern string, caseSensitive bool) ([]ContentMatch, error) {
+	flags := ""
+	if !caseSensitive {
+		flags = "(?i)"
+	}
+	re, err := regexp.Compile(flags + pattern)
+	if err != nil {
+		re = regexp.MustCompile(flags + regexp.QuoteMeta(pattern))
+	}
+
+	limit := fo.maxMatches
+	if limit <= 0 {
+		limit = defaultMaxContentMatches
+	}
+
+	files, err := fo.ListFiles("")
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []ContentMatch
+	for _, file := range files {
+		if file.Size > fo.maxFileSize || !fo.isAllowedExtension(file.Extension) && !fo.isSpecialFile(file.Name) {
+			continue
+		}
+		f, err := os.Open(filepath.Join(fo.workingDir, file.Path))
+		if err != nil {
+			continue // Skip files we can't open
+		}
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			text := scanner.Text()
+			if !re.MatchString(text) {
+				continue
+			}
+			text = strings.TrimSpace(text)
+			if len(text) > maxMatchText {
+				text = cutAtRune(text, maxMatchText) + "..."
+			}
+			matches = append(matches, ContentMatch{File: file.Path, Line: line, Text: text})
+			if len(matches) >= limit {
+				f.Close()
+				return matches, nil
+			}
+		}
+		f.Close()
+	}
+	return matches, nil
+}
+
 // ReadMultipleFiles reads multiple files and returns their content
 func (fo *FileOperations) ReadMultipleFiles(filenames []string) (map[string]string, error) {
 	results := make(map[string]string)
@@ -235,6 +421,28 @@ func (fo *FileOperations) buildTree(dir, prefix string, depth, maxDepth int, tre
 
 // Helper functions
 
+// cutAtRune returns at most the first n bytes of s, backing up to a rune
+// boundary so a multi-byte character isn't split
+func cutAtRune(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+// checkFileType returns an error unless the file's extension is allowed or it
+// is a known special file
+func (fo *FileOperations) checkFileType(filename string) error {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if !fo.isAllowedExtension(ext) && !fo.isSpecialFile(filename) {
+		return fmt.Errorf("file type not allowed: %s", ext)
+	}
+	return nil
+}
+
 func (fo *FileOperations) isAllowedExtension(ext string) bool {
 	for _, allowed := range fo.allowedExts {
 		if ext == allowed {
@@ -274,6 +482,12 @@ func (fo *FileOperations) isImportantHiddenFile(name string) bool {
 }
 
 func (fo *FileOperations) shouldSkip(name string) bool {
+	return shouldSkip(name)
+}
+
+// shouldSkip reports whether a file or directory matches the common ignore
+// patterns (dependency, build and VCS directories, binaries)
+func shouldSkip(name string) bool {
 	skipPatterns := []string{
 		"node_modules", "vendor", "target", "build", "dist",
 		".git", ".svn", ".hg", "__pycache__", ".pytest_cache",
@@ -0,0 +1,35 @@
+package agent
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestResolvePath(t *testing.T) {
+	fo := &FileOperations{workingDir: filepath.FromSlash("/work/proj")}
+	tests := []struct {
+		filename string
+		ok       bool
+	}{
+		{"main.go", true},
+		{"pkg/agent/agent.go", true},
+		{".", true},
+		{"pkg/../main.go", true},
+		{"..file", true},
+		{"../proj/main.go", true},
+		{"../proj-evil/main.go", false},
+		{"../proj2", false},
+		{"..", false},
+		{"../../etc/passwd", false},
+		{"pkg/../../other", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			_, err := fo.resolvePath(tt.filename)
+			if (err == nil) != tt.ok {
+				t.Errorf("resolvePath(%q) error = %v, want ok = %v", tt.filename, err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GoModInfo is what the project context reads from go.mod
+type GoModInfo struct {
+	Module    string   // module path
+	GoVersion string   // minimum Go version from the go directive
+	Toolchain string   // preferred toolchain, if set
+	Require   []string // direct requirements as "path version"
+}
+
+// parseGoMod reads the module path, Go version and direct requirements from a
+// go.mod file. Requirements marked "// indirect" are skipped, as are replace,
+// exclude and retract directives.
+func parseGoMod(path string) (*GoModInfo, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.mod: %v", err)
+	}
+
+	info := &GoModInfo{}
+	block := "" // directive of the ( ... ) block being read
+	for n, raw := range strings.Split(string(data), "\n") {
+		line := raw
+		indirect := false
+		if i := strings.Index(line, "//"); i >= 0 {
+			comment := strings.TrimSpace(line[i+2:])
+			indirect = comment == "indirect" || strings.HasPrefix(comment, "indirect;")
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			if block == "require" && !indirect {
+				info.Require = append(info.Require, requirement(fields))
+			}
+			continue
+		}
+
+		directive, args := fields[0], fields[1:]
+		if len(args) == 1 && args[0] == "(" {
+			block = directive
+			continue
+		}
+		if len(args) == 0 {
+			return nil, fmt.Errorf("go.mod:%d: %s needs an argument", n+1, directive)
+		}
+		switch directive {
+		case "module":
+			info.Module = unquoteModPath(args[0])
+		case "go":
+			info.GoVersion = args[0]
+		case "toolchain":
+			info.Toolchain = args[0]
+		case "require":
+			if !indirect {
+				info.Require = append(info.Require, requirement(args))
+			}
+		}
+	}
+
+	if info.Module == "" {
+		return nil, fmt.Errorf("go.mod has no module directive")
+	}
+	return info, nil
+}
+
+// requirement formats the fields of a require line as "path version"
+func requirement(fields []string) string {
+	path := unquoteModPath(fields[0])
+	if len(fields) > 1 {
+		return path + " " + fields[1]
+	}
+	return path
+}
+
+// unquoteModPath removes the quotes go.mod allows around module paths
+func unquoteModPath(path string) string {
+	if unquoted, err := strconv.Unquote(path); err == nil {
+		return unquoted
+	}
+	return path
+}
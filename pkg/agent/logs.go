@@ -0,0 +1,166 @@
+package agent
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// maxLogLineSize is the longest single line HeadFile/TailFile will accept
+const maxLogLineSize = 1024 * 1024
+
+// HeadFile returns the first n lines of a file. The file is streamed, so it
+// may be larger than the ReadFile size limit, and .gz files are decompressed.
+func (fo *FileOperations) HeadFile(filename string, n int) (string, error) {
+	scanner, closeFile, err := fo.openLines(filename)
+	if err != nil {
+		return "", err
+	}
+	defer closeFile()
+
+	var lines []string
+	for len(lines) < n && scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// TailFile returns the last n lines of a file. Only n lines are kept in
+// memory while the file is streamed, and .gz files are decompressed.
+func (fo *FileOperations) TailFile(filename string, n int) (string, error) {
+	scanner, closeFile, err := fo.openLines(filename)
+	if err != nil {
+		return "", err
+	}
+	defer closeFile()
+
+	if n <= 0 {
+		return "", nil
+	}
+
+	// Ring buffer holding the most recent n lines
+	ring := make([]string, n)
+	count := 0
+	for scanner.Scan() {
+		ring[count%n] = scanner.Text()
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	if count < n {
+		return strings.Join(ring[:count], "\n"), nil
+	}
+	start := count % n
+	return strings.Join(append(ring[start:], ring[:start]...), "\n"), nil
+}
+
+// ReadFileRange returns lines start through end (1-based, inclusive) of a
+// file, each prefixed with its line number. The range is clamped to the file.
+func (fo *FileOperations) ReadFileRange(filename string, start, end int) (string, error) {
+	scanner, closeFile, err := fo.openLines(filename)
+	if err != nil {
+		return "", err
+	}
+	defer closeFile()
+
+	if start < 1 {
+		start = 1
+	}
+
+	var lines []string
+	for lineNo := 1; lineNo <= end && scanner.Scan(); lineNo++ {
+		if lineNo >= start {
+			lines = append(lines, fmt.Sprintf("%5d | %s", lineNo, scanner.Text()))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	return strings.Join(lines, "\n"), nil
+}
+
+// ExcerptFile returns the first and last n lines of a file with a
+// "[... N lines truncated ...]" note between them, or the whole file when it
+// has at most 2n lines. Like HeadFile it streams the file, but only files
+// ReadFile would send are allowed.
+func (fo *FileOperations) ExcerptFile(filename string, n int) (string, error) {
+	if err := fo.checkFileType(filename); err != nil {
+		return "", err
+	}
+	scanner, closeFile, err := fo.openLines(filename)
+	if err != nil {
+		return "", err
+	}
+	defer closeFile()
+
+	var head []string
+	ring := make([]string, n)
+	total := 0
+	for scanner.Scan() {
+		if total < n {
+			head = append(head, scanner.Text())
+		} else if n > 0 {
+			ring[(total-n)%n] = scanner.Text()
+		}
+		total++
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	rest := total - len(head)
+	if rest <= n {
+		// Everything after the head fits in the ring, in order
+		return strings.Join(append(head, ring[:rest]...), "\n"), nil
+	}
+
+	tail := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		tail = append(tail, ring[(rest+i)%n])
+	}
+	note := fmt.Sprintf("[... %d lines truncated ...]", rest-n)
+	return strings.Join(append(append(head, note), tail...), "\n"), nil
+}
+
+// openLines opens a file inside the working directory for line-by-line reading
+func (fo *FileOperations) openLines(filename string) (*bufio.Scanner, func(), error) {
+	fullPath, err := fo.resolvePath(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("file not found: %s", filename)
+	}
+
+	var reader io.Reader = file
+	closeFile := func() { file.Close() }
+
+	if strings.HasSuffix(strings.ToLower(filename), ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, nil, fmt.Errorf("failed to decompress %s: %v", filename, err)
+		}
+		reader = gz
+		closeFile = func() {
+			gz.Close()
+			file.Close()
+		}
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxLogLineSize)
+	return scanner, closeFile, nil
+}
@@ -0,0 +1,243 @@
+package agent
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FileQueryAction is the file operation a natural-language query asks for
+type FileQueryAction string
+
+const (
+	ReadAction   FileQueryAction = "read"
+	ListAction   FileQueryAction = "list"
+	SearchAction FileQueryAction = "search"
+	TreeAction   FileQueryAction = "tree"
+)
+
+// FileQueryIntent is a file query broken into what to do and what to do it to.
+// Flags holds optional modifiers: "dir" for "in <dir>" and "depth" for tree depth.
+type FileQueryIntent struct {
+	Action FileQueryAction
+	Target string
+	Flags  map[string]string
+}
+
+// fileQueryVerbs maps the verbs that start a file query to their action
+var fileQueryVerbs = map[string]FileQueryAction{
+	"read":    ReadAction,
+	"show":    ReadAction,
+	"open":    ReadAction,
+	"display": ReadAction,
+	"view":    ReadAction,
+	"print":   ReadAction,
+	"cat":     ReadAction,
+	"list":    ListAction,
+	"ls":      ListAction,
+	"find":    SearchAction,
+	"search":  SearchAction,
+	"locate":  SearchAction,
+	"where":   SearchAction,
+	"tree":    TreeAction,
+}
+
+// listNouns turn a read verb into a listing: "show files", "show me the directory"
+var listNouns = map[string]bool{"files": true, "directory": true, "directories": true, "folder": true, "folders": true, "dir": true}
+
+// treeNouns turn any file query into a tree: "show the project structure"
+var treeNouns = map[string]bool{"tree": true, "structure": true, "layout": true, "hierarchy": true}
+
+// dirPrepositions introduce the directory a query is limited to
+var dirPrepositions = map[string]bool{"in": true, "inside": true, "under": true, "within": true, "from": true}
+
+// depthWords mark a number as the tree depth: "2 levels", "depth 2", "-L 2"
+var depthWords = map[string]bool{"level": true, "levels": true, "depth": true, "deep": true, "-l": true}
+
+// fileQueryFiller are words skipped when looking for a query's target
+var fileQueryFiller = map[string]bool{
+	"me": true, "the": true, "a": true, "an": true, "file": true, "files": true, "contents": true,
+	"content": true, "of": true, "please": true, "for": true, "named": true, "called": true,
+	"all": true, "my": true, "our": true, "this": true, "that": true, "is": true, "are": true,
+	"up": true, "out": true, "project": true, "project's": true, "source": true, "code": true,
+	"to": true, "can": true, "you": true, "could": true, "would": true, "with": true, "name": true,
+}
+
+// extensionlessFiles are well-known file names without an extension
+var extensionlessFiles = map[string]bool{
+	"makefile": true, "dockerfile": true, "license": true, "readme": true, "gemfile": true,
+	"rakefile": true, "procfile": true, "jenkinsfile": true, "vagrantfile": true, "gnumakefile": true,
+	"containerfile": true, "brewfile": true, "justfile": true, "caddyfile": true,
+}
+
+// queryToken is one word of a query; quoted tokens keep their spaces
+type queryToken struct {
+	text   string
+	lower  string
+	quoted bool
+}
+
+// ParseFileQuery reads a natural-language file query such as "show me
+// pkg/agent/agent.go" or "find files named config". It scans for the first
+// known verb and takes the target from the words after it, preferring quoted
+// names and words that look like paths. ok is false when the query is not a
+// file query, including a read with nothing that looks like a file to read.
+func ParseFileQuery(query string) (intent FileQueryIntent, ok bool) {
+	tokens := tokenizeQuery(query)
+	intent.Flags = map[string]string{}
+
+	verb := -1
+	for i, tok := range tokens {
+		if action, found := fileQueryVerbs[tok.lower]; found && !tok.quoted {
+			intent.Action = action
+			verb = i
+			break
+		}
+	}
+
+	// "file tree" and "project structure" need no verb
+	for _, tok := range tokens {
+		if treeNouns[tok.lower] && !tok.quoted {
+			intent.Action = TreeAction
+		}
+	}
+	if intent.Action == "" {
+		return intent, false
+	}
+
+	rest := tokens[verb+1:]
+	var words []queryToken
+	for i := 0; i < len(rest); i++ {
+		tok := rest[i]
+		switch {
+		case tok.quoted:
+			words = append(words, tok)
+		case dirPrepositions[tok.lower] && i+1 < len(rest):
+			intent.Flags["dir"] = rest[i+1].text
+			i++
+		case depthWords[tok.lower]:
+			if i+1 < len(rest) && isNumber(rest[i+1].text) {
+				intent.Flags["depth"] = rest[i+1].text
+				i++
+			} else if i > 0 && isNumber(rest[i-1].text) {
+				intent.Flags["depth"] = rest[i-1].text
+			}
+		case listNouns[tok.lower] && intent.Action == ReadAction:
+			intent.Action = ListAction
+		case !fileQueryFiller[tok.lower] && !treeNouns[tok.lower] && !listNouns[tok.lower] && !isNumber(tok.text):
+			words = append(words, tok)
+		}
+	}
+
+	switch intent.Action {
+	case TreeAction:
+		return intent, true
+	case ListAction:
+		// "list files in pkg/agent" lists that directory
+		intent.Target = intent.Flags["dir"]
+		if intent.Target == "" {
+			if target, found := pathTarget(words); found {
+				intent.Target = target
+			}
+		}
+		delete(intent.Flags, "dir")
+		return intent, true
+	case SearchAction:
+		if target, found := pathTarget(words); found {
+			intent.Target = target
+		} else if len(words) > 0 {
+			intent.Target = words[0].text
+		}
+		return intent, true
+	}
+
+	// A read needs something that looks like a file; "show me how this
+	// works" is a question, not a file query
+	if target, found := pathTarget(words); found {
+		intent.Target = target
+		return intent, true
+	}
+	// "what's in main.go" names the file after the preposition
+	if dir := intent.Flags["dir"]; looksLikePath(dir) {
+		intent.Target = dir
+		delete(intent.Flags, "dir")
+		return intent, true
+	}
+	return intent, false
+}
+
+// pathTarget returns the first quoted word, or failing that the first word
+// that looks like a file path
+func pathTarget(words []queryToken) (string, bool) {
+	for _, word := range words {
+		if word.quoted {
+			return word.text, true
+		}
+	}
+	for _, word := range words {
+		if looksLikePath(word.text) {
+			return word.text, true
+		}
+	}
+	return "", false
+}
+
+// looksLikePath reports whether a word names a file: it has a directory
+// separator or an extension, or is a known extensionless name like Makefile
+func looksLikePath(word string) bool {
+	if strings.ContainsAny(word, `/\`) {
+		return true
+	}
+	if extensionlessFiles[strings.ToLower(word)] {
+		return true
+	}
+	dot := strings.LastIndex(word, ".")
+	return dot >= 0 && dot < len(word)-1 && strings.IndexFunc(word, unicode.IsLetter) >= 0
+}
+
+// isNumber reports whether s is a run of digits
+func isNumber(s string) bool {
+	return s != "" && strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) }) < 0
+}
+
+// tokenizeQuery splits a query into words. Text in double, single or back
+// quotes is one token; an apostrophe inside a word (what's) does not quote.
+// Sentence punctuation is trimmed from the ends of unquoted words.
+func tokenizeQuery(query string) []queryToken {
+	var tokens []queryToken
+	var word strings.Builder
+	flush := func() {
+		text := strings.TrimRight(word.String(), "?!,.;:)")
+		text = strings.TrimLeft(text, "(")
+		word.Reset()
+		if text != "" {
+			tokens = append(tokens, queryToken{text: text, lower: strings.ToLower(text)})
+		}
+	}
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case (r == '"' || r == '`' || r == '\'') && word.Len() == 0:
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				// No closing quote: treat it as an ordinary character
+				word.WriteRune(r)
+				continue
+			}
+			if text := string(runes[i+1 : end]); text != "" {
+				tokens = append(tokens, queryToken{text: text, lower: strings.ToLower(text), quoted: true})
+			}
+			i = end
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+	return tokens
+}
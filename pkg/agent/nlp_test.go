@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFileQuery(t *testing.T) {
+	tests := []struct {
+		query  string
+		ok     bool
+		action FileQueryAction
+		target string
+		flags  map[string]string
+	}{
+		// Reads
+		{"read main.go", true, ReadAction, "main.go", nil},
+		{"show me pkg/agent/agent.go", true, ReadAction, "pkg/agent/agent.go", nil},
+		{"Can you open the file pkg/cli/cli.go please?", true, ReadAction, "pkg/cli/cli.go", nil},
+		{"cat ./configs/config.json", true, ReadAction, "./configs/config.json", nil},
+		{"display the contents of README.md.", true, ReadAction, "README.md", nil},
+		{"show the Makefile", true, ReadAction, "Makefile", nil},
+		{"open Dockerfile", true, ReadAction, "Dockerfile", nil},
+		{`read "my notes.txt"`, true, ReadAction, "my notes.txt", nil},
+		{"view 'docs/user guide.md'", true, ReadAction, "docs/user guide.md", nil},
+		{"print `go.mod`", true, ReadAction, "go.mod", nil},
+		{`show pkg\agent\nlp.go`, true, ReadAction, `pkg\agent\nlp.go`, nil},
+		{"show me what's in main.go", true, ReadAction, "main.go", nil},
+		{"display .gitignore", true, ReadAction, ".gitignore", nil},
+
+		// Listings
+		{"list files", true, ListAction, "", nil},
+		{"list files in pkg/agent", true, ListAction, "pkg/agent", nil},
+		{"show me the files in cmd", true, ListAction, "cmd", nil},
+		{"ls pkg/ui", true, ListAction, "pkg/ui", nil},
+
+		// Searches
+		{"find files named config", true, SearchAction, "config", nil},
+		{"search for *.go in pkg", true, SearchAction, "*.go", map[string]string{"dir": "pkg"}},
+		{"where is cookies.go?", true, SearchAction, "cookies.go", nil},
+		{`locate "selectors.json"`, true, SearchAction, "selectors.json", nil},
+
+		// Trees
+		{"tree", true, TreeAction, "", nil},
+		{"show the project structure", true, TreeAction, "", nil},
+		{"show the file tree 2 levels deep", true, TreeAction, "", map[string]string{"depth": "2"}},
+		{"tree depth 3 in pkg", true, TreeAction, "", map[string]string{"depth": "3", "dir": "pkg"}},
+
+		// Not file queries
+		{"show me how this works", false, "", "", nil},
+		{"explain the retry logic", false, "", "", nil},
+		{"why does the build fail?", false, "", "", nil},
+		{"open questions about the design", false, "", "", nil},
+		{"", false, "", "", nil},
+		{`"read main.go"`, false, "", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			intent, ok := ParseFileQuery(tt.query)
+			if ok != tt.ok {
+				t.Fatalf("ParseFileQuery(%q) ok = %v, want %v (intent %+v)", tt.query, ok, tt.ok, intent)
+			}
+			if !ok {
+				return
+			}
+			if intent.Action != tt.action || intent.Target != tt.target {
+				t.Errorf("ParseFileQuery(%q) = %s %q, want %s %q", tt.query, intent.Action, intent.Target, tt.action, tt.target)
+			}
+			want := tt.flags
+			if want == nil {
+				want = map[string]string{}
+			}
+			if !reflect.DeepEqual(intent.Flags, want) {
+				t.Errorf("ParseFileQuery(%q) flags = %v, want %v", tt.query, intent.Flags, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package agent
+
+import (
+	"fmt"
+	"sort"
+)
+
+// PinFile adds a file to the working set whose content is attached to every
+// prompt by the "file_refs" pipeline stage
+func (a *Agent) PinFile(filename string) error {
+	if !a.fileOps.Exists(filename) || a.fileOps.isDir(filename) {
+		return fmt.Errorf("file not found: %s", filename)
+	}
+	if a.pinned == nil {
+		a.pinned = make(map[string]bool)
+	}
+	a.pinned[filename] = true
+	return nil
+}
+
+// PinnedFiles returns the pinned files in alphabetical order
+func (a *Agent) PinnedFiles() []string {
+	files := make([]string, 0, len(a.pinned))
+	for filename := range a.pinned {
+		files = append(files, filename)
+	}
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,227 @@
+package agent
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/chatgpt-element-recorder/pkg/ui"
+)
+
+// PromptTransform rewrites a prompt before it is sent
+type PromptTransform func(string) string
+
+// PromptPipeline applies the configured transforms to a prompt in order
+type PromptPipeline struct {
+	stages []PromptTransform
+}
+
+// Apply runs the prompt through every stage
+func (p *PromptPipeline) Apply(prompt string) string {
+	for _, stage := range p.stages {
+		prompt = stage(prompt)
+	}
+	return prompt
+}
+
+// newPromptPipeline builds the pipeline from stage names, skipping unknown ones
+func newPromptPipeline(a *Agent, names []string) *PromptPipeline {
+	available := map[string]PromptTransform{
+		"file_refs": a.expandMentions,
+		"redact":    redactSecrets,
+		"context":   a.enhanceWithContext,
+	}
+
+	pipeline := &PromptPipeline{}
+	for _, name := range names {
+		stage, ok := available[name]
+		if !ok {
+			ui.PrintWarning(fmt.Sprintf("Unknown prompt pipeline stage: %s", name))
+			continue
+		}
+		pipeline.stages = append(pipeline.stages, stage)
+	}
+	return pipeline
+}
+
+// mention matches an @path reference at the start of a word
+var mention = regexp.MustCompile(`(^|\s)@([\w./-]+)`)
+
+// CheckMention returns an error if an @path mention of path would be sent as
+// is rather than expanded: the file_refs stage is off or the path is neither
+// a directory nor a file ReadFile accepts
+func (a *Agent) CheckMention(path string) error {
+	if !slices.Contains(a.config.Agent.PromptPipeline, "file_refs") {
+		return fmt.Errorf("@file mentions are not expanded: add \"file_refs\" to agent.prompt_pipeline")
+	}
+	if a.fileOps.isDir(path) {
+		return nil
+	}
+	_, err := a.ReadFile(path)
+	return err
+}
+
+// expandMentions resolves @file and @dir/ mentions to project paths, replacing
+// each with the bare path and appending labeled content blocks after the
+// prompt. Directories list their files and include as many contents as the
+// mention budget allows. Mentions that don't resolve pass through unchanged.
+// Pinned files are appended the same way, within the same budget.
+func (a *Agent) expandMentions(prompt string) string {
+	var blocks []string
+	seen := make(map[string]bool)
+	budget := a.config.Agent.MentionBudget
+
+	expanded := mention.ReplaceAllStringFunc(prompt, func(match string) string {
+		groups := mention.FindStringSubmatch(match)
+		lead, path := groups[1], groups[2]
+
+		// Allow sentence punctuation right after the mention
+		trailing := ""
+		for strings.HasSuffix(path, ".") && len(path) > 1 {
+			path, trailing = strings.TrimSuffix(path, "."), "."+trailing
+		}
+
+		var block string
+		if a.fileOps.isDir(path) {
+			block = a.dirSection(path, &budget)
+		} else if content, err := a.ReadFile(path); err == nil {
+			block = fileSection(path, clipToBudget(content, &budget))
+		} else {
+			return match
+		}
+
+		if !seen[path] {
+			seen[path] = true
+			blocks = append(blocks, block)
+		}
+		return lead + path + trailing
+	})
+
+	// Pinned files come along with every prompt unless it already mentions them
+	for _, path := range a.PinnedFiles() {
+		if seen[path] {
+			continue
+		}
+		content, err := a.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		seen[path] = true
+		blocks = append(blocks, fileSection(path, clipToBudget(content, &budget)))
+	}
+
+	if len(blocks) == 0 {
+		return prompt
+	}
+	return expanded + "\n\n" + strings.Join(blocks, "\n\n")
+}
+
+// dirSection lists a directory's files and includes their contents until the budget runs out
+func (a *Agent) dirSection(dir string, budget *int) string {
+	files, err := a.ListFiles(dir)
+	if err != nil || len(files) == 0 {
+		return fmt.Sprintf("Directory %s is empty or unreadable.", dir)
+	}
+
+	var section strings.Builder
+	section.WriteString(fmt.Sprintf("Directory %s (%d files):\n", dir, len(files)))
+	for _, file := range files {
+		section.WriteString("- " + file.Path + "\n")
+	}
+
+	omitted := 0
+	for _, file := range files {
+		if *budget <= 0 {
+			omitted++
+			continue
+		}
+		content, err := a.ReadFile(file.Path)
+		if err != nil {
+			continue // binary or disallowed types are only listed
+		}
+		section.WriteString("\n" + fileSection(file.Path, clipToBudget(content, budget)) + "\n")
+	}
+	if omitted > 0 {
+		section.WriteString(fmt.Sprintf("\n(%d more files omitted to stay within the size budget)\n", omitted))
+	}
+
+	return strings.TrimRight(section.String(), "\n")
+}
+
+// clipToBudget truncates content to the remaining budget and charges it
+func clipToBudget(content string, budget *int) string {
+	if *budget <= 0 {
+		return "(omitted to stay within the size budget)"
+	}
+	if len(content) > *budget {
+		content = cutAtRune(content, *budget) + "\n... (truncated)"
+		*budget = 0
+		return content
+	}
+	*budget -= len(content)
+	return content
+}
+
+// fileSection formats a file's content for inclusion in a prompt
+func fileSection(filename, content string) string {
+	return fmt.Sprintf("Here's the content of %s:\n\n```\n%s\n```", filename, content)
+}
+
+// secretPatterns match common credentials, with the replacement keeping any
+// identifying prefix so the model still knows what was there
+var secretPatterns = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), "[REDACTED PRIVATE KEY]"},
+	{regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`), "sk-[REDACTED]"},
+	{regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}`), "[REDACTED GITHUB TOKEN]"},
+	{regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`), "[REDACTED AWS KEY]"},
+	{regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9._~+/-]{20,}=*`), "${1}[REDACTED]"},
+	{regexp.MustCompile(`(?i)\b(password|passwd|secret|api[_-]?key|access[_-]?token)(\s*[:=]\s*)(["']?)[^\s"']{6,}`), "${1}${2}${3}[REDACTED]"},
+}
+
+// redactSecrets masks credentials so they aren't sent to ChatGPT. Fenced
+// blocks are left alone: they hold files and command output the user chose to
+// send, where a pattern like password= is as likely code as a credential.
+func redactSecrets(prompt string) string {
+	lines := strings.SplitAfter(prompt, "\n")
+	var out, prose strings.Builder
+	flush := func() {
+		text := prose.String()
+		for _, s := range secretPatterns {
+			text = s.pattern.ReplaceAllString(text, s.replacement)
+		}
+		out.WriteString(text)
+		prose.Reset()
+	}
+
+	inFence := false
+	for _, line := range lines {
+		trim := strings.TrimSpace(line)
+		if strings.HasPrefix(trim, "```") || strings.HasPrefix(trim, "~~~") {
+			if !inFence {
+				flush()
+			}
+			inFence = !inFence
+			out.WriteString(line)
+			continue
+		}
+		if inFence {
+			out.WriteString(line)
+		} else {
+			prose.WriteString(line)
+		}
+	}
+	flush()
+	return out.String()
+}
+
+// enhanceWithContext adds project context when project analysis is enabled
+func (a *Agent) enhanceWithContext(prompt string) string {
+	if a.context == nil {
+		return prompt
+	}
+	return a.context.EnhanceMessage(prompt)
+}
@@ -0,0 +1,55 @@
+package agent
+
+import "testing"
+
+func TestRedactSecrets(t *testing.T) {
+	fileBody := "db:\n  password: hunter2hunter2\n  api_key = \"abcdef123456\"\nsecret := os.Getenv(\"SECRET\")\n"
+
+	tests := []struct {
+		name   string
+		prompt string
+		want   string
+	}{
+		{"password in prose", "my password: hunter2hunter2 fails", "my password: [REDACTED] fails"},
+		{"openai key in prose", "use sk-abcdefghijklmnopqrstuvwxyz", "use sk-[REDACTED]"},
+		{"file section", fileSection("config.yaml", fileBody), fileSection("config.yaml", fileBody)},
+		{"file after a prompt", "Please review this file\n\nconfig.yaml:\n```\n" + fileBody + "```",
+			"Please review this file\n\nconfig.yaml:\n```\n" + fileBody + "```"},
+		{"prose around a block", "token=abcdef123456\n```\npassword=abcdef123456\n```\napi_key: abcdef123456",
+			"token=abcdef123456\n```\npassword=abcdef123456\n```\napi_key: [REDACTED]"},
+		{"tilde fence", "~~~\nsecret=abcdef123456\n~~~", "~~~\nsecret=abcdef123456\n~~~"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactSecrets(tt.prompt); got != tt.want {
+				t.Errorf("redactSecrets(%q) = %q, want %q", tt.prompt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClipToBudget(t *testing.T) {
+	tests := []struct {
+		content string
+		budget  int
+		want    string
+		left    int
+	}{
+		{"hello", 10, "hello", 5},
+		{"hello", 3, "hel\n... (truncated)", 0},
+		{"héllo", 2, "h\n... (truncated)", 0}, // é is two bytes
+		{"héllo", 3, "hé\n... (truncated)", 0},
+		{"日本", 4, "日\n... (truncated)", 0},
+		{"日本", 2, "\n... (truncated)", 0},
+		{"hello", 0, "(omitted to stay within the size budget)", 0},
+	}
+
+	for _, tt := range tests {
+		budget := tt.budget
+		got := clipToBudget(tt.content, &budget)
+		if got != tt.want || budget != tt.left {
+			t.Errorf("clipToBudget(%q, %d) = %q with %d left, want %q with %d left", tt.content, tt.budget, got, budget, tt.want, tt.left)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/chatgpt-element-recorder/pkg/ui"
+)
+
+// defaultMaxAutoSteps caps a plan when agent.max_auto_steps is unset
+const defaultMaxAutoSteps = 10
+
+// planPrompt asks for the goal broken into subtasks as a JSON array
+const planPrompt = `Break the following goal into a short, ordered list of concrete subtasks that can each be done in one reply. Answer with only a JSON array of strings, one subtask per element, and no other text.
+
+Goal: %s`
+
+// ErrPlanNeedsConfirm is returned by auto mode when a plan can't be confirmed
+// because stdin is not a terminal
+var ErrPlanNeedsConfirm = errors.New("auto mode asks before running a plan, but stdin is not a terminal (pass --yes or set agent.auto_confirm to true)")
+
+// Plan asks ChatGPT to break a goal into subtasks, at most agent.max_auto_steps of them
+func (a *Agent) Plan(goal string) ([]string, error) {
+	response, err := RetryOnRateLimit(a.config.ChatGPT.RateLimit, func() (string, error) {
+		spinner := ui.NewSquareSpinner()
+		spinner.Start("Planning...")
+		defer spinner.Stop()
+		return a.send(fmt.Sprintf(planPrompt, goal))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := parsePlan(response)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := a.config.Agent.MaxAutoSteps
+	if limit <= 0 {
+		limit = defaultMaxAutoSteps
+	}
+	if len(steps) > limit {
+		ui.PrintWarning(fmt.Sprintf("The plan has %d steps - keeping the first %d (agent.max_auto_steps)", len(steps), limit))
+		steps = steps[:limit]
+	}
+	return steps, nil
+}
+
+// parsePlan reads the JSON array of subtasks from a planning response. The
+// array may sit in a code block or between other text; elements may be
+// strings or objects naming the task.
+func parsePlan(response string) ([]string, error) {
+	start := strings.Index(response, "[")
+	end := strings.LastIndex(response, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("the plan is not a JSON list of subtasks")
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal([]byte(response[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse the plan: %v", err)
+	}
+
+	var steps []string
+	for _, item := range raw {
+		var text string
+		if json.Unmarshal(item, &text) != nil {
+			var task map[string]interface{}
+			if json.Unmarshal(item, &task) != nil {
+				continue
+			}
+			for _, key := range []string{"task", "title", "description", "step"} {
+				if value, ok := task[key].(string); ok {
+					text = value
+					break
+				}
+			}
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			steps = append(steps, text)
+		}
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("the plan has no subtasks")
+	}
+	return steps, nil
+}
+
+// runPlan plans a goal, asks before running it, then sends each subtask in
+// order and returns the answers under a heading per step
+func (a *Agent) runPlan(goal string) (string, error) {
+	// Checked before planning so no request is spent on a plan that can't run
+	if !ui.CanConfirm() {
+		return "", ErrPlanNeedsConfirm
+	}
+
+	steps, err := a.Plan(goal)
+	if err != nil {
+		return "", err
+	}
+
+	// Status output, so --output-format json keeps stdout for the answer
+	ui.Statusf("\n📋 Plan (%d steps):\n", len(steps))
+	for i, step := range steps {
+		ui.Statusf("  %d. %s\n", i+1, step)
+	}
+	if !ui.Confirm("Run this plan?") {
+		return "Plan cancelled.", nil
+	}
+
+	var results strings.Builder
+	progress := ui.NewProgressBar(len(steps))
+	for i, step := range steps {
+		progress.Increment(step)
+
+		// Each step is sent as an interactive message; ProcessMessage would
+		// plan the step again while the agent is in auto mode. A rate limit
+		// is waited out here so the steps already run aren't sent again.
+		response, err := RetryOnRateLimit(a.config.ChatGPT.RateLimit, func() (string, error) {
+			return a.processInteractive(step)
+		})
+		if err != nil {
+			progress.Done()
+			return results.String(), fmt.Errorf("step %d failed: %w", i+1, err)
+		}
+
+		if i > 0 {
+			results.WriteString("\n\n")
+		}
+		results.WriteString(fmt.Sprintf("## Step %d: %s\n\n%s", i+1, step, response))
+	}
+	progress.Done()
+	ui.PrintSuccess(fmt.Sprintf("Completed %d steps", len(steps)))
+	return results.String(), nil
+}
@@ -0,0 +1,203 @@
+package agent
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chatgpt-element-recorder/pkg/config"
+)
+
+// reportBudget caps the characters of the project report sent to ChatGPT
+const reportBudget = 16000
+
+// maxCountedFileSize skips files too large to be hand-written code when counting lines
+const maxCountedFileSize = 2 << 20
+
+// reportRequest asks for the assessment after the report
+const reportRequest = `Based on this report, give an architectural assessment of the project: how it is organized, the main components and how they depend on each other, strengths, risks, and the most valuable improvements. Refer to concrete files and directories.`
+
+// ProjectReport assembles a structured report of the project: files by
+// category, lines of code by language, dependencies, git state and entry
+// points, clipped to the report budget
+func (a *Agent) ProjectReport() (string, error) {
+	files, err := a.fileOps.ListFiles("")
+	if err != nil {
+		return "", fmt.Errorf("failed to list files: %v", err)
+	}
+
+	var report strings.Builder
+	report.WriteString("# Project report\n\n")
+	if a.context != nil {
+		report.WriteString(a.context.GetProjectInfo())
+	}
+
+	report.WriteString("\n## Files\n")
+	categories := make(map[string]int)
+	for _, f := range files {
+		categories[string(f.Category)]++
+	}
+	report.WriteString(fmt.Sprintf("Total: %d\n", len(files)))
+	for _, category := range sortedKeys(categories) {
+		report.WriteString(fmt.Sprintf("- %s: %d\n", category, categories[category]))
+	}
+
+	report.WriteString("\n## Lines of code\n")
+	loc := a.countLines(files)
+	total := 0
+	for _, ext := range sortedKeys(loc) {
+		report.WriteString(fmt.Sprintf("- %s: %d\n", ext, loc[ext]))
+		total += loc[ext]
+	}
+	report.WriteString(fmt.Sprintf("Total: %d\n", total))
+
+	report.WriteString("\n## Dependencies\n")
+	deps := a.dependencies()
+	if len(deps) == 0 {
+		report.WriteString("None found\n")
+	}
+	for _, dep := range deps {
+		report.WriteString("- " + dep + "\n")
+	}
+
+	report.WriteString("\n## Git\n")
+	report.WriteString(a.gitSummary())
+
+	if a.context != nil {
+		if entries := a.context.GetAnalysis().Structure.MainFiles; len(entries) > 0 {
+			report.WriteString("\n## Entry points\n")
+			for _, entry := range entries {
+				report.WriteString("- " + entry + "\n")
+			}
+		}
+	}
+
+	budget := reportBudget
+	return clipToBudget(report.String(), &budget), nil
+}
+
+// SendProjectReport sends the project report with the architecture prompt
+// and returns the assessment
+func (a *Agent) SendProjectReport() (string, error) {
+	report, err := a.ProjectReport()
+	if err != nil {
+		return "", err
+	}
+
+	role := "Provide guidance on system design, architecture patterns, and scalability."
+	if prompts, err := config.GetPrompts(); err == nil {
+		if mode, ok := prompts.SystemPrompts.SpecializedModes["architecture"]; ok {
+			role = mode
+		}
+	}
+
+	return a.chatgpt.SendMessage(fmt.Sprintf("%s\n\n%s\n\n%s", role, report, reportRequest))
+}
+
+// countLines counts lines of code files by extension
+func (a *Agent) countLines(files []FileInfo) map[string]int {
+	loc := make(map[string]int)
+	for _, f := range files {
+		if f.Category != CodeFile || f.Size > maxCountedFileSize {
+			continue
+		}
+		file, err := os.Open(filepath.Join(a.fileOps.workingDir, f.Path))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxCountedFileSize)
+		for scanner.Scan() {
+			if strings.TrimSpace(scanner.Text()) != "" {
+				loc[f.Extension]++
+			}
+		}
+		file.Close()
+	}
+	return loc
+}
+
+// dependencies lists direct dependencies from go.mod, package.json and requirements.txt
+func (a *Agent) dependencies() []string {
+	var deps []string
+	root := a.fileOps.workingDir
+
+	if info, err := parseGoMod(filepath.Join(root, "go.mod")); err == nil {
+		for _, require := range info.Require {
+			deps = append(deps, "go: "+require)
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, "package.json")); err == nil {
+		var pkg struct {
+			Dependencies    map[string]string `json:"dependencies"`
+			DevDependencies map[string]string `json:"devDependencies"`
+		}
+		if json.Unmarshal(data, &pkg) == nil {
+			var npm []string
+			for name, version := range pkg.Dependencies {
+				npm = append(npm, fmt.Sprintf("npm: %s %s", name, version))
+			}
+			for name, version := range pkg.DevDependencies {
+				npm = append(npm, fmt.Sprintf("npm (dev): %s %s", name, version))
+			}
+			sort.Strings(npm)
+			deps = append(deps, npm...)
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, "requirements.txt")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "#") {
+				deps = append(deps, "pip: "+line)
+			}
+		}
+	}
+
+	return deps
+}
+
+// gitSummary describes the branch, last commit and uncommitted changes
+func (a *Agent) gitSummary() string {
+	git := func(args ...string) (string, error) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = a.fileOps.workingDir
+		out, err := cmd.Output()
+		return strings.TrimSpace(string(out)), err
+	}
+
+	branch, err := git("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "Not a git repository\n"
+	}
+
+	var summary strings.Builder
+	summary.WriteString(fmt.Sprintf("Branch: %s\n", branch))
+	if last, err := git("log", "-1", "--format=%h %s (%cr)"); err == nil && last != "" {
+		summary.WriteString(fmt.Sprintf("Last commit: %s\n", last))
+	}
+	if status, err := git("status", "--porcelain"); err == nil {
+		changed := 0
+		if status != "" {
+			changed = len(strings.Split(status, "\n"))
+		}
+		summary.WriteString(fmt.Sprintf("Uncommitted changes: %d files\n", changed))
+	}
+	return summary.String()
+}
+
+// sortedKeys returns the keys of a count map in sorted order
+func sortedKeys(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
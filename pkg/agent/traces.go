@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// traceContextLines is how many lines around each referenced line are sent
+const traceContextLines = 8
+
+// maxTraceRefs caps the code excerpts sent with a trace
+const maxTraceRefs = 6
+
+// TraceRef is a file:line location mentioned in an error or stack trace
+type TraceRef struct {
+	File string
+	Line int
+}
+
+// tracePatterns match file:line references in Python, JS and Go traces. Each
+// has the file in group 1 and the line in group 2.
+var tracePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`File "([^"]+)", line (\d+)`),                              // Python
+	regexp.MustCompile(`([^\s()]+\.(?:[cm]?js|jsx|ts|tsx|vue|svelte)):(\d+):\d+`), // JS: at foo (bar.js:42:1)
+	regexp.MustCompile(`([^\s:()"']+\.go):(\d+)`),                                 // Go: file.go:42
+}
+
+// ParseTraceRefs returns the distinct file:line references in a trace, in order
+func ParseTraceRefs(trace string) []TraceRef {
+	type match struct {
+		pos int
+		ref TraceRef
+	}
+	var matches []match
+	for _, pattern := range tracePatterns {
+		for _, m := range pattern.FindAllStringSubmatchIndex(trace, -1) {
+			line, err := strconv.Atoi(trace[m[4]:m[5]])
+			if err != nil {
+				continue
+			}
+			matches = append(matches, match{m[0], TraceRef{File: trace[m[2]:m[3]], Line: line}})
+		}
+	}
+
+	// Keep the trace's own order across the different patterns
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].pos < matches[j].pos })
+
+	seen := make(map[TraceRef]bool)
+	var refs []TraceRef
+	for _, m := range matches {
+		if !seen[m.ref] {
+			seen[m.ref] = true
+			refs = append(refs, m.ref)
+		}
+	}
+	return refs
+}
+
+// projectPath maps a path from a trace to a path inside the working directory.
+// Absolute paths must lie inside the project; file:// prefixes are dropped.
+func (a *Agent) projectPath(path string) (string, bool) {
+	path = strings.TrimPrefix(path, "file://")
+	if filepath.IsAbs(path) {
+		rel, err := filepath.Rel(a.fileOps.workingDir, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return "", false
+		}
+		path = rel
+	}
+	if !a.fileOps.Exists(path) || a.fileOps.isDir(path) {
+		return "", false
+	}
+	return path, true
+}
+
+// ExplainError sends a pasted error or stack trace to ChatGPT together with
+// the project code around every file:line it references
+func (a *Agent) ExplainError(trace string) (string, error) {
+	var excerpts []string
+	budget := a.config.Agent.MentionBudget
+	for _, ref := range ParseTraceRefs(trace) {
+		if len(excerpts) == maxTraceRefs {
+			break
+		}
+		path, ok := a.projectPath(ref.File)
+		if !ok {
+			continue
+		}
+		code, err := a.fileOps.ReadFileRange(path, ref.Line-traceContextLines, ref.Line+traceContextLines)
+		if err != nil || code == "" {
+			continue
+		}
+		excerpts = append(excerpts, fmt.Sprintf("%s around line %d:\n\n```\n%s\n```", path, ref.Line, clipToBudget(code, &budget)))
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString(fmt.Sprintf("I got this error:\n\n```\n%s\n```\n\n", strings.TrimSpace(trace)))
+	if len(excerpts) > 0 {
+		prompt.WriteString("Here is the code it points to in my project:\n\n")
+		prompt.WriteString(strings.Join(excerpts, "\n\n"))
+		prompt.WriteString("\n\n")
+	}
+	prompt.WriteString("Explain what is going wrong, the most likely root cause, and how to fix it.")
+
+	return a.send(prompt.String())
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/chatgpt-element-recorder/pkg/config"
+	"github.com/chatgpt-element-recorder/pkg/file"
 	"github.com/chatgpt-element-recorder/pkg/ui"
 	"github.com/chromedp/cdproto/cdp"
 	"github.com/chromedp/cdproto/network"
@@ -132,27 +134,16 @@ func LoadCookiesAction() chromedp.Action {
 	})
 }
 
-// isChatGPTDomain checks if domain belongs to ChatGPT
+// isChatGPTDomain checks if domain belongs to ChatGPT, including any
+// browser.allowed_domains from config
 func isChatGPTDomain(domain string) bool {
-	chatgptDomains := []string{
-		"chatgpt.com",
-		".chatgpt.com", 
-		"chat.openai.com",
-		".openai.com",
-	}
-	
-	for _, chatgptDomain := range chatgptDomains {
-		if domain == chatgptDomain {
-			return true
-		}
-	}
-	return false
+	cfg, _ := config.LoadDynamicConfig()
+	return cfg.IsAllowedDomain(domain)
 }
 
 // SaveCookiesAction retrieves cookies from the browser and saves them to a file.
 func SaveCookiesAction() chromedp.Action {
 	return chromedp.ActionFunc(func(ctx context.Context) error {
-		log.Println("Saving cookies to", config.CookiesFile)
 		cookies, err := network.GetCookies().Do(ctx)
 		if err != nil {
 			return err
@@ -163,10 +154,48 @@ func SaveCookiesAction() chromedp.Action {
 			return err
 		}
 
-		return os.WriteFile(config.CookiesFile, cookiesData, 0644)
+		path := NewCookieManager().GetCookiesPath()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		return file.WriteFileAtomic(path, cookiesData, 0644)
 	})
 }
 
+// TakeScreenshot captures the visible part of the page as a PNG
+func TakeScreenshot(ctx context.Context) ([]byte, error) {
+	var png []byte
+	if err := chromedp.Run(ctx, chromedp.CaptureScreenshot(&png)); err != nil {
+		return nil, fmt.Errorf("failed to take screenshot: %v", err)
+	}
+	return png, nil
+}
+
+// SaveScreenshot takes a screenshot and writes it to filename, or to
+// screenshot-<timestamp>.png in files.output_dir when filename is empty.
+// It returns the file written.
+func SaveScreenshot(ctx context.Context, filename string) (string, error) {
+	png, err := TakeScreenshot(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if filename == "" {
+		cfg, _ := config.LoadDynamicConfig()
+		filename = filepath.Join(cfg.Files.OutputDir,
+			fmt.Sprintf("screenshot-%s.png", time.Now().Format("20060102-150405")))
+	}
+	if dir := filepath.Dir(filename); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create output directory: %v", err)
+		}
+	}
+	if err := os.WriteFile(filename, png, 0644); err != nil {
+		return "", fmt.Errorf("failed to save screenshot: %v", err)
+	}
+	return filename, nil
+}
+
 // WaitForUserInteraction waits for user to perform an action and provides instructions
 func WaitForUserInteraction(instruction string) chromedp.Action {
 	return chromedp.ActionFunc(func(ctx context.Context) error {
@@ -183,15 +212,60 @@ func WaitForUserInteraction(instruction string) chromedp.Action {
 	})
 }
 
-// WaitForChatGPTLoad waits for ChatGPT to fully load
-func WaitForChatGPTLoad() chromedp.Action {
+// blankGrace is how long a blank page is tolerated before reloading
+const blankGrace = 2 * time.Second
+
+// WaitForChatGPTReady polls until inputSelector is present or timeout passes.
+// ChatGPT sometimes renders an empty body on the first load; if the page stays
+// blank (no main element, no text) past a short grace period it is reloaded once.
+func WaitForChatGPTReady(inputSelector string, timeout time.Duration) chromedp.Action {
 	return chromedp.ActionFunc(func(ctx context.Context) error {
-		// Just wait for basic page elements - no noisy logging
-		err := chromedp.WaitVisible(`main`, chromedp.ByQuery).Do(ctx)
-		if err != nil {
-			// Fallback - wait for body
-			chromedp.WaitVisible(`body`, chromedp.ByQuery).Do(ctx)
+		stateScript := fmt.Sprintf(`
+			(function() {
+				if (document.querySelector(%q)) return 'ready';
+				const body = document.body;
+				const blank = !document.querySelector('main') && (!body || body.innerText.trim() === '');
+				return blank ? 'blank' : 'loading';
+			})();
+		`, inputSelector)
+
+		deadline := time.Now().Add(timeout)
+		var blankSince time.Time
+		reloaded := false
+
+		// A zero timeout waits until the page is ready
+		for timeout <= 0 || time.Now().Before(deadline) {
+			var state string
+			if err := chromedp.Evaluate(stateScript, &state).Do(ctx); err != nil {
+				// The document may be mid-navigation; try again on the next tick
+				state = "loading"
+			}
+
+			switch state {
+			case "ready":
+				return nil
+			case "blank":
+				if blankSince.IsZero() {
+					blankSince = time.Now()
+				}
+				if !reloaded && time.Since(blankSince) >= blankGrace {
+					if err := chromedp.Reload().Do(ctx); err != nil {
+						return fmt.Errorf("failed to reload blank page: %v", err)
+					}
+					reloaded = true
+					blankSince = time.Time{}
+				}
+			default:
+				blankSince = time.Time{}
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(250 * time.Millisecond):
+			}
 		}
-		return nil
+
+		return fmt.Errorf("ChatGPT did not become ready within %s", timeout)
 	})
 }
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/chatgpt-element-recorder/pkg/config"
+	"github.com/chatgpt-element-recorder/pkg/file"
 	"github.com/chatgpt-element-recorder/pkg/ui"
 )
 
@@ -33,7 +34,7 @@ func NewCookieManager() *CookieManager {
 	cfg, err := config.LoadDynamicConfig()
 	cookiesPath := "cookies/chatgpt.json" // default
 	if err == nil {
-		cookiesPath = cfg.Files.CookiesFile
+		cookiesPath = cfg.GetCookiesPath()
 	}
 	
 	return &CookieManager{
@@ -175,19 +176,7 @@ func (cm *CookieManager) validateCookieContent(cookies []CookieInfo) error {
 
 // isChatGPTCookie checks if cookie belongs to ChatGPT
 func (cm *CookieManager) isChatGPTCookie(cookie CookieInfo) bool {
-	chatgptDomains := []string{
-		"chatgpt.com",
-		".chatgpt.com", 
-		"chat.openai.com",
-		".openai.com",
-	}
-	
-	for _, domain := range chatgptDomains {
-		if cookie.Domain == domain {
-			return true
-		}
-	}
-	return false
+	return isChatGPTDomain(cookie.Domain)
 }
 
 // isSessionCookie checks if cookie is session-related
@@ -268,8 +257,9 @@ func (cm *CookieManager) SaveCookies(cookies []CookieInfo) error {
 		return fmt.Errorf("failed to marshal cookies: %v", err)
 	}
 
-	// Write to file
-	if err := os.WriteFile(cm.cookiesPath, data, 0644); err != nil {
+	// Write to a temporary file and rename it, so a crash mid-save keeps the
+	// previous cookies intact
+	if err := file.WriteFileAtomic(cm.cookiesPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to save cookies: %v", err)
 	}
 
@@ -0,0 +1,78 @@
+package chatgpt
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// citationMarker matches inline citation markers such as "【4†source】" that
+// some answers carry as plain text rather than as chips
+var citationMarker = regexp.MustCompile(`【[^】]*】`)
+
+// hideCitationsJS defines hideCitations(root), which hides the citation chips
+// inside root and returns a function that shows them again. Reading innerText
+// in between leaves the chips out while keeping the rendered layout, which a
+// detached clone would lose.
+func hideCitationsJS() string {
+	selectorJSON, _ := json.Marshal(CitationChip)
+	return fmt.Sprintf(`
+		function hideCitations(root) {
+			const chips = Array.from(root.querySelectorAll(%s));
+			const previous = chips.map(chip => chip.style.display);
+			chips.forEach(chip => { chip.style.display = 'none'; });
+			return () => chips.forEach((chip, i) => { chip.style.display = previous[i]; });
+		}
+	`, selectorJSON)
+}
+
+// stripCitationMarkers removes text-only citation markers left in a response
+func stripCitationMarkers(response string) string {
+	return citationMarker.ReplaceAllString(response, "")
+}
+
+// readSources returns the distinct links cited by the last assistant turn, in order
+func (c *ChatGPT) readSources() []string {
+	selectorJSON, _ := json.Marshal(CitationChip)
+	script := fmt.Sprintf(`
+		(function() {
+			const turns = document.querySelectorAll('%s');
+			const last = turns[turns.length - 1];
+			if (!last) return [];
+			const sources = [];
+			last.querySelectorAll(%s).forEach(chip => {
+				const links = chip.matches('a[href]') ? [chip] : Array.from(chip.querySelectorAll('a[href]'));
+				links.forEach(link => {
+					if (link.href.startsWith('http') && !sources.includes(link.href)) sources.push(link.href);
+				});
+			});
+			return sources;
+		})();
+	`, AssistantMessage, selectorJSON)
+
+	var sources []string
+	if err := chromedp.Run(c.ctx, chromedp.Evaluate(script, &sources)); err != nil {
+		c.debugf("could not read citation sources: %v", err)
+		return nil
+	}
+	return sources
+}
+
+// LastSources returns the links cited by the most recent response when
+// chatgpt.citations.collect_sources is on
+func (c *ChatGPT) LastSources() []string {
+	return c.lastSources
+}
+
+// FormatSources renders cited links as a numbered "Sources:" footer
+func FormatSources(sources []string) string {
+	var footer strings.Builder
+	footer.WriteString("Sources:")
+	for i, source := range sources {
+		footer.WriteString(fmt.Sprintf("\n  [%d] %s", i+1, source))
+	}
+	return footer.String()
+}
@@ -2,33 +2,254 @@ package chatgpt
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
+	"github.com/chatgpt-element-recorder/pkg/browser"
+	"github.com/chatgpt-element-recorder/pkg/config"
+	"github.com/chatgpt-element-recorder/pkg/errs"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/target"
 	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/kb"
 )
 
 // ChatGPT represents a ChatGPT session
 type ChatGPT struct {
-	ctx    context.Context
-	cancel context.CancelFunc
+	ctx            context.Context
+	ctxMu          sync.RWMutex // guards ctx for goroutines that outlive a call
+	cancel         context.CancelFunc
+	config         *config.DynamicConfig
+	headless       bool
+	activeChat     ChatHistoryItem
+	sentInChat     int // messages sent through this client since the chat was opened
+	chatChars      int // characters exchanged in the active chat, for size estimates
+	lastTiming     Timing
+	models         []string                      // model names scraped from the picker, nil until first scrape
+	tabs           map[target.ID]context.Context // contexts attached to other tabs, reused on switch
+	usage          Usage
+	timeout        time.Duration   // limit for a whole response; 0 means no limit
+	waitTime       time.Duration   // limit for page and element waits; 0 means no limit
+	quiet          map[string]bool // normalized setup prompts sent with SendQuiet
+	lastSources    []string        // links cited by the last response, with citations.collect_sources
+	sending        int32           // non-zero while SendMessage runs, so the keepalive stays out of the way
+	activeAt       int64           // UnixNano of the last send, read by the keepalive
+	cookiesSavedAt int64           // UnixNano of the last background cookie save
+	streamErr      error           // error that ended the last SendMessageStream
+	streamed       string          // final page text of the last SendMessageStream answer
+	conversation   []TrackedTurn   // messages exchanged in the active chat through this client
+	chatSwitches   int             // times the client has moved to another chat
 }
 
 // NewChatGPT creates a new ChatGPT session
 func NewChatGPT(ctx context.Context) *ChatGPT {
+	// LoadDynamicConfig always returns usable defaults, even on error
+	cfg, _ := config.LoadDynamicConfig()
 	return &ChatGPT{
-		ctx: ctx,
+		ctx:      ctx,
+		config:   cfg,
+		usage:    Usage{Started: time.Now()},
+		timeout:  time.Duration(cfg.ChatGPT.Timeout) * time.Second,
+		waitTime: time.Duration(cfg.ChatGPT.WaitTimeout) * time.Second,
 	}
 }
 
-// SendMessage sends a message to ChatGPT and returns the response
+// SetTimeout overrides the configured response and wait timeouts for this
+// session. Zero disables both.
+func (c *ChatGPT) SetTimeout(seconds int) {
+	c.timeout = time.Duration(seconds) * time.Second
+	c.waitTime = c.timeout
+}
+
+// withTimeout bounds ctx by d, or only makes it cancellable when d is zero
+func withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// responseTimeout reports err as ErrResponseTimeout when it ended an answer
+// wait because waitCtx, bounded by chatgpt.timeout, ran out
+func responseTimeout(waitCtx context.Context, err error) error {
+	if err != nil && errors.Is(waitCtx.Err(), context.DeadlineExceeded) {
+		return &errs.BrowserError{Op: "wait for response", Kind: errs.ErrResponseTimeout, Err: err}
+	}
+	return err
+}
+
+// SetHeadless records whether the browser was launched in headless mode
+func (c *ChatGPT) SetHeadless(headless bool) {
+	c.headless = headless
+}
+
+// IsHeadless reports whether the browser has no visible window
+func (c *ChatGPT) IsHeadless() bool {
+	return c.headless
+}
+
+// defaultRetryBackoff is the first retry wait when chatgpt.retry_backoff_ms is unset
+const defaultRetryBackoff = time.Second
+
+// SendMessage sends a message to ChatGPT and returns the response. Transient
+// failures are retried up to chatgpt.retry_attempts times, waiting
+// chatgpt.retry_backoff_ms before the first retry and twice as long before
+// each further one. Only a failed submit sends the message again: once it is
+// on the page, a retry reads the answer again, after clicking Regenerate if
+// ChatGPT showed its "Something went wrong" error. When every attempt fails
+// the result is a *SendError.
 func (c *ChatGPT) SendMessage(message string) (string, error) {
 	// Removed log message to avoid duplicate with CLI spinner
+	atomic.AddInt32(&c.sending, 1)
+	defer func() {
+		c.markActivity()
+		atomic.AddInt32(&c.sending, -1)
+	}()
+
+	retries := c.config.ChatGPT.RetryAttempts
+	backoff := time.Duration(c.config.ChatGPT.RetryBackoffMs) * time.Millisecond
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+
+	submitted := false
+	var initialToastCount, initialMessageCount int
+	var sentAt time.Time
+	for attempt := 0; ; attempt++ {
+		var response string
+		var err error
+		if !submitted {
+			initialToastCount, initialMessageCount, err = c.submitMessage(message)
+			submitted, sentAt = err == nil, time.Now()
+		}
+		if submitted && err == nil {
+			response, err = c.awaitAnswer(message, sentAt, initialToastCount, initialMessageCount)
+		}
+		if err == nil {
+			c.saveCookiesInBackground()
+			return response, nil
+		}
+		if !isTransientSendError(err) || attempt >= retries {
+			if attempt == 0 {
+				return "", err
+			}
+			return "", &SendError{Attempts: attempt + 1, LastErr: err}
+		}
+
+		wait := backoff << attempt
+		c.debugf("send failed (%v), retry %d/%d in %s", err, attempt+1, retries, wait)
+		time.Sleep(wait)
+
+		var toastErr *ToastError
+		if submitted && errors.As(err, &toastErr) {
+			if initialToastCount, err = c.regenerate(); err != nil {
+				return "", &SendError{Attempts: attempt + 1, LastErr: err}
+			}
+		}
+	}
+}
+
+// regenerateStartWait bounds the wait for a regenerated answer to start
+const regenerateStartWait = 5 * time.Second
+
+// regenerate clicks Regenerate to ask for the answer to the message already
+// on the page again, and returns the error notice count to wait past
+func (c *ChatGPT) regenerate() (toastCount int, err error) {
+	toastCountScript := fmt.Sprintf(`document.querySelectorAll('%s').length`, errorNotices)
+	if err := chromedp.Run(c.ctx, chromedp.Evaluate(toastCountScript, &toastCount)); err != nil {
+		return 0, errs.Wrap("regenerate", err)
+	}
+	if err := chromedp.Run(c.ctx, chromedp.Click(RegenerateButton, chromedp.ByQuery)); err != nil {
+		return 0, errs.Wrap("regenerate", err)
+	}
+
+	// The new answer replaces the failed one, so the turn count can't tell
+	// when it starts; wait for the stop button instead
+	startCtx, cancel := context.WithTimeout(c.ctx, regenerateStartWait)
+	defer cancel()
+	_ = chromedp.Run(startCtx, chromedp.WaitVisible(StopButton, chromedp.ByQuery))
+	return toastCount, nil
+}
+
+// awaitAnswer waits for and reads the answer to a message submitted at
+// sentAt. It can run again for the same message without sending it.
+func (c *ChatGPT) awaitAnswer(message string, sentAt time.Time, initialToastCount, initialMessageCount int) (string, error) {
+	c.lastTiming = Timing{}
+
+	// 3. Poll for the answer, bounded by the response timeout
+	waitCtx, cancel := withTimeout(c.ctx, c.timeout)
+	defer cancel()
+
+	// Wait for the first text of the answer, then for the answer to complete
+	if err := c.waitForResponseState(waitCtx, initialToastCount, initialMessageCount, false); err != nil {
+		return "", responseTimeout(waitCtx, err)
+	}
+	c.lastTiming.FirstToken = time.Since(sentAt)
+
+	if err := c.waitForResponseState(waitCtx, initialToastCount, initialMessageCount, true); err != nil {
+		return "", responseTimeout(waitCtx, err)
+	}
+	c.lastTiming.Complete = time.Since(sentAt)
+
+	// Response complete - removed log to avoid interference with CLI
+	time.Sleep(300 * time.Millisecond) // A final small delay for stability
+
+	// 4. Get the content of the answer: the markdown source if configured,
+	// then the JS extractor if configured, then the selectors
+	var response string
+	if c.config.ChatGPT.ResponseFormat == formatMarkdown {
+		response = c.cleanResponse(c.readMarkdownSource())
+		if response == "" {
+			c.debugf("no markdown source captured, falling back to rendered text")
+		}
+	}
+	if response == "" && c.config.ChatGPT.ScrapeStrategy == scrapeJS {
+		response = c.cleanResponse(c.extractWithJS())
+		if response == "" {
+			c.debugf("JS extractor returned nothing, falling back to selectors")
+		}
+	}
+	if response == "" {
+		var err error
+		if response, err = c.readLastTurn(initialMessageCount); err != nil {
+			return "", err
+		}
+	}
+	c.lastSources = nil
+	if c.config.ChatGPT.Citations.CollectSources {
+		c.lastSources = c.readSources()
+	}
+	c.chatChars += utf8.RuneCountInString(message) + utf8.RuneCountInString(response)
+	c.usage.ResponseChars += utf8.RuneCountInString(response)
+	c.track(message, sentAt, response)
+	return response, nil
+}
+
+// submitMessage types and sends a message once the page is ready. It returns
+// the error toast and assistant turn counts from before the send, which mark
+// where the answer to this message starts.
+func (c *ChatGPT) submitMessage(message string) (initialToastCount, initialMessageCount int, err error) {
+	// The login wall may have no prompt box at all, or a logged-out one
+	if err := c.ensureReady(c.ctx); err != nil {
+		if loginErr := c.checkLoggedIn("send message"); loginErr != nil {
+			return 0, 0, loginErr
+		}
+		return 0, 0, err
+	}
+	if err := c.checkLoggedIn("send message"); err != nil {
+		return 0, 0, err
+	}
 
 	// 1. Count existing assistant messages before sending a new one.
-	var initialMessageCount int
 	countScript := fmt.Sprintf(`document.querySelectorAll('%s').length`, AssistantMessage)
 	if err := chromedp.Run(c.ctx, chromedp.Evaluate(countScript, &initialMessageCount)); err != nil {
 		initialMessageCount = 0
@@ -37,126 +258,399 @@ func (c *ChatGPT) SendMessage(message string) (string, error) {
 		//log.Printf("   - Initial assistant message count: %d", initialMessageCount)
 	}
 
+	// Toasts already on screen belong to an earlier request, so only newer ones count
+	toastCountScript := fmt.Sprintf(`document.querySelectorAll('%s').length`, errorNotices)
+	if err := chromedp.Run(c.ctx, chromedp.Evaluate(toastCountScript, &initialToastCount)); err != nil {
+		initialToastCount = 0
+	}
+
 	// 2. Send the message.
-	err := chromedp.Run(c.ctx,
-		chromedp.WaitVisible(InputElement, chromedp.ByQuery),
-		chromedp.SendKeys(InputElement, message, chromedp.ByQuery),
+	err = chromedp.Run(c.ctx,
+		typeMessage(message),
 		chromedp.WaitEnabled(SubmitButton, chromedp.ByQuery),
 		chromedp.Click(SubmitButton, chromedp.ByQuery),
 	)
 	if err != nil {
-		return "", fmt.Errorf("failed to send message: %v", err)
+		return 0, 0, errs.Wrap("send message", err)
 	}
+	c.sentInChat++
+	c.usage.Messages++
+	c.usage.PromptChars += utf8.RuneCountInString(message)
+	c.usage.SentAt = append(c.usage.SentAt, time.Now())
 
-	// Removed log message to avoid interference with CLI spinner
+	return initialToastCount, initialMessageCount, nil
+}
 
-	// 3. New robust polling logic with longer timeout for long responses.
-	waitCtx, cancel := context.WithTimeout(c.ctx, 300*time.Second) // Increased to 5 minutes
-	defer cancel()
+// Usage returns the session's message and character counts
+func (c *ChatGPT) Usage() Usage {
+	return c.usage
+}
+
+// EstimateTokens roughly estimates the token count of text (about 4 characters per token)
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
 
+// EstimatedContextTokens estimates how many tokens the active chat holds,
+// counting only messages exchanged through this client
+func (c *ChatGPT) EstimatedContextTokens() int {
+	return (c.chatChars + 3) / 4
+}
+
+// errorNotices matches both error toasts and the in-chat error banner
+const errorNotices = ErrorToast + ", " + ErrorBanner
+
+// waitForResponseState polls until a new assistant turn has started (or, with
+// complete set, has finished). It returns a ToastError when ChatGPT shows an
+// error toast or banner instead of answering.
+func (c *ChatGPT) waitForResponseState(ctx context.Context, initialToastCount, initialMessageCount int, complete bool) error {
 	pollScript := fmt.Sprintf(`
 		(() => {
+			const toasts = document.querySelectorAll('%s');
+			if (toasts.length > %d) {
+				const text = (toasts[toasts.length - 1].innerText || '').trim();
+				if (text) return 'toast:' + text;
+			}
 			const assistantMessages = document.querySelectorAll('%s');
-			const stopButton = document.querySelector('%s');
-			return assistantMessages.length > %d && !stopButton;
+			if (assistantMessages.length <= %d) return '';
+			if (%t) {
+				return document.querySelector('%s') ? '' : 'done';
+			}
+			const last = assistantMessages[assistantMessages.length - 1];
+			return (last.innerText || '').trim() ? 'started' : '';
 		})()
-	`, AssistantMessage, StopButton, initialMessageCount)
-
-	if err := chromedp.Run(waitCtx, chromedp.Poll(pollScript, nil)); err != nil {
-		return "", fmt.Errorf("timed out waiting for response to complete: %v", err)
-	}
-
-	// Response complete - removed log to avoid interference with CLI
-	time.Sleep(300 * time.Millisecond) // A final small delay for stability
-
-	// 4. Get the content of the last message.
-	var response string
-	script := fmt.Sprintf(`
-        (function() {
-            const elements = document.querySelectorAll('%s');
-            if (elements.length === 0) return '';
-            const lastElement = elements[elements.length - 1];
-            return lastElement ? lastElement.innerText : '';
-        })();
-    `, LastResponse)
+	`, errorNotices, initialToastCount, AssistantMessage, initialMessageCount, complete, StopButton)
 
-	err = chromedp.Run(c.ctx, chromedp.Evaluate(script, &response))
-	if err != nil {
-		return "", fmt.Errorf("failed to get response text: %v", err)
+	var pollResult string
+	if err := chromedp.Run(ctx, chromedp.Poll(pollScript, &pollResult)); err != nil {
+		return errs.Wrap("wait for response", err)
 	}
-	if response == "" {
-		return "", fmt.Errorf("received empty response from assistant")
+	if strings.HasPrefix(pollResult, "toast:") {
+		return &ToastError{Message: strings.TrimPrefix(pollResult, "toast:")}
 	}
-	return strings.TrimSpace(response), nil
+	return nil
+}
+
+// LastTiming returns the latency measurements of the most recent SendMessage
+func (c *ChatGPT) LastTiming() Timing {
+	return c.lastTiming
 }
 
 // StartNewChat starts a new chat session
 func (c *ChatGPT) StartNewChat() error {
 	log.Println("🆕 Starting new chat...")
-	err := chromedp.Run(c.ctx,
-		chromedp.Click(NewChatButton, chromedp.ByQuery),
-		chromedp.WaitVisible(InputElement, chromedp.ByQuery),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to start new chat: %v", err)
+	if err := c.ensureReady(c.ctx); err != nil {
+		return err
 	}
+	if err := chromedp.Run(c.ctx, chromedp.Click(NewChatButton, chromedp.ByQuery)); err != nil {
+		return errs.Wrap("start new chat", err)
+	}
+	if err := c.ensureReady(c.ctx); err != nil {
+		return err
+	}
+	c.resetChat(ChatHistoryItem{})
 	log.Println("✅ New chat started")
 	return nil
 }
 
-// GetChatHistory gets the list of chat history
-func (c *ChatGPT) GetChatHistory() ([]ChatHistoryItem, error) {
+// GetChatHistory returns one page of the sidebar's chats, newest first: page
+// 1 is the first pageSize chats. The sidebar is scrolled only as far as the
+// page needs, up to history.scroll_steps steps per page (0 for no limit), and
+// a page past the last chat is empty.
+func (c *ChatGPT) GetChatHistory(page, pageSize int) ([]ChatHistoryItem, error) {
+	if page < 1 || pageSize < 1 {
+		return nil, fmt.Errorf("invalid history page %d of size %d", page, pageSize)
+	}
+
 	log.Println("📜 Getting chat history...")
+	steps := -1
+	if c.config.History.ScrollSteps > 0 {
+		steps = c.config.History.ScrollSteps * page
+	}
+	rawItems, err := c.scrapeHistoryLinks(steps, page*pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	start := (page - 1) * pageSize
+	if start >= len(rawItems) {
+		return nil, nil
+	}
 	var historyItems []ChatHistoryItem
-	script := fmt.Sprintf(`
+	for _, item := range rawItems[start:min(start+pageSize, len(rawItems))] {
+		historyItems = append(historyItems, ChatHistoryItem{
+			Title: item.Title,
+			URL:   item.URL,
+			ID:    extractChatID(item.URL),
+		})
+	}
+	log.Printf("📜 Found %d chat history items", len(historyItems))
+	return historyItems, nil
+}
+
+// fullHistorySteps bounds how far GetFullChatHistory scrolls when no item
+// limit is given
+const fullHistorySteps = 500
+
+// GetFullChatHistory scrolls the sidebar until no new chats appear and returns
+// every chat found, at most maxItems of them when maxItems is positive
+func (c *ChatGPT) GetFullChatHistory(maxItems int) ([]ChatHistoryItem, error) {
+	log.Println("📜 Getting full chat history...")
+	rawItems, err := c.scrapeHistoryLinks(-1, maxItems)
+	if err != nil {
+		return nil, err
+	}
+
+	historyItems := make([]ChatHistoryItem, 0, len(rawItems))
+	for _, item := range rawItems {
+		historyItems = append(historyItems, ChatHistoryItem{
+			Title: item.Title,
+			URL:   item.URL,
+			ID:    extractChatID(item.URL),
+		})
+	}
+	log.Printf("📜 Found %d chat history items", len(historyItems))
+	return historyItems, nil
+}
+
+// historyLink is a raw sidebar link as returned by the scrape script
+type historyLink struct {
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+// scrapeHistoryLinks reads sidebar links while scrolling through the list in
+// small steps, because the sidebar virtualizes rows and only renders titles
+// for entries that are on screen. It scrolls the given number of steps, or
+// with steps below zero until two steps in a row find nothing new, and stops
+// early once maxItems links are found if maxItems is positive.
+func (c *ChatGPT) scrapeHistoryLinks(steps, maxItems int) ([]historyLink, error) {
+	collectScript := fmt.Sprintf(`
         (function() {
             const links = document.querySelectorAll('%s');
             const items = [];
             links.forEach(link => {
-                if (link.href && link.innerText) {
-                    items.push({ url: link.href, title: link.innerText.trim() });
+                if (link.href) {
+                    items.push({ url: link.href, title: (link.innerText || '').trim() });
                 }
             });
             return items;
         })();
     `, HistoryLink)
-	var rawItems []struct {
-		URL   string `json:"url"`
-		Title string `json:"title"`
+
+	// Scroll the nearest scrollable ancestor of the history list
+	scrollScript := func(position string) string {
+		return fmt.Sprintf(`
+            (function() {
+                let el = document.querySelector('%s');
+                while (el && el.scrollHeight <= el.clientHeight) el = el.parentElement;
+                if (!el) return false;
+                el.scrollTo({ top: %s, behavior: 'smooth' });
+                return true;
+            })();
+        `, HistoryLink, position)
 	}
-	err := chromedp.Run(c.ctx, chromedp.Evaluate(script, &rawItems))
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute script to get history: %v", err)
+
+	untilStable := steps < 0
+	if untilStable {
+		steps = fullHistorySteps
 	}
-	for i, item := range rawItems {
-		if i >= 10 {
+	delay := time.Duration(c.config.History.ScrollDelay) * time.Millisecond
+
+	var ordered []historyLink
+	seen := make(map[string]int)
+	idle := 0
+	for step := 0; step <= steps; step++ {
+		var batch []historyLink
+		if err := chromedp.Run(c.ctx, chromedp.Evaluate(collectScript, &batch)); err != nil {
+			return nil, errs.Wrap("get history", err)
+		}
+
+		// Keep first-seen order but fill in titles that render on later steps
+		found := len(ordered)
+		for _, item := range batch {
+			if idx, ok := seen[item.URL]; ok {
+				if ordered[idx].Title == "" {
+					ordered[idx].Title = item.Title
+				}
+				continue
+			}
+			seen[item.URL] = len(ordered)
+			ordered = append(ordered, item)
+		}
+
+		if maxItems > 0 && len(ordered) >= maxItems {
 			break
 		}
-		historyItems = append(historyItems, ChatHistoryItem{
-			Title: item.Title,
-			URL:   item.URL,
-			ID:    extractChatID(item.URL),
-		})
+		// Older chats load as the list nears its end, so one quiet step may
+		// just be a slow load
+		if untilStable {
+			if len(ordered) == found {
+				idle++
+			} else {
+				idle = 0
+			}
+			if idle >= 2 {
+				break
+			}
+		}
+		if step == steps {
+			break
+		}
+		var scrolled bool
+		if err := chromedp.Run(c.ctx, chromedp.Evaluate(scrollScript("el.scrollTop + el.clientHeight / 2"), &scrolled)); err != nil || !scrolled {
+			break
+		}
+		time.Sleep(delay)
 	}
-	log.Printf("📜 Found %d chat history items", len(historyItems))
-	return historyItems, nil
+
+	if steps > 0 {
+		chromedp.Run(c.ctx, chromedp.Evaluate(scrollScript("0"), nil))
+	}
+
+	var items []historyLink
+	for _, item := range ordered {
+		if item.Title != "" {
+			items = append(items, item)
+		}
+	}
+	if maxItems > 0 && len(items) > maxItems {
+		items = items[:maxItems]
+	}
+	return items, nil
+}
+
+// resetChat forgets the per-chat state when the client moves to another
+// chat, which active describes
+func (c *ChatGPT) resetChat(active ChatHistoryItem) {
+	c.activeChat = active
+	c.sentInChat = 0
+	c.chatChars = 0
+	c.conversation = nil
+	c.chatSwitches++
+}
+
+// ChatSwitches counts the times the client has moved to another chat, by
+// starting, opening or restoring one or by switching tabs
+func (c *ChatGPT) ChatSwitches() int {
+	return c.chatSwitches
 }
 
 // OpenChat opens a specific chat by ID
 func (c *ChatGPT) OpenChat(chatID string) error {
 	log.Printf("📂 Opening chat: %s", chatID)
-	url := fmt.Sprintf("https://chatgpt.com/c/%s", chatID)
-	err := chromedp.Run(c.ctx,
-		chromedp.Navigate(url),
-		chromedp.WaitVisible(InputElement, chromedp.ByQuery),
-	)
-	if err != nil {
-		return fmt.Errorf("failed to open chat: %v", err)
+	url := c.chatURL(chatID)
+	if err := chromedp.Run(c.ctx, chromedp.Navigate(url)); err != nil {
+		return errs.Wrap("open chat", err)
+	}
+	if err := c.ensureReady(c.ctx); err != nil {
+		return err
 	}
+	c.resetChat(ChatHistoryItem{ID: chatID, URL: url})
 	log.Println("✅ Chat opened")
 	return nil
 }
 
+// ReadConversation scrapes every turn of a chat in order. The chat is loaded
+// in a temporary tab so the chat used for sending stays untouched.
+func (c *ChatGPT) ReadConversation(chatID string) ([]Turn, error) {
+	tabCtx, closeTab := chromedp.NewContext(c.ctx)
+	defer closeTab()
+	if err := c.attachTab(tabCtx); err != nil {
+		return nil, err
+	}
+
+	waitCtx, cancel := withTimeout(tabCtx, c.waitTime)
+	defer cancel()
+
+	var turns []Turn
+	err := chromedp.Run(waitCtx,
+		chromedp.Navigate(c.chatURL(chatID)),
+		chromedp.WaitVisible(ConversationTurn, chromedp.ByQuery),
+		chromedp.Sleep(500*time.Millisecond), // let the remaining turns render
+		chromedp.Evaluate(c.turnsScript(), &turns),
+	)
+	if err != nil {
+		return nil, errs.Wrap("read conversation", err)
+	}
+	return c.cleanTurns(turns), nil
+}
+
+// CurrentConversation scrapes every turn of the chat open in the bound tab
+func (c *ChatGPT) CurrentConversation() ([]Turn, error) {
+	var turns []Turn
+	if err := chromedp.Run(c.ctx, chromedp.Evaluate(c.turnsScript(), &turns)); err != nil {
+		return nil, errs.Wrap("read conversation", err)
+	}
+	return c.cleanTurns(turns), nil
+}
+
+// turnsScript returns JS that lists the page's turns as {role, text} in order
+func (c *ChatGPT) turnsScript() string {
+	return fmt.Sprintf(`
+		(function() {
+			%s
+			%s
+			const nodes = document.querySelectorAll('%s');
+			return Array.from(nodes).map(node => {
+				const role = node.getAttribute('data-message-author-role');
+				const content = role === 'assistant' ? (node.querySelector('%s') || node) : node;
+				const showCitations = role === 'assistant' && %t ? hideCitations(content) : () => {};
+				const showReasoning = role === 'assistant' && %t ? hideReasoning(node) : () => {};
+				const text = content.innerText || '';
+				showReasoning();
+				showCitations();
+				return { role: role, text: text };
+			});
+		})();
+	`, hideCitationsJS(), hideReasoningJS(), ConversationTurn, ResponseContent, c.config.ChatGPT.Citations.Strip, c.config.Agent.HideReasoning)
+}
+
+// cleanTurns applies the response filters to assistant turns and trims the
+// rest. With ui.omit_system_prompt set, setup prompts sent with SendQuiet are
+// dropped together with the reply that follows them.
+func (c *ChatGPT) cleanTurns(turns []Turn) []Turn {
+	omit := c.config.UI.OmitSystemPrompt && len(c.quiet) > 0
+	kept := turns[:0]
+	for i := 0; i < len(turns); i++ {
+		turn := turns[i]
+		if omit && turn.Role == "user" && c.quiet[normalizeSpace(turn.Text)] {
+			if i+1 < len(turns) && turns[i+1].Role == "assistant" {
+				i++
+			}
+			continue
+		}
+		if turn.Role == "assistant" {
+			turn.Text = c.cleanResponse(turn.Text)
+		} else {
+			turn.Text = strings.TrimSpace(turn.Text)
+		}
+		kept = append(kept, turn)
+	}
+	return kept
+}
+
+// SendQuiet sends a setup message, such as the project system prompt, and
+// returns the reply. The message is remembered so transcripts can leave the
+// exchange out.
+func (c *ChatGPT) SendQuiet(message string) (string, error) {
+	if c.quiet == nil {
+		c.quiet = make(map[string]bool)
+	}
+	c.quiet[normalizeSpace(message)] = true
+	return c.SendMessage(message)
+}
+
+// normalizeSpace collapses whitespace so typed and scraped text compare equal
+func normalizeSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// chatURL returns the URL of a chat by ID
+func (c *ChatGPT) chatURL(chatID string) string {
+	return fmt.Sprintf("%s/c/%s", strings.TrimRight(c.config.ChatGPT.BaseURL, "/"), chatID)
+}
+
 // WaitForPageLoad waits for ChatGPT to be ready
 func (c *ChatGPT) WaitForPageLoad() error {
 	// Wait for page to load silently for clean UI
@@ -164,9 +658,278 @@ func (c *ChatGPT) WaitForPageLoad() error {
 		chromedp.WaitVisible(InputElement, chromedp.ByQuery),
 	)
 	if err != nil {
-		return fmt.Errorf("ChatGPT page did not load correctly: %v", err)
+		return errs.Wrap("wait for page load", err)
 	}
-	return err
+	return nil
+}
+
+// ActiveChat returns the chat currently open in the browser
+func (c *ChatGPT) ActiveChat() (ChatHistoryItem, error) {
+	var location string
+	if err := chromedp.Run(c.ctx, chromedp.Location(&location)); err != nil {
+		return ChatHistoryItem{}, errs.Wrap("read current chat", err)
+	}
+	if !strings.Contains(location, "/c/") {
+		return ChatHistoryItem{}, errs.New("read current chat", errs.ErrNoActiveChat)
+	}
+
+	chatID := extractChatID(location)
+	if chatID != c.activeChat.ID {
+		c.activeChat = ChatHistoryItem{ID: chatID, URL: location}
+	}
+
+	// The highlighted sidebar entry carries the title ChatGPT generated
+	if c.activeChat.Title == "" {
+		if title, err := c.sidebarTitle(chatID); err == nil {
+			c.activeChat.Title = title
+		}
+	}
+
+	return c.activeChat, nil
+}
+
+// GetChatInfo returns metadata about the chat that is currently open
+func (c *ChatGPT) GetChatInfo() (ChatInfo, error) {
+	active, err := c.ActiveChat()
+	if err != nil {
+		return ChatInfo{}, err
+	}
+
+	info := ChatInfo{
+		ChatHistoryItem: active,
+		SentByUs:        c.sentInChat,
+	}
+
+	script := fmt.Sprintf(`
+		(function() {
+			const model = document.querySelector('%s');
+			return {
+				model: model ? model.innerText.trim() : '',
+				turns: document.querySelectorAll('%s').length,
+			};
+		})();
+	`, ModelSwitcher, ConversationTurn)
+
+	var scraped struct {
+		Model string `json:"model"`
+		Turns int    `json:"turns"`
+	}
+	if err := chromedp.Run(c.ctx, chromedp.Evaluate(script, &scraped)); err == nil {
+		info.Model = scraped.Model
+		info.Turns = scraped.Turns
+	}
+	return info, nil
+}
+
+// chatIDPattern matches the characters chat IDs are made of, so an ID can be
+// put into a selector
+var chatIDPattern = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// chatLinkSelector returns the selector of a chat's sidebar link. The ID
+// must have passed chatIDPattern.
+func chatLinkSelector(chatID string) string {
+	return fmt.Sprintf(`a[href="/c/%s"]`, chatID)
+}
+
+// chatLinkJS returns chatLinkSelector as a JS string literal
+func chatLinkJS(chatID string) string {
+	quoted, _ := json.Marshal(chatLinkSelector(chatID))
+	return string(quoted)
+}
+
+// sidebarTitle reads a chat's title from its sidebar entry; it is empty when
+// the chat is not listed
+func (c *ChatGPT) sidebarTitle(chatID string) (string, error) {
+	if !chatIDPattern.MatchString(chatID) {
+		return "", errs.New("read chat title", errs.ErrInvalidChatID)
+	}
+	var title string
+	script := fmt.Sprintf(`
+		(function() {
+			const link = document.querySelector(%s);
+			return link ? link.innerText.trim() : '';
+		})();
+	`, chatLinkJS(chatID))
+	if err := chromedp.Run(c.ctx, chromedp.Evaluate(script, &title)); err != nil {
+		return "", errs.Wrap("read chat title", err)
+	}
+	return title, nil
+}
+
+// RenameActiveChat renames the chat that is currently open and returns the
+// title the sidebar shows afterwards
+func (c *ChatGPT) RenameActiveChat(title string) (string, error) {
+	active, err := c.ActiveChat()
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.RenameChat(active.ID, title); err != nil {
+		return "", err
+	}
+
+	c.activeChat.Title = title
+	if shown, err := c.sidebarTitle(active.ID); err == nil && shown != "" {
+		c.activeChat.Title = shown
+	}
+	return c.activeChat.Title, nil
+}
+
+// chatMenuTimeout bounds each step of a sidebar chat menu. The menu, its
+// dialogs and the title field appear at once when they appear at all, so a
+// short wait is enough to tell that the option isn't there.
+const chatMenuTimeout = 5 * time.Second
+
+// RenameChat drives the sidebar "Rename" menu for the given chat. When the
+// chat has no sidebar entry or the menu doesn't offer a rename, the error
+// is ErrChatMenuUnavailable rather than a wait for the page to change.
+func (c *ChatGPT) RenameChat(chatID, title string) error {
+	op := fmt.Sprintf("rename chat %s", chatID)
+	if err := c.clickChatMenuItem(op, chatID, "rename"); err != nil {
+		return err
+	}
+
+	inputCtx, cancel := context.WithTimeout(c.ctx, chatMenuTimeout)
+	defer cancel()
+	err := chromedp.Run(inputCtx,
+		chromedp.WaitVisible(RenameInput, chromedp.ByQuery),
+		chromedp.Evaluate(fmt.Sprintf(`document.querySelector('%s').select()`, RenameInput), nil),
+		chromedp.SendKeys(RenameInput, title+kb.Enter, chromedp.ByQuery),
+	)
+	if err != nil && errs.Classify(err) == errs.ErrTimeout {
+		return errs.New(op, errs.ErrChatMenuUnavailable)
+	}
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	return nil
+}
+
+// DeleteChat deletes a chat through its sidebar menu and confirms the
+// dialog. Deleting the chat that is open starts a new chat.
+func (c *ChatGPT) DeleteChat(chatID string) error {
+	op := fmt.Sprintf("delete chat %s", chatID)
+	if err := c.clickChatMenuItem(op, chatID, "delete"); err != nil {
+		return err
+	}
+
+	confirmCtx, cancel := context.WithTimeout(c.ctx, chatMenuTimeout)
+	defer cancel()
+	err := chromedp.Run(confirmCtx,
+		chromedp.WaitVisible(DeleteConfirmButton, chromedp.ByQuery),
+		chromedp.Click(DeleteConfirmButton, chromedp.ByQuery),
+		chromedp.WaitNotPresent("nav "+chatLinkSelector(chatID), chromedp.ByQuery),
+	)
+	if err != nil && errs.Classify(err) == errs.ErrTimeout {
+		chromedp.Run(c.ctx, chromedp.KeyEvent(kb.Escape))
+		return errs.New(op, errs.ErrChatMenuUnavailable)
+	}
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+
+	var location string
+	if err := chromedp.Run(c.ctx, chromedp.Location(&location)); err != nil {
+		return errs.Wrap(op, err)
+	}
+	if c.activeChat.ID == chatID || extractChatID(location) == chatID {
+		return c.StartNewChat()
+	}
+	return nil
+}
+
+// clickChatMenuItem opens the sidebar options menu of a chat and clicks the
+// item whose text starts with label. A missing entry, menu or item is
+// ErrChatMenuUnavailable.
+func (c *ChatGPT) clickChatMenuItem(op, chatID, label string) error {
+	// The ID ends up in a selector and a script; DeleteChat's comes from input
+	if !chatIDPattern.MatchString(chatID) {
+		return errs.New(op, errs.ErrInvalidChatID)
+	}
+
+	// Radix menus open on pointerdown, so a plain click() is not enough
+	openMenuScript := fmt.Sprintf(`
+		(function() {
+			const link = document.querySelector(%s);
+			if (!link) return false;
+			const container = link.closest('li') || link.parentElement;
+			const button = link.querySelector('%s') || container.querySelector('%s');
+			if (!button) return false;
+			button.dispatchEvent(new PointerEvent('pointerdown', { bubbles: true }));
+			button.click();
+			return true;
+		})();
+	`, chatLinkJS(chatID), ChatOptions, ChatOptions)
+
+	clickItemScript := fmt.Sprintf(`
+		(function() {
+			const items = Array.from(document.querySelectorAll('%s'));
+			const item = items.find(item => item.innerText.trim().toLowerCase().startsWith('%s'));
+			if (!item) return false;
+			item.click();
+			return true;
+		})();
+	`, MenuItem, label)
+
+	var opened, clicked bool
+	err := chromedp.Run(c.ctx,
+		chromedp.Evaluate(openMenuScript, &opened),
+	)
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	if !opened {
+		return errs.New(op, errs.ErrChatMenuUnavailable)
+	}
+
+	menuCtx, cancel := context.WithTimeout(c.ctx, chatMenuTimeout)
+	defer cancel()
+	err = chromedp.Run(menuCtx,
+		chromedp.WaitVisible(MenuItem, chromedp.ByQuery),
+		chromedp.Evaluate(clickItemScript, &clicked),
+	)
+	if err == nil && !clicked || err != nil && errs.Classify(err) == errs.ErrTimeout {
+		chromedp.Run(c.ctx, chromedp.KeyEvent(kb.Escape))
+		return errs.New(op, errs.ErrChatMenuUnavailable)
+	}
+	if err != nil {
+		return errs.Wrap(op, err)
+	}
+	return nil
+}
+
+// Screenshot saves what the ChatGPT tab shows as a PNG, headless or not, and
+// returns the file written; see browser.SaveScreenshot for where it goes
+func (c *ChatGPT) Screenshot(filename string) (string, error) {
+	path, err := browser.SaveScreenshot(c.ctx, filename)
+	if err != nil {
+		return "", errs.Wrap("screenshot", err)
+	}
+	return path, nil
+}
+
+// FocusWindow brings the Chrome window with the ChatGPT tab to the foreground
+func (c *ChatGPT) FocusWindow() error {
+	if c.headless {
+		return errs.New("focus window", errs.ErrHeadless)
+	}
+
+	err := chromedp.Run(c.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		if err := page.BringToFront().Do(ctx); err != nil {
+			return err
+		}
+
+		// Activating the target also raises the window on some window managers
+		chromeCtx := chromedp.FromContext(ctx)
+		if chromeCtx.Target == nil || chromeCtx.Browser == nil {
+			return nil
+		}
+		return target.ActivateTarget(chromeCtx.Target.TargetID).Do(cdp.WithExecutor(ctx, chromeCtx.Browser))
+	}))
+	if err != nil {
+		return errs.Wrap("focus window", err)
+	}
+	return nil
 }
 
 // extractChatID is a helper function to get the ID from a URL.
@@ -0,0 +1,28 @@
+package chatgpt
+
+import "time"
+
+// track records a completed exchange in the active chat's conversation
+func (c *ChatGPT) track(message string, sentAt time.Time, response string) {
+	c.conversation = append(c.conversation,
+		TrackedTurn{Role: "user", Content: message, Timestamp: sentAt},
+		TrackedTurn{Role: "assistant", Content: response, Timestamp: time.Now()},
+	)
+}
+
+// Conversation returns the messages exchanged through this client since the
+// active chat was opened. With ui.omit_system_prompt set, setup prompts sent
+// with SendQuiet are left out together with their replies.
+func (c *ChatGPT) Conversation() []TrackedTurn {
+	omit := c.config.UI.OmitSystemPrompt && len(c.quiet) > 0
+	turns := make([]TrackedTurn, 0, len(c.conversation))
+	for i := 0; i < len(c.conversation); i++ {
+		turn := c.conversation[i]
+		if omit && turn.Role == "user" && c.quiet[normalizeSpace(turn.Content)] {
+			i++ // and the reply
+			continue
+		}
+		turns = append(turns, turn)
+	}
+	return turns
+}
@@ -0,0 +1,160 @@
+package chatgpt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/chatgpt-element-recorder/pkg/config"
+	"github.com/chatgpt-element-recorder/pkg/errs"
+	cdpbrowser "github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/chromedp"
+)
+
+// SelectorMatch records how many elements a selector matched on the page
+type SelectorMatch struct {
+	Name     string `json:"name"`
+	Selector string `json:"selector"`
+	Count    int    `json:"count"`
+}
+
+// Diagnostics describes the browser and the ChatGPT page for bug reports
+type Diagnostics struct {
+	Product   string          `json:"product"` // e.g. "HeadlessChrome/120.0.6099.71"
+	Protocol  string          `json:"protocol"`
+	UserAgent string          `json:"user_agent"`
+	URL       string          `json:"url"`
+	Selectors []SelectorMatch `json:"selectors"`
+}
+
+// diagSelectors are the built-in selectors checked by Diagnostics, in display order
+var diagSelectors = []struct{ name, selector string }{
+	{"InputElement", InputElement},
+	{"SubmitButton", SubmitButton},
+	{"StopButton", StopButton},
+	{"AssistantMessage", AssistantMessage},
+	{"ResponseContent", ResponseContent},
+	{"ConversationTurn", ConversationTurn},
+	{"NewChatButton", NewChatButton},
+	{"HistoryLink", HistoryLink},
+	{"ModelSwitcher", ModelSwitcher},
+	{"ReasoningBlock", ReasoningBlock},
+	{"CitationChip", CitationChip},
+	{"CopyButton", CopyButton},
+	{"ErrorToast", ErrorToast},
+	{"ErrorBanner", ErrorBanner},
+}
+
+// Diagnostics collects the browser version, current URL and which of the
+// built-in selectors match the page
+func (c *ChatGPT) Diagnostics() (Diagnostics, error) {
+	var diag Diagnostics
+
+	err := chromedp.Run(c.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		chromeCtx := chromedp.FromContext(ctx)
+		if chromeCtx == nil || chromeCtx.Browser == nil {
+			return nil
+		}
+		protocol, product, _, userAgent, _, err := cdpbrowser.GetVersion().Do(cdp.WithExecutor(ctx, chromeCtx.Browser))
+		if err != nil {
+			return err
+		}
+		diag.Protocol, diag.Product, diag.UserAgent = protocol, product, userAgent
+		return nil
+	}), chromedp.Location(&diag.URL))
+	if err != nil {
+		return diag, errs.Wrap("collect diagnostics", err)
+	}
+
+	selectors := make([]string, len(diagSelectors))
+	for i, s := range diagSelectors {
+		selectors[i] = s.selector
+	}
+	selectorsJSON, _ := json.Marshal(selectors)
+	countScript := fmt.Sprintf(`%s.map(sel => { try { return document.querySelectorAll(sel).length; } catch (e) { return -1; } })`, selectorsJSON)
+
+	var counts []int
+	if err := chromedp.Run(c.ctx, chromedp.Evaluate(countScript, &counts)); err != nil {
+		return diag, errs.Wrap("collect diagnostics", err)
+	}
+	for i, s := range diagSelectors {
+		match := SelectorMatch{Name: s.name, Selector: s.selector, Count: -1}
+		if i < len(counts) {
+			match.Count = counts[i]
+		}
+		diag.Selectors = append(diag.Selectors, match)
+	}
+
+	return diag, nil
+}
+
+// SelectorGroupReport is how a role's candidate selectors fare on the page,
+// in the order they are tried
+type SelectorGroupReport struct {
+	Role       string          `json:"role"`
+	Candidates []SelectorMatch `json:"candidates"`
+	Winner     int             `json:"winner"` // index of the first candidate that matched, -1 for none
+}
+
+// SelectorReport runs the candidate selectors of each role (the configured
+// primary and fallbacks from selectors.json, then the built-in selector)
+// against the current page and reports the match counts and which one wins
+func (c *ChatGPT) SelectorReport() ([]SelectorGroupReport, error) {
+	var sel config.Selectors
+	if loaded, err := config.GetSelectors(); err == nil {
+		sel = *loaded
+	}
+
+	roles := []struct {
+		role       string
+		candidates []string
+	}{
+		{"input", inputSelectors()},
+		{"send", append(selectorList(sel.SendButton), SubmitButton)},
+		{"response", append(selectorList(sel.Response), AssistantMessage)},
+		{"new chat", append(nonEmpty(sel.ChatControls["new_chat"]), NewChatButton)},
+		{"history", []string{HistoryLink}},
+		{"model picker", append(selectorList(sel.ModelPicker), ModelSwitcher)},
+	}
+
+	var all []string
+	for _, r := range roles {
+		all = append(all, r.candidates...)
+	}
+	allJSON, _ := json.Marshal(all)
+	countScript := fmt.Sprintf(`%s.map(sel => { try { return document.querySelectorAll(sel).length; } catch (e) { return -1; } })`, allJSON)
+
+	var counts []int
+	if err := chromedp.Run(c.ctx, chromedp.Evaluate(countScript, &counts)); err != nil {
+		return nil, errs.Wrap("check selectors", err)
+	}
+
+	var reports []SelectorGroupReport
+	next := 0
+	for _, r := range roles {
+		report := SelectorGroupReport{Role: r.role, Winner: -1}
+		for i, selector := range r.candidates {
+			match := SelectorMatch{Name: r.role, Selector: selector, Count: -1}
+			if next < len(counts) {
+				match.Count = counts[next]
+			}
+			next++
+			if report.Winner < 0 && match.Count > 0 {
+				report.Winner = i
+			}
+			report.Candidates = append(report.Candidates, match)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// nonEmpty returns selector as a one-element list, or nil when it is blank
+func nonEmpty(selector string) []string {
+	if strings.TrimSpace(selector) == "" {
+		return nil
+	}
+	return []string{selector}
+}
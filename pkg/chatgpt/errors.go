@@ -0,0 +1,123 @@
+package chatgpt
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chatgpt-element-recorder/pkg/errs"
+)
+
+// ErrChatGPTToast is the sentinel wrapped by every ToastError
+var ErrChatGPTToast = errors.New("chatgpt error toast")
+
+// ToastError carries the text of an error toast ChatGPT showed during a send
+type ToastError struct {
+	Message string
+}
+
+// Error implements the error interface
+func (e *ToastError) Error() string {
+	return "ChatGPT reported: " + e.Message
+}
+
+// Unwrap lets callers match toast errors with errors.Is(err, ErrChatGPTToast)
+func (e *ToastError) Unwrap() error {
+	return ErrChatGPTToast
+}
+
+// IsRateLimit reports whether the toast is a "too many requests" style message
+func (e *ToastError) IsRateLimit() bool {
+	lower := strings.ToLower(e.Message)
+	rateLimitPhrases := []string{
+		"too many requests",
+		"rate limit",
+		"you've reached",
+		"slow down",
+	}
+
+	for _, phrase := range rateLimitPhrases {
+		if strings.Contains(lower, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// cooldownPattern finds waits like "try again in 20 seconds" or "in 2 minutes"
+var cooldownPattern = regexp.MustCompile(`(?i)in (\d+)\s*(seconds?|secs?|s|minutes?|mins?|m|hours?|hrs?|h)\b`)
+
+// Cooldown returns how long the toast asks to wait, or 0 if it doesn't say
+func (e *ToastError) Cooldown() time.Duration {
+	match := cooldownPattern.FindStringSubmatch(e.Message)
+	if match == nil {
+		return 0
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0
+	}
+
+	switch unit := strings.ToLower(match[2]); {
+	case strings.HasPrefix(unit, "h"):
+		return time.Duration(n) * time.Hour
+	case strings.HasPrefix(unit, "m"):
+		return time.Duration(n) * time.Minute
+	default:
+		return time.Duration(n) * time.Second
+	}
+}
+
+// IsTransient reports whether the toast is a passing failure, such as
+// "Something went wrong", that sending again may get past
+func (e *ToastError) IsTransient() bool {
+	if e.IsRateLimit() {
+		return false
+	}
+	lower := strings.ToLower(e.Message)
+	transientPhrases := []string{
+		"something went wrong",
+		"network error",
+		"error in message stream",
+		"an error occurred",
+		"error generating a response",
+	}
+
+	for _, phrase := range transientPhrases {
+		if strings.Contains(lower, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// SendError is returned by SendMessage when every attempt failed
+type SendError struct {
+	Attempts int
+	LastErr  error
+}
+
+// Error implements the error interface
+func (e *SendError) Error() string {
+	return fmt.Sprintf("%v (after %d attempts)", e.LastErr, e.Attempts)
+}
+
+// Unwrap exposes the last attempt's error
+func (e *SendError) Unwrap() error {
+	return e.LastErr
+}
+
+// isTransientSendError reports whether a failed send is worth retrying: a
+// transient toast, an empty answer, or a browser action that failed while the
+// page was changing. Rate limits, timeouts, logouts and a closed browser are not.
+func isTransientSendError(err error) bool {
+	var toastErr *ToastError
+	if errors.As(err, &toastErr) {
+		return toastErr.IsTransient()
+	}
+	return errors.Is(err, errs.ErrEmptyResponse) || errors.Is(err, errs.ErrBrowser) ||
+		errors.Is(err, errs.ErrSelectorNotFound)
+}
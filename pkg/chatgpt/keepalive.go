@@ -0,0 +1,65 @@
+package chatgpt
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// keepaliveScript nudges the page the way a returning user would: a focus
+// event and a harmless read. It never types, clicks or navigates.
+const keepaliveScript = `
+	(function() {
+		window.dispatchEvent(new Event('focus'));
+		document.dispatchEvent(new Event('visibilitychange'));
+		return document.visibilityState;
+	})();
+`
+
+// StartKeepalive keeps the session warm while the CLI sits idle by touching
+// the page every interval. Ticks are skipped while a message is being sent or
+// when the page saw activity within the last interval. It follows the client
+// across tab switches and stops when the current tab's context ends.
+func (c *ChatGPT) StartKeepalive(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			// Read on every tick, since /tab may have moved the client to
+			// another tab since the last one
+			ctx := c.currentContext()
+			select {
+			case <-ctx.Done():
+				// A closed tab the client has already left doesn't end it
+				if c.currentContext() == ctx {
+					return
+				}
+			case <-ticker.C:
+				if atomic.LoadInt32(&c.sending) > 0 || time.Since(c.lastActivity()) < interval {
+					continue
+				}
+				var state string
+				if err := chromedp.Run(ctx, chromedp.Evaluate(keepaliveScript, &state)); err != nil {
+					c.debugf("keepalive failed: %v", err)
+					continue
+				}
+				c.debugf("keepalive: page %s", state)
+			}
+		}
+	}()
+}
+
+// lastActivity returns when a message was last sent
+func (c *ChatGPT) lastActivity() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&c.activeAt))
+}
+
+// markActivity records that the page was just used
+func (c *ChatGPT) markActivity() {
+	atomic.StoreInt64(&c.activeAt, time.Now().UnixNano())
+}
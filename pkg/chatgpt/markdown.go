@@ -0,0 +1,68 @@
+package chatgpt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+)
+
+// Response formats for chatgpt.response_format
+const (
+	formatText     = "text"     // the rendered answer's innerText
+	formatMarkdown = "markdown" // the markdown source, as the turn's copy button puts it on the clipboard
+)
+
+// readMarkdownSource returns the markdown source of the last assistant turn.
+// ChatGPT only hands out the source through the turn's copy button, so the
+// clipboard write is intercepted for one click and restored afterwards; the
+// real clipboard is left untouched. It returns "" when there is no copy
+// button or nothing was captured, so the caller keeps the rendered text.
+func (c *ChatGPT) readMarkdownSource() string {
+	selectorJSON, _ := json.Marshal(CopyButton)
+	script := fmt.Sprintf(`
+		(async function() {
+			const turns = document.querySelectorAll('%s');
+			const last = turns[turns.length - 1];
+			if (!last) return '';
+			const turn = last.closest('article, [data-testid^="conversation-turn"]') || last.parentElement;
+			const buttons = turn ? turn.querySelectorAll(%s) : [];
+			const button = buttons[buttons.length - 1];
+			if (!button) return '';
+
+			const clipboard = navigator.clipboard;
+			const original = { writeText: clipboard.writeText, write: clipboard.write };
+			let captured = '';
+			clipboard.writeText = async text => { captured = text; };
+			clipboard.write = async items => {
+				for (const item of items) {
+					if (item.types.includes('text/plain')) {
+						captured = await (await item.getType('text/plain')).text();
+					}
+				}
+			};
+			try {
+				button.click();
+				for (let i = 0; i < 20 && !captured; i++) {
+					await new Promise(resolve => setTimeout(resolve, 50));
+				}
+			} finally {
+				clipboard.writeText = original.writeText;
+				clipboard.write = original.write;
+			}
+			return captured;
+		})();
+	`, AssistantMessage, selectorJSON)
+
+	var source string
+	err := chromedp.Run(c.ctx, chromedp.Evaluate(script, &source, func(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+		return p.WithAwaitPromise(true)
+	}))
+	if err != nil {
+		c.debugf("could not read markdown source: %v", err)
+		return ""
+	}
+	return strings.TrimSpace(source)
+}
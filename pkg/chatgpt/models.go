@@ -1,8 +1,70 @@
 package chatgpt
 
+import "time"
+
 // ChatHistoryItem represents a chat history item returned by the scraper.
 type ChatHistoryItem struct {
 	Title string
 	URL   string
 	ID    string
+	Tags  []string // local /tag labels, filled in when history is displayed
+}
+
+// ChatInfo describes the chat that is currently open.
+type ChatInfo struct {
+	ChatHistoryItem
+	Model    string
+	Turns    int
+	SentByUs int
+}
+
+// Timing holds latency measurements for one SendMessage call.
+type Timing struct {
+	FirstToken time.Duration // from submit until the answer's first text appeared
+	Complete   time.Duration // from submit until generation finished
+}
+
+// Usage is local bookkeeping of what this session has sent and received.
+type Usage struct {
+	Messages      int         // prompts submitted, including ones whose answer failed
+	PromptChars   int         // characters sent
+	ResponseChars int         // characters received
+	Started       time.Time   // when the session started
+	SentAt        []time.Time // submit time of every prompt, for rolling-window counts
+}
+
+// SentSince counts the prompts submitted within the last window.
+func (u Usage) SentSince(window time.Duration) int {
+	cutoff := time.Now().Add(-window)
+	count := 0
+	for _, t := range u.SentAt {
+		if t.After(cutoff) {
+			count++
+		}
+	}
+	return count
+}
+
+// Turn is a single message of a scraped conversation.
+type Turn struct {
+	Role string `json:"role"` // "user" or "assistant"
+	Text string `json:"text"`
+}
+
+// TrackedTurn is a message exchanged through this client, kept in memory for
+// exports. Unlike a scraped Turn it knows when it was sent or received.
+type TrackedTurn struct {
+	Role      string    `json:"role"` // "user" or "assistant"
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// TabInfo describes an open browser tab
+type TabInfo struct {
+	Index     int    `json:"index"` // 1-based, as shown by /tabs
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	URL       string `json:"url"`
+	Active    bool   `json:"active"` // the tab the client is bound to
+	IsChatGPT bool   `json:"is_chatgpt"`
 }
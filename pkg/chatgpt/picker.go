@@ -0,0 +1,198 @@
+package chatgpt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chatgpt-element-recorder/pkg/config"
+	"github.com/chatgpt-element-recorder/pkg/errs"
+	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/kb"
+)
+
+// Models returns the model names offered by the model picker. The list is
+// scraped once and cached; pass refresh to scrape it again.
+func (c *ChatGPT) Models(refresh bool) ([]string, error) {
+	if c.models != nil && !refresh {
+		return c.models, nil
+	}
+
+	models, err := c.scrapeModels()
+	if err != nil {
+		return nil, err
+	}
+	c.models = models
+	return models, nil
+}
+
+// scrapeModels opens the model picker, reads its options and closes it again
+func (c *ChatGPT) scrapeModels() ([]string, error) {
+	_, options := modelSelectors()
+	optionJSON, _ := json.Marshal(options)
+
+	readScript := fmt.Sprintf(`
+		(function() {
+			for (const sel of %s) {
+				const items = document.querySelectorAll(sel);
+				if (items.length === 0) continue;
+				return Array.from(items)
+					.map(item => (item.innerText || '').split('\n')[0].trim())
+					.filter(name => name.length > 0);
+			}
+			return [];
+		})();
+	`, optionJSON)
+
+	if err := c.openModelPicker(); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	err := chromedp.Run(c.ctx,
+		chromedp.Evaluate(readScript, &names),
+		chromedp.KeyEvent(kb.Escape),
+	)
+	if err != nil {
+		return nil, errs.Wrap("read model picker", err)
+	}
+	if len(names) == 0 {
+		return nil, errs.New("read model picker", errs.ErrSelectorNotFound)
+	}
+
+	return dedupe(names), nil
+}
+
+// SetModel switches the model through the model picker. The name matches an
+// option case-insensitively, exactly or as the only option containing it.
+// An unknown name is an error listing the options, and the model is left as is.
+func (c *ChatGPT) SetModel(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("no model name given")
+	}
+
+	_, options := modelSelectors()
+	optionJSON, _ := json.Marshal(options)
+	nameJSON, _ := json.Marshal(strings.ToLower(name))
+
+	selectScript := fmt.Sprintf(`
+		(function() {
+			const wanted = %s;
+			for (const sel of %s) {
+				const items = Array.from(document.querySelectorAll(sel));
+				if (items.length === 0) continue;
+				const names = items.map(item => (item.innerText || '').split('\n')[0].trim());
+				let index = names.findIndex(n => n.toLowerCase() === wanted);
+				if (index < 0) {
+					const partial = names
+						.map((n, i) => n.toLowerCase().includes(wanted) ? i : -1)
+						.filter(i => i >= 0);
+					if (partial.length === 1) index = partial[0];
+				}
+				if (index < 0) return { selected: '', names: names.filter(n => n.length > 0) };
+				items[index].dispatchEvent(new PointerEvent('pointerdown', { bubbles: true }));
+				items[index].click();
+				return { selected: names[index], names: names.filter(n => n.length > 0) };
+			}
+			return { selected: '', names: [] };
+		})();
+	`, nameJSON, optionJSON)
+
+	if err := c.openModelPicker(); err != nil {
+		return err
+	}
+
+	var result struct {
+		Selected string   `json:"selected"`
+		Names    []string `json:"names"`
+	}
+	if err := chromedp.Run(c.ctx, chromedp.Evaluate(selectScript, &result)); err != nil {
+		chromedp.Run(c.ctx, chromedp.KeyEvent(kb.Escape))
+		return errs.Wrap("select model", err)
+	}
+	if len(result.Names) > 0 {
+		c.models = dedupe(result.Names)
+	}
+
+	if result.Selected == "" {
+		chromedp.Run(c.ctx, chromedp.KeyEvent(kb.Escape))
+		if len(result.Names) == 0 {
+			return errs.New("read model picker", errs.ErrSelectorNotFound)
+		}
+		return fmt.Errorf("model %q not found (available: %s)", name, strings.Join(c.models, ", "))
+	}
+	c.debugf("selected model %q", result.Selected)
+	return nil
+}
+
+// openModelPicker opens the model picker menu and waits for it to render
+func (c *ChatGPT) openModelPicker() error {
+	pickers, _ := modelSelectors()
+	pickerJSON, _ := json.Marshal(pickers)
+
+	// Radix menus open on pointerdown, so a plain click() is not enough
+	openScript := fmt.Sprintf(`
+		(function() {
+			for (const sel of %s) {
+				const button = document.querySelector(sel);
+				if (!button) continue;
+				button.dispatchEvent(new PointerEvent('pointerdown', { bubbles: true }));
+				button.click();
+				return true;
+			}
+			return false;
+		})();
+	`, pickerJSON)
+
+	var opened bool
+	if err := chromedp.Run(c.ctx, chromedp.Evaluate(openScript, &opened)); err != nil {
+		return errs.Wrap("open model picker", err)
+	}
+	if !opened {
+		return errs.New("open model picker", errs.ErrSelectorNotFound)
+	}
+	// let the menu render
+	return chromedp.Run(c.ctx, chromedp.Sleep(500*time.Millisecond))
+}
+
+// CachedModels returns the model names from the last picker scrape without
+// opening the picker; it is nil until the picker has been read
+func (c *ChatGPT) CachedModels() []string {
+	return c.models
+}
+
+// modelSelectors returns the picker and option selectors to try in order:
+// the configured primary and fallbacks, then the built-in defaults
+func modelSelectors() (pickers, options []string) {
+	if sel, err := config.GetSelectors(); err == nil {
+		pickers = append(pickers, selectorList(sel.ModelPicker)...)
+		options = append(options, selectorList(sel.ModelOption)...)
+	}
+	pickers = append(pickers, ModelSwitcher)
+	options = append(options, MenuItem)
+	return pickers, options
+}
+
+// selectorList flattens a selector group into primary-first order
+func selectorList(group config.SelectorGroup) []string {
+	var list []string
+	if strings.TrimSpace(group.Primary) != "" {
+		list = append(list, group.Primary)
+	}
+	return append(list, group.Fallback...)
+}
+
+// dedupe removes repeated names while keeping their order
+func dedupe(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	var unique []string
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
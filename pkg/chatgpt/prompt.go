@@ -0,0 +1,202 @@
+package chatgpt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/chatgpt-element-recorder/pkg/config"
+	"github.com/chatgpt-element-recorder/pkg/errs"
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/kb"
+)
+
+// promptInput is the prompt box that matched, and how text has to be entered into it
+type promptInput struct {
+	Selector string `json:"selector"`
+	Editable bool   `json:"editable"` // contenteditable div rather than a <textarea>
+}
+
+// typeMessage types a message into the prompt box. A <textarea> gets real key
+// events; a contenteditable div ignores SendKeys-style input on the newer UI,
+// so its content is set directly and an input event tells the editor about it.
+func typeMessage(message string) chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		prompt, err := findPromptInput(ctx)
+		if err != nil {
+			return err
+		}
+		if prompt.Editable {
+			return setEditableText(ctx, prompt.Selector, message)
+		}
+		return typeIntoTextarea(prompt.Selector, message).Do(ctx)
+	})
+}
+
+// findPromptInput waits for any of the input selectors and reports the first that matches
+func findPromptInput(ctx context.Context) (promptInput, error) {
+	selectors := inputSelectors()
+	selectorsJSON, _ := json.Marshal(selectors)
+
+	detectScript := fmt.Sprintf(`
+		(function() {
+			for (const sel of %s) {
+				const el = document.querySelector(sel);
+				if (!el) continue;
+				const isField = el.tagName === 'TEXTAREA' || el.tagName === 'INPUT';
+				return { selector: sel, editable: !isField && el.isContentEditable };
+			}
+			return { selector: '', editable: false };
+		})();
+	`, selectorsJSON)
+
+	var prompt promptInput
+	err := chromedp.Run(ctx,
+		chromedp.WaitVisible(strings.Join(selectors, ", "), chromedp.ByQuery),
+		chromedp.Evaluate(detectScript, &prompt),
+	)
+	if err != nil {
+		return prompt, errs.Wrap("find prompt input", err)
+	}
+	if prompt.Selector == "" {
+		return prompt, errs.New("find prompt input", errs.ErrSelectorNotFound)
+	}
+	return prompt, nil
+}
+
+// typeIntoTextarea sends key events, typing line breaks as Shift+Enter since
+// a plain Enter submits the prompt
+func typeIntoTextarea(selector, message string) chromedp.Action {
+	actions := []chromedp.Action{
+		chromedp.Focus(selector, chromedp.ByQuery),
+	}
+
+	for i, line := range strings.Split(message, "\n") {
+		if i > 0 {
+			actions = append(actions, chromedp.KeyEvent(kb.Enter, chromedp.KeyModifiers(input.ModifierShift)))
+		}
+		if line != "" {
+			actions = append(actions, chromedp.KeyEvent(line))
+		}
+	}
+
+	return chromedp.Tasks(actions)
+}
+
+// setEditableText replaces a contenteditable's content with one paragraph per
+// line and dispatches an input event so the editor picks up the change
+func setEditableText(ctx context.Context, selector, message string) error {
+	selectorJSON, _ := json.Marshal(selector)
+	linesJSON, _ := json.Marshal(strings.Split(message, "\n"))
+
+	script := fmt.Sprintf(`
+		(function() {
+			const el = document.querySelector(%s);
+			if (!el) return false;
+			el.focus();
+			el.innerHTML = '';
+			for (const line of %s) {
+				const p = document.createElement('p');
+				if (line === '') {
+					p.appendChild(document.createElement('br'));
+				} else {
+					p.textContent = line;
+				}
+				el.appendChild(p);
+			}
+			el.dispatchEvent(new InputEvent('input', { bubbles: true, inputType: 'insertText' }));
+			return true;
+		})();
+	`, selectorJSON, linesJSON)
+
+	var ok bool
+	if err := chromedp.Run(ctx, chromedp.Evaluate(script, &ok)); err != nil {
+		return errs.Wrap("type message", err)
+	}
+	if !ok {
+		return errs.New("type message", errs.ErrSelectorNotFound)
+	}
+	return nil
+}
+
+// ensureReady waits, bounded by the wait timeout, for the prompt input to be
+// visible so actions right after a navigation don't race the page. If the page
+// doesn't settle it is reloaded once and given another wait.
+func (c *ChatGPT) ensureReady(ctx context.Context) error {
+	ready := strings.Join(inputSelectors(), ", ")
+
+	wait := func() error {
+		waitCtx, cancel := withTimeout(ctx, c.waitTime)
+		defer cancel()
+		return chromedp.Run(waitCtx, chromedp.WaitVisible(ready, chromedp.ByQuery))
+	}
+
+	err := wait()
+	if err == nil {
+		return nil
+	}
+	c.debugf("page not ready (%v), reloading once", err)
+	if err := chromedp.Run(ctx, chromedp.Reload()); err != nil {
+		return errs.Wrap("wait for page ready", err)
+	}
+	if err := wait(); err != nil {
+		return errs.Wrap("wait for page ready", err)
+	}
+	return nil
+}
+
+// InputSelector returns a selector list matching the prompt box in any of the
+// configured ways, for waiting on the page from outside the client
+func InputSelector() string {
+	return strings.Join(inputSelectors(), ", ")
+}
+
+// inputSelectors returns the prompt selectors to try in order: the configured
+// primary and fallbacks, the built-in default, and last the per-locale
+// placeholder matches, since placeholder text changes with the UI language
+func inputSelectors() []string {
+	var selectors []string
+	var placeholders config.SelectorMap
+	if sel, err := config.GetSelectors(); err == nil {
+		selectors = selectorList(sel.Input)
+		placeholders = sel.InputPlaceholders
+	}
+
+	hasDefault := false
+	for _, s := range selectors {
+		if s == InputElement {
+			hasDefault = true
+		}
+	}
+	if !hasDefault {
+		selectors = append(selectors, InputElement)
+	}
+
+	return append(selectors, placeholderSelectors(placeholders)...)
+}
+
+// placeholderSelectors builds placeholder-text selectors for each locale,
+// sorted by locale so the order is stable
+func placeholderSelectors(placeholders config.SelectorMap) []string {
+	locales := make([]string, 0, len(placeholders))
+	for locale := range placeholders {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	// CSS string: only backslashes and double quotes need escaping
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+	var selectors []string
+	for _, locale := range locales {
+		text := `"` + quote.Replace(placeholders[locale]) + `"`
+		selectors = append(selectors,
+			fmt.Sprintf("textarea[placeholder*=%s]", text),
+			fmt.Sprintf("[contenteditable='true'][data-placeholder*=%s]", text),
+		)
+	}
+	return selectors
+}
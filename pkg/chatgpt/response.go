@@ -0,0 +1,206 @@
+package chatgpt
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chatgpt-element-recorder/pkg/errs"
+	"github.com/chromedp/chromedp"
+)
+
+// reasoningHeader matches the "Thought for 12s" / "Reasoned for 1m" summary
+// line GPT5 prepends to answers when the reasoning block is collapsed.
+var reasoningHeader = regexp.MustCompile(`^(?:Thought|Reasoned) for [^\n]*\n*`)
+
+// cleanResponse applies the configured filters to a scraped response
+func (c *ChatGPT) cleanResponse(response string) string {
+	response = strings.TrimSpace(response)
+
+	if c.config.Agent.HideReasoning {
+		response = stripReasoning(response)
+	}
+	if c.config.ChatGPT.Citations.Strip {
+		response = stripCitationMarkers(response)
+	}
+	response = stripActionRows(response, c.config.ChatGPT.UIActionLabels)
+
+	return response
+}
+
+// stripActionRows drops trailing lines that are only UI action labels, such as
+// the "Regenerate" / "Share" row scraped along with a turn. Matching is
+// case-insensitive and only ever trims from the end, so the words are safe
+// inside the answer itself.
+func stripActionRows(response string, labels []string) string {
+	if len(labels) == 0 {
+		return response
+	}
+	known := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		known[strings.ToLower(strings.TrimSpace(label))] = true
+	}
+
+	lines := strings.Split(response, "\n")
+	end := len(lines)
+	for end > 0 {
+		line := strings.ToLower(strings.TrimSpace(lines[end-1]))
+		if line != "" && !known[line] {
+			break
+		}
+		end--
+	}
+	return strings.TrimSpace(strings.Join(lines[:end], "\n"))
+}
+
+// stripReasoning removes a leading reasoning summary from the response text
+func stripReasoning(response string) string {
+	return strings.TrimSpace(reasoningHeader.ReplaceAllString(response, ""))
+}
+
+// hideReasoningJS defines hideReasoning(root), which hides the reasoning
+// blocks inside root and returns a function that shows them again, the same
+// way hideCitations does for citation chips
+func hideReasoningJS() string {
+	selectorJSON, _ := json.Marshal(ReasoningBlock)
+	return fmt.Sprintf(`
+		function hideReasoning(root) {
+			const blocks = Array.from(root.querySelectorAll(%s));
+			const previous = blocks.map(block => block.style.display);
+			blocks.forEach(block => { block.style.display = 'none'; });
+			return () => blocks.forEach((block, i) => { block.style.display = previous[i]; });
+		}
+	`, selectorJSON)
+}
+
+// readAssistantTurns returns the rendered text of every assistant turn on the page
+func (c *ChatGPT) readAssistantTurns() ([]string, error) {
+	script := fmt.Sprintf(`
+        (function() {
+            %s
+            %s
+            const turns = document.querySelectorAll('%s');
+            return Array.from(turns).map(turn => {
+                const content = turn.querySelector('%s') || turn;
+                const showCitations = %t ? hideCitations(content) : () => {};
+                // The reasoning block is hidden rather than cut from the text,
+                // so answer text that repeats it is kept
+                const showReasoning = %t ? hideReasoning(turn) : () => {};
+                const text = content.innerText || '';
+                showReasoning();
+                showCitations();
+                return text;
+            });
+        })();
+    `, hideCitationsJS(), hideReasoningJS(), AssistantMessage, ResponseContent, c.config.ChatGPT.Citations.Strip, c.config.Agent.HideReasoning)
+
+	var turns []string
+	if err := chromedp.Run(c.ctx, chromedp.Evaluate(script, &turns)); err != nil {
+		return nil, err
+	}
+	return turns, nil
+}
+
+// readLastTurn returns the content of the last settled assistant turn at or
+// after index from, the turn count taken before the message was sent, so an
+// earlier answer is never mistaken for the new one. Reading twice lets us skip
+// placeholder turns and text that is still being rendered.
+func (c *ChatGPT) readLastTurn(from int) (string, error) {
+	firstRead, err := c.readAssistantTurns()
+	if err != nil {
+		return "", errs.Wrap("read response", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	secondRead, err := c.readAssistantTurns()
+	if err != nil {
+		return "", errs.Wrap("read response", err)
+	}
+
+	index := pickStableTurn(firstRead, secondRead, from)
+	c.debugf("saw %d assistant turns, using index %d", len(secondRead), index)
+	if index < 0 {
+		return "", errs.New("read response", errs.ErrEmptyResponse)
+	}
+
+	response := c.cleanResponse(secondRead[index])
+	if response == "" {
+		return "", errs.New("read response", errs.ErrEmptyResponse)
+	}
+	return response, nil
+}
+
+// Scrape strategies for chatgpt.scrape_strategy
+const (
+	scrapeSelector = "selector" // read turns through the built-in selectors
+	scrapeJS       = "js"       // evaluate a single extractor snippet first
+)
+
+// defaultExtractorJS returns JS that reads the last assistant answer's text,
+// leaving out citation chips when chatgpt.citations.strip is on
+func (c *ChatGPT) defaultExtractorJS() string {
+	return fmt.Sprintf(`
+	(function() {
+		%s
+		const turns = document.querySelectorAll('%s');
+		const last = turns[turns.length - 1];
+		if (!last) return '';
+		const content = last.querySelector('%s') || last;
+		const showCitations = %t ? hideCitations(content) : () => {};
+		const text = content.innerText || '';
+		showCitations();
+		return text;
+	})();
+`, hideCitationsJS(), AssistantMessage, ResponseContent, c.config.ChatGPT.Citations.Strip)
+}
+
+// extractWithJS evaluates the configured extractor (or the built-in one) and
+// returns its text. Errors and non-string results count as empty so the
+// caller can fall back to the selector path.
+func (c *ChatGPT) extractWithJS() string {
+	script := c.config.ChatGPT.ExtractorJS
+	if strings.TrimSpace(script) == "" {
+		script = c.defaultExtractorJS()
+	}
+
+	var result interface{}
+	if err := chromedp.Run(c.ctx, chromedp.Evaluate(script, &result)); err != nil {
+		c.debugf("JS extractor failed: %v", err)
+		return ""
+	}
+	text, _ := result.(string)
+	return text
+}
+
+// pickStableTurn returns the index of the last assistant turn from index from
+// on that has text and did not change between two reads, falling back to the
+// last such non-empty turn of the second read. Turns before from were on the
+// page before the message was sent and are never picked. It returns -1 when
+// no new turn has text.
+func pickStableTurn(first, second []string, from int) int {
+	from = max(from, 0)
+	for i := len(second) - 1; i >= from; i-- {
+		if strings.TrimSpace(second[i]) == "" {
+			continue
+		}
+		if i < len(first) && first[i] == second[i] {
+			return i
+		}
+	}
+
+	for i := len(second) - 1; i >= from; i-- {
+		if strings.TrimSpace(second[i]) != "" {
+			return i
+		}
+	}
+	return -1
+}
+
+// debugf logs client internals when chatgpt.debug is enabled in config
+func (c *ChatGPT) debugf(format string, args ...interface{}) {
+	if c.config.IsDebug() {
+		log.Printf("[debug] "+format, args...)
+	}
+}
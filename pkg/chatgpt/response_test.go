@@ -0,0 +1,58 @@
+package chatgpt
+
+import "testing"
+
+func TestPickStableTurn(t *testing.T) {
+	tests := []struct {
+		name          string
+		first, second []string
+		from          int
+		want          int
+	}{
+		{"new turn settled", []string{"old", "new"}, []string{"old", "new"}, 1, 1},
+		{"new turn still changing", []string{"old", "ne"}, []string{"old", "new"}, 1, 1},
+		{"new turn empty", []string{"old", ""}, []string{"old", ""}, 1, -1},
+		{"no new turn yet", []string{"old"}, []string{"old"}, 1, -1},
+		{"older turn stable, new one changing", []string{"a", "b", "c"}, []string{"a", "b", "cd"}, 2, 2},
+		{"placeholder after the answer", []string{"old", "answer", ""}, []string{"old", "answer", ""}, 1, 1},
+		{"first message in a chat", []string{"answer"}, []string{"answer"}, 0, 0},
+		{"negative count reads every turn", []string{"answer"}, []string{"answer"}, -1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pickStableTurn(tt.first, tt.second, tt.from); got != tt.want {
+				t.Errorf("pickStableTurn(%q, %q, %d) = %d, want %d", tt.first, tt.second, tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripActionRows(t *testing.T) {
+	labels := []string{"Copy", "Edit", "Regenerate", "Share", "Good response", "Bad response", "Read aloud", "More actions"}
+	tests := []struct {
+		name     string
+		response string
+		labels   []string
+		want     string
+	}{
+		{"trailing regenerate and share row", "The answer.\nRegenerate\nShare", labels, "The answer."},
+		{"row with blank lines and padding", "The answer.\n\n  Copy  \n\nRegenerate\nShare\n", labels, "The answer."},
+		{"labels in another case", "The answer.\nREGENERATE\nshare", labels, "The answer."},
+		{"multi-word labels", "The answer.\nGood response\nBad response\nRead aloud", labels, "The answer."},
+		{"words inside a sentence", "Click Regenerate to try again, or Share the chat.", labels, "Click Regenerate to try again, or Share the chat."},
+		{"label line in the middle", "Steps:\nRegenerate\nthen wait.", labels, "Steps:\nRegenerate\nthen wait."},
+		{"last line mentions the words", "Done.\nUse Share or Regenerate", labels, "Done.\nUse Share or Regenerate"},
+		{"label followed by text", "Copy\nthe file first.", labels, "Copy\nthe file first."},
+		{"only labels", "Regenerate\nShare", labels, ""},
+		{"no labels configured", "The answer.\nRegenerate\nShare", nil, "The answer.\nRegenerate\nShare"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripActionRows(tt.response, tt.labels); got != tt.want {
+				t.Errorf("stripActionRows(%q) = %q, want %q", tt.response, got, tt.want)
+			}
+		})
+	}
+}
@@ -2,11 +2,24 @@ package chatgpt
 
 // Selectors are hardcoded for stability and simplicity.
 const (
-	InputElement     = `#prompt-textarea`
-	SubmitButton     = `button[data-testid="send-button"]`
-	StopButton       = `button[data-testid="stop-button"]`
-	LastResponse     = `div[data-message-author-role="assistant"]:last-child .markdown`
-	NewChatButton    = `a[href="/"]`
-	HistoryLink      = `a[href^="/c/"]`
-	AssistantMessage = `div[data-message-author-role="assistant"]`
+	InputElement        = `#prompt-textarea`
+	SubmitButton        = `button[data-testid="send-button"]`
+	StopButton          = `button[data-testid="stop-button"]`
+	LastResponse        = `div[data-message-author-role="assistant"]:last-child .markdown`
+	ResponseContent     = `.markdown`
+	NewChatButton       = `a[href="/"]`
+	HistoryLink         = `a[href^="/c/"]`
+	AssistantMessage    = `div[data-message-author-role="assistant"]`
+	ReasoningBlock      = `[data-testid*="reasoning"]`
+	ErrorToast          = `[role="alert"], [data-testid*="toast"]`
+	ErrorBanner         = `[data-testid="error-banner"]`
+	ChatOptions         = `button[data-testid$="-options"], button[aria-label*="options"]`
+	MenuItem            = `[role="menuitem"]`
+	RenameInput         = `nav input[type="text"]`
+	DeleteConfirmButton = `[role="dialog"] button[data-testid="delete-conversation-confirm-button"], [role="dialog"] button.btn-danger`
+	ModelSwitcher       = `[data-testid="model-switcher-dropdown-button"]`
+	ConversationTurn    = `[data-message-author-role]`
+	RegenerateButton    = `button[aria-label*="Regenerate"], button[data-testid="regenerate-turn-action-button"], [data-testid="error-banner"] button`
+	CopyButton          = `button[data-testid="copy-turn-action-button"], button[aria-label="Copy"]`
+	CitationChip        = `[data-testid*="citation"], span[class*="citation"], sup:has(a[href^="http"])`
 )
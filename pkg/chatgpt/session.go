@@ -0,0 +1,99 @@
+package chatgpt
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/chatgpt-element-recorder/pkg/browser"
+	"github.com/chatgpt-element-recorder/pkg/config"
+	"github.com/chatgpt-element-recorder/pkg/errs"
+	"github.com/chromedp/chromedp"
+)
+
+// defaultLoginButton is used when selectors.json has no authentication.login_button
+const defaultLoginButton = `[data-testid="login-button"]`
+
+// IsLoggedOut reports whether the page is ChatGPT's login wall: an auth URL,
+// or the logged-out layout that shows a login button
+func (c *ChatGPT) IsLoggedOut() (bool, error) {
+	loginButton := defaultLoginButton
+	if sel, err := config.GetSelectors(); err == nil && sel.Authentication["login_button"] != "" {
+		loginButton = sel.Authentication["login_button"]
+	}
+	loginJSON, _ := json.Marshal(loginButton)
+
+	script := fmt.Sprintf(`
+		(function() {
+			const url = new URL(location.href);
+			if (url.hostname.startsWith('auth.') || url.pathname.startsWith('/auth/login')) return true;
+			return document.querySelector(%s) !== null;
+		})();
+	`, loginJSON)
+
+	var loggedOut bool
+	if err := chromedp.Run(c.ctx, chromedp.Evaluate(script, &loggedOut)); err != nil {
+		return false, errs.Wrap("check login", err)
+	}
+	return loggedOut, nil
+}
+
+// checkLoggedIn returns ErrNotLoggedIn when the page is the login wall
+func (c *ChatGPT) checkLoggedIn(op string) error {
+	if loggedOut, err := c.IsLoggedOut(); err == nil && loggedOut {
+		return errs.New(op, errs.ErrNotLoggedIn)
+	}
+	return nil
+}
+
+// RestoreSession reloads the saved cookies and navigates back to ChatGPT
+func (c *ChatGPT) RestoreSession() error {
+	err := chromedp.Run(c.ctx,
+		browser.LoadCookiesAction(),
+		chromedp.Navigate(c.config.ChatGPT.BaseURL),
+	)
+	if err != nil {
+		return errs.Wrap("restore session", err)
+	}
+	c.resetChat(ChatHistoryItem{})
+	return c.ensureReady(c.ctx)
+}
+
+// SaveSession writes the browser's current cookies to the cookies file
+func (c *ChatGPT) SaveSession() error {
+	if err := chromedp.Run(c.ctx, browser.SaveCookiesAction()); err != nil {
+		return errs.Wrap("save session", err)
+	}
+	return nil
+}
+
+// saveCookiesInBackground refreshes the cookies file after a successful send,
+// at most once per chatgpt.cookie_save_interval_seconds; zero turns it off.
+// The save runs in its own goroutine and a failure is only logged in debug
+// mode, since the cookies saved earlier still work.
+func (c *ChatGPT) saveCookiesInBackground() {
+	interval := time.Duration(c.config.ChatGPT.CookieSaveIntervalSeconds) * time.Second
+	if interval <= 0 {
+		return
+	}
+
+	last := atomic.LoadInt64(&c.cookiesSavedAt)
+	now := time.Now().UnixNano()
+	if last != 0 && time.Duration(now-last) < interval {
+		return
+	}
+	// Claim this slot so concurrent sends don't start a second save
+	if !atomic.CompareAndSwapInt64(&c.cookiesSavedAt, last, now) {
+		return
+	}
+
+	ctx := c.currentContext()
+	go func() {
+		if err := chromedp.Run(ctx, browser.SaveCookiesAction()); err != nil {
+			c.debugf("background cookie save failed: %v", err)
+			return
+		}
+		c.debugf("cookies saved")
+	}()
+}
@@ -0,0 +1,133 @@
+package chatgpt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/chromedp/chromedp"
+)
+
+// streamPollInterval is how often a streamed answer is re-read
+const streamPollInterval = 200 * time.Millisecond
+
+// SendMessageStream sends a message and streams the answer as it is generated.
+// Each value on the channel is the text added since the previous one; the
+// channel closes when the stop button disappears or ctx is done. The settled
+// answer, or the error hit while waiting, is reported by StreamResult once the
+// channel is closed.
+func (c *ChatGPT) SendMessageStream(ctx context.Context, message string) (<-chan string, error) {
+	atomic.AddInt32(&c.sending, 1)
+	initialToastCount, initialMessageCount, err := c.submitMessage(message)
+	if err != nil {
+		c.markActivity()
+		atomic.AddInt32(&c.sending, -1)
+		return nil, err
+	}
+
+	c.streamErr = nil
+	c.streamed = ""
+	c.lastSources = nil
+	sentAt := time.Now()
+	chunks := make(chan string)
+	go func() {
+		defer close(chunks)
+		defer func() {
+			c.markActivity()
+			atomic.AddInt32(&c.sending, -1)
+		}()
+
+		response, err := c.streamResponse(ctx, chunks, initialToastCount, initialMessageCount)
+		if err != nil {
+			c.streamErr = err
+			return
+		}
+		c.streamed = response
+		if c.config.ChatGPT.Citations.CollectSources {
+			c.lastSources = c.readSources()
+		}
+		c.chatChars += utf8.RuneCountInString(message) + utf8.RuneCountInString(response)
+		c.usage.ResponseChars += utf8.RuneCountInString(response)
+		c.track(message, sentAt, response)
+		c.saveCookiesInBackground()
+	}()
+	return chunks, nil
+}
+
+// StreamResult returns the answer to the last SendMessageStream as read from
+// the page once it settled, or the error that ended it early. The chunks may
+// not add up to it when the page re-rendered text already sent.
+func (c *ChatGPT) StreamResult() (string, error) {
+	return c.streamed, c.streamErr
+}
+
+// streamResponse polls the new assistant turn until generation finishes,
+// sending the text added since each previous poll, and returns the full answer
+func (c *ChatGPT) streamResponse(ctx context.Context, chunks chan<- string, initialToastCount, initialMessageCount int) (_ string, err error) {
+	sentAt := time.Now()
+	c.lastTiming = Timing{}
+
+	waitCtx, cancel := withTimeout(ctx, c.timeout)
+	defer cancel()
+	defer func() { err = responseTimeout(waitCtx, err) }()
+
+	if err := c.waitForResponseState(waitCtx, initialToastCount, initialMessageCount, false); err != nil {
+		return "", err
+	}
+	c.lastTiming.FirstToken = time.Since(sentAt)
+
+	doneScript := fmt.Sprintf(`!document.querySelector('%s')`, StopButton)
+	sent := ""
+	emit := func(text string) error {
+		// Re-rendered text that no longer extends what was sent is left for
+		// the next poll rather than sent twice
+		if len(text) <= len(sent) || !strings.HasPrefix(text, sent) {
+			return nil
+		}
+		select {
+		case chunks <- text[len(sent):]:
+			sent = text
+			return nil
+		case <-waitCtx.Done():
+			return waitCtx.Err()
+		}
+	}
+
+	ticker := time.NewTicker(streamPollInterval)
+	defer ticker.Stop()
+	for {
+		var done bool
+		if err := chromedp.Run(waitCtx, chromedp.Evaluate(doneScript, &done)); err != nil {
+			return "", err
+		}
+
+		if turns, err := c.readAssistantTurns(); err == nil && len(turns) > initialMessageCount {
+			if err := emit(c.cleanResponse(turns[len(turns)-1])); err != nil {
+				return "", err
+			}
+		}
+
+		if done {
+			break
+		}
+		select {
+		case <-ticker.C:
+		case <-waitCtx.Done():
+			return "", waitCtx.Err()
+		}
+	}
+	c.lastTiming.Complete = time.Since(sentAt)
+
+	// The settled turn may differ from the last poll, so send what it adds
+	response, err := c.readLastTurn(initialMessageCount)
+	if err != nil {
+		return "", err
+	}
+	if err := emit(response); err != nil {
+		return "", err
+	}
+	return response, nil
+}
@@ -0,0 +1,169 @@
+package chatgpt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/chatgpt-element-recorder/pkg/browser"
+	"github.com/chatgpt-element-recorder/pkg/errs"
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// Tabs lists the open browser tabs, marking the one the client is bound to
+func (c *ChatGPT) Tabs() ([]TabInfo, error) {
+	targets, err := chromedp.Targets(c.ctx)
+	if err != nil {
+		return nil, errs.Wrap("list tabs", err)
+	}
+
+	current := c.currentTargetID()
+	var tabs []TabInfo
+	for _, t := range targets {
+		if t.Type != "page" {
+			continue
+		}
+		tabs = append(tabs, TabInfo{
+			Index:     len(tabs) + 1,
+			ID:        string(t.TargetID),
+			Title:     t.Title,
+			URL:       t.URL,
+			Active:    t.TargetID == current,
+			IsChatGPT: c.isChatGPTURL(t.URL),
+		})
+	}
+	return tabs, nil
+}
+
+// SwitchTab binds the client to the tab at a 1-based index from Tabs
+func (c *ChatGPT) SwitchTab(index int) (TabInfo, error) {
+	tabs, err := c.Tabs()
+	if err != nil {
+		return TabInfo{}, err
+	}
+	if index < 1 || index > len(tabs) {
+		return TabInfo{}, errs.New(fmt.Sprintf("switch to tab %d (available: 1-%d)", index, len(tabs)), errs.ErrNoSuchTab)
+	}
+
+	tab := tabs[index-1]
+	if err := c.bindTab(target.ID(tab.ID)); err != nil {
+		return TabInfo{}, err
+	}
+	return tab, nil
+}
+
+// EnsureChatGPTTab makes sure the client drives a ChatGPT page. If the bound
+// tab is on another site it switches to an open ChatGPT tab, or opens one.
+func (c *ChatGPT) EnsureChatGPTTab() error {
+	if !c.config.ChatGPT.AutoSelectTab {
+		return nil
+	}
+
+	tabs, err := c.Tabs()
+	if err != nil {
+		return err
+	}
+
+	for _, tab := range tabs {
+		if tab.Active && tab.IsChatGPT {
+			return nil
+		}
+	}
+	for _, tab := range tabs {
+		if tab.IsChatGPT {
+			return c.bindTab(target.ID(tab.ID))
+		}
+	}
+
+	// No ChatGPT tab is open, so open one alongside the others
+	tabCtx, _ := chromedp.NewContext(c.ctx)
+	if err := c.attachTab(tabCtx); err != nil {
+		return err
+	}
+	if err := chromedp.Run(tabCtx, chromedp.Navigate(c.config.ChatGPT.BaseURL)); err != nil {
+		return errs.Wrap("open ChatGPT tab", err)
+	}
+	c.useTabContext(tabCtx)
+	return nil
+}
+
+// bindTab points the client at another tab and brings it to the front. Attached
+// contexts are kept rather than cancelled, since cancelling closes the tab.
+func (c *ChatGPT) bindTab(id target.ID) error {
+	if id == c.currentTargetID() {
+		return nil
+	}
+
+	tabCtx, ok := c.tabs[id]
+	if !ok {
+		tabCtx, _ = chromedp.NewContext(c.ctx, chromedp.WithTargetID(id))
+		if err := c.attachTab(tabCtx); err != nil {
+			return err
+		}
+	}
+
+	err := chromedp.Run(tabCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		chromeCtx := chromedp.FromContext(ctx)
+		return target.ActivateTarget(id).Do(cdp.WithExecutor(ctx, chromeCtx.Browser))
+	}))
+	if err != nil {
+		return errs.Wrap("switch tab", err)
+	}
+
+	c.useTabContext(tabCtx)
+	return nil
+}
+
+// attachTab prepares a tab context the client has not used before. Proxy
+// challenges are answered per target, so each new tab needs its own handler
+// besides the one main sets up on the first.
+func (c *ChatGPT) attachTab(tabCtx context.Context) error {
+	auth := c.config.Browser.ProxyAuth
+	if c.config.Browser.ProxyServer == "" || auth.Username == "" {
+		return nil
+	}
+	if err := chromedp.Run(tabCtx, browser.ProxyAuthAction(auth.Username, auth.Password)); err != nil {
+		return errs.Wrap("set up proxy authentication", err)
+	}
+	return nil
+}
+
+// useTabContext makes tabCtx the context for all further actions and resets
+// the per-chat state, which belonged to the previous tab
+func (c *ChatGPT) useTabContext(tabCtx context.Context) {
+	// Remember the outgoing context so switching back reuses it
+	if c.tabs == nil {
+		c.tabs = make(map[target.ID]context.Context)
+	}
+	if id := c.currentTargetID(); id != "" {
+		c.tabs[id] = c.ctx
+	}
+
+	c.ctxMu.Lock()
+	c.ctx = tabCtx
+	c.ctxMu.Unlock()
+	c.resetChat(ChatHistoryItem{})
+}
+
+// currentContext returns the context of the tab the client is bound to. The
+// client's own goroutine reads c.ctx directly; background goroutines use
+// this, since a tab switch may be replacing it.
+func (c *ChatGPT) currentContext() context.Context {
+	c.ctxMu.RLock()
+	defer c.ctxMu.RUnlock()
+	return c.ctx
+}
+
+// currentTargetID returns the ID of the tab the client is bound to
+func (c *ChatGPT) currentTargetID() target.ID {
+	if chromeCtx := chromedp.FromContext(c.ctx); chromeCtx != nil && chromeCtx.Target != nil {
+		return chromeCtx.Target.TargetID
+	}
+	return ""
+}
+
+// isChatGPTURL reports whether url is on a ChatGPT domain
+func (c *ChatGPT) isChatGPTURL(url string) bool {
+	return c.config.IsAllowedURL(url)
+}
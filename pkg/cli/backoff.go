@@ -0,0 +1,16 @@
+package cli
+
+import (
+	"github.com/chatgpt-element-recorder/pkg/agent"
+	"github.com/chatgpt-element-recorder/pkg/config"
+)
+
+// retryOnRateLimit waits out rate limits around step, which sends through
+// a, unless the agent waits them out itself. Auto mode retries each step of
+// its plan, so retrying the whole message would plan and ask again.
+func retryOnRateLimit(cfg config.RateLimitConfig, a *agent.Agent, step func() (string, error)) (string, error) {
+	if a != nil && a.HandlesRateLimits() {
+		return step()
+	}
+	return agent.RetryOnRateLimit(cfg, step)
+}
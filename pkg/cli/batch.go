@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/chatgpt-element-recorder/pkg/agent"
+	"github.com/chatgpt-element-recorder/pkg/config"
+	"golang.org/x/term"
+)
+
+// StdinPiped reports whether stdin is a pipe or file rather than a terminal,
+// in which case input is read as a batch of queries instead of a chat
+func StdinPiped() bool {
+	return !term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// runBatch sends every non-empty line of in as a query, in order, and hands
+// each response to emit. Failures are reported on stderr and the batch
+// carries on; the returned error says how many queries failed. a is the
+// agent send goes through, if any.
+func runBatch(in io.Reader, maxKB int, a *agent.Agent, send func(string) (string, error), emit func(query, response string)) error {
+	if maxKB <= 0 {
+		maxKB = 1024
+	}
+	cfg, _ := config.LoadDynamicConfig()
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxKB*1024)
+
+	failed := 0
+	for scanner.Scan() {
+		query := strings.TrimSpace(scanner.Text())
+		if query == "" {
+			continue
+		}
+		config.ApplyReloads()
+
+		response, err := retryOnRateLimit(cfg.ChatGPT.RateLimit, a, func() (string, error) {
+			return send(query)
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "query failed: %v\n", err)
+			failed++
+			continue
+		}
+		emit(query, response)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stdin: %v", err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d queries failed", failed)
+	}
+	return nil
+}
+
+// printPlain writes a batch response to stdout as is
+func printPlain(query, response string) {
+	fmt.Println(response)
+}
+
+// runBatch answers queries piped on stdin through the agent, if there is one
+func (cli *CLI) runBatch() error {
+	send := cli.chatgpt.SendMessage
+	if cli.agent != nil {
+		send = cli.agent.ProcessMessage
+	}
+	return runBatch(os.Stdin, cli.config.UI.MaxInputKB, cli.agent, send, printPlain)
+}
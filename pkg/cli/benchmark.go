@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/chatgpt-element-recorder/pkg/agent"
+	"github.com/chatgpt-element-recorder/pkg/file"
+	"github.com/chatgpt-element-recorder/pkg/ui"
+)
+
+// benchmarkPrompt is short and deterministic so runs are comparable
+const benchmarkPrompt = "Reply with exactly the word: pong"
+
+// benchmarkRun holds the measurements of a single benchmark round
+type benchmarkRun struct {
+	Run          int    `json:"run"`
+	FirstTokenMs int64  `json:"first_token_ms"`
+	CompleteMs   int64  `json:"complete_ms"`
+	Error        string `json:"error,omitempty"`
+}
+
+// benchmarkReport is the JSON document written by /benchmark --save
+type benchmarkReport struct {
+	Timestamp time.Time      `json:"timestamp"`
+	Prompt    string         `json:"prompt"`
+	Runs      []benchmarkRun `json:"runs"`
+}
+
+// runBenchmark sends a fixed prompt to n fresh chats and reports latency.
+// Usage: /benchmark [n] [--save]
+func (cli *CLI) runBenchmark(args []string) error {
+	runs := 3
+	save := false
+	for _, arg := range args {
+		if arg == "--save" {
+			save = true
+			continue
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid run count: %s", arg)
+		}
+		runs = n
+	}
+	// Checked before the runs so they aren't spent on a report that can't be saved
+	if save && cli.isReadOnly() {
+		return agent.ErrReadOnly
+	}
+
+	report := benchmarkReport{Timestamp: time.Now(), Prompt: benchmarkPrompt}
+	progress := ui.NewProgressBar(runs)
+	for i := 1; i <= runs; i++ {
+		progress.Increment(fmt.Sprintf("benchmark run %d", i))
+
+		run := benchmarkRun{Run: i}
+		err := cli.chatgpt.StartNewChat()
+		if err == nil {
+			_, err = cli.chatgpt.SendMessage(benchmarkPrompt)
+		}
+		progress.Clear()
+
+		if err != nil {
+			run.Error = err.Error()
+			ui.PrintWarning(fmt.Sprintf("Run %d failed: %v", i, err))
+		} else {
+			timing := cli.chatgpt.LastTiming()
+			run.FirstTokenMs = timing.FirstToken.Milliseconds()
+			run.CompleteMs = timing.Complete.Milliseconds()
+			fmt.Printf("Run %d: first token %dms, complete %dms\n", i, run.FirstTokenMs, run.CompleteMs)
+		}
+		report.Runs = append(report.Runs, run)
+	}
+	progress.Done()
+
+	printBenchmarkSummary(report.Runs)
+	ui.PrintInfo("The benchmark ran in fresh chats - use /new or /open to continue working")
+
+	if save {
+		return cli.saveBenchmark(report)
+	}
+	return nil
+}
+
+// printBenchmarkSummary prints min/avg/max over the successful runs
+func printBenchmarkSummary(runs []benchmarkRun) {
+	var firstTokens, completes []int64
+	for _, run := range runs {
+		if run.Error == "" {
+			firstTokens = append(firstTokens, run.FirstTokenMs)
+			completes = append(completes, run.CompleteMs)
+		}
+	}
+
+	fmt.Println("\n⏱️  Benchmark Results:")
+	ui.PrintSeparator()
+	if len(completes) == 0 {
+		ui.PrintWarning("No successful runs")
+		return
+	}
+
+	fmt.Printf("Successful runs: %d/%d\n", len(completes), len(runs))
+	fmt.Printf("%-14s %8s %8s %8s\n", "", "min", "avg", "max")
+	for _, row := range []struct {
+		name   string
+		values []int64
+	}{
+		{"First token", firstTokens},
+		{"Complete", completes},
+	} {
+		min, avg, max := summarize(row.values)
+		fmt.Printf("%-14s %6dms %6dms %6dms\n", row.name, min, avg, max)
+	}
+	ui.PrintSeparator()
+}
+
+// summarize returns the min, average and max of non-empty values
+func summarize(values []int64) (int64, int64, int64) {
+	min, max, total := values[0], values[0], int64(0)
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+		total += v
+	}
+	return min, total / int64(len(values)), max
+}
+
+// saveBenchmark writes the report as JSON into the configured output directory
+func (cli *CLI) saveBenchmark(report benchmarkReport) error {
+	if cli.isReadOnly() {
+		return agent.ErrReadOnly
+	}
+
+	outputDir := cli.config.Files.OutputDir
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	path := filepath.Join(outputDir, fmt.Sprintf("benchmark-%s.json", report.Timestamp.Format("20060102-150405")))
+	if err := file.WriteJSONFile(path, report); err != nil {
+		return fmt.Errorf("failed to save benchmark: %v", err)
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Benchmark saved to %s", path))
+	return nil
+}
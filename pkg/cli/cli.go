@@ -1,7 +1,7 @@
 package cli
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,15 +13,30 @@ import (
 	"github.com/chatgpt-element-recorder/pkg/browser"
 	"github.com/chatgpt-element-recorder/pkg/chatgpt"
 	"github.com/chatgpt-element-recorder/pkg/config"
+	"github.com/chatgpt-element-recorder/pkg/errs"
+	"github.com/chatgpt-element-recorder/pkg/formatter"
 	"github.com/chatgpt-element-recorder/pkg/ui"
 )
 
 // CLI represents the command line interface
 type CLI struct {
-	chatgpt *chatgpt.ChatGPT
-	scanner *bufio.Scanner
-	agent   *agent.Agent // Agent system integration
-	config  *config.DynamicConfig
+	chatgpt       *chatgpt.ChatGPT
+	input         *inputReader
+	agent         *agent.Agent // Agent system integration
+	config        *config.DynamicConfig
+	pendingWrite  *pendingWrite // Content offered to /write
+	lastSent      string        // Last prompt typed and sent, for the duplicate guard
+	lastSentAt    time.Time
+	initialPrompt string // Sent once after context seeding, from --ask
+	lastResponse  string // Most recent answer, for /save-code
+	sessionStats  SessionStats
+	multiline     bool // /multiline mode: lines are buffered until .send
+}
+
+// pendingWrite holds generated file content waiting for /write
+type pendingWrite struct {
+	path    string
+	content string
 }
 
 // NewCLI creates a new CLI instance
@@ -32,182 +47,1857 @@ func NewCLI(chatgptClient *chatgpt.ChatGPT) *CLI {
 		// Use default config if loading fails
 		ui.PrintWarning("Could not load configuration, using defaults")
 	}
-	
-	// Create agent instance
-	agentInstance, err := agent.NewAgent(chatgptClient)
+	
+	// Create agent instance
+	agentInstance, err := agent.NewAgent(chatgptClient)
+	if err != nil {
+		// Continue without agent if creation fails
+		ui.PrintWarning("Could not initialize agent system")
+		agentInstance = nil
+	}
+	
+	ui.SetTyping(config.UI.TypingEffect)
+	ui.SetAutoConfirm(config.Agent.AutoConfirm)
+
+	input := newInputReader(config.UI.MaxInputKB, config.UI.LineEditing)
+	ui.SetConfirmReader(input.ReadLine)
+
+	return &CLI{
+		chatgpt:      chatgptClient,
+		input:        input,
+		agent:        agentInstance,
+		config:       config,
+		sessionStats: SessionStats{Started: time.Now()},
+	}
+}
+
+// SetReadOnly enables safe mode, where the agent never writes files
+func (cli *CLI) SetReadOnly(readOnly bool) {
+	if cli.agent != nil {
+		cli.agent.SetReadOnly(readOnly)
+	}
+}
+
+// isReadOnly reports whether safe mode is on
+func (cli *CLI) isReadOnly() bool {
+	return cli.agent != nil && cli.agent.IsReadOnly()
+}
+
+// SetInitialPrompt sets a prompt to send once at startup before the interactive loop
+func (cli *CLI) SetInitialPrompt(prompt string) {
+	cli.initialPrompt = strings.TrimSpace(prompt)
+}
+
+// Start starts the CLI interface
+func (cli *CLI) Start() error {
+	// Piped input is a batch of queries, not a conversation
+	if StdinPiped() {
+		cli.applyDefaultModel()
+		return cli.runBatch()
+	}
+
+	cli.printWelcome()
+
+	if keepalive := cli.config.ChatGPT.Keepalive; keepalive.Enabled {
+		cli.chatgpt.StartKeepalive(time.Duration(keepalive.Interval) * time.Second)
+	}
+
+	if model := cli.config.ChatGPT.DefaultModel; model != "" {
+		if err := cli.selectModel(model); err != nil {
+			ui.PrintWarning(fmt.Sprintf("Could not switch to the default model: %v", err))
+		}
+	}
+	
+	// A reopened chat already has its context; otherwise send the system prompt
+	if !cli.restoreSession() {
+		if err := cli.sendSystemPromptForNewChat(); err != nil {
+			ui.PrintWarning("Could not establish initial project context")
+		}
+	}
+
+	if cli.initialPrompt != "" {
+		fmt.Println(ui.Cyan + ui.Bold + "You: " + ui.Reset + cli.initialPrompt)
+		cli.respond(cli.initialPrompt)
+	}
+
+	for {
+		line, ok := cli.readMessage()
+		if !ok {
+			break
+		}
+
+		// Config files edited while waiting take effect before the input is handled
+		config.ApplyReloads()
+
+		input := strings.TrimSpace(line)
+		if input == "" {
+			continue
+		}
+
+		// Handle commands
+		if strings.HasPrefix(input, "/") {
+			if err := cli.handleCommand(input); err != nil {
+				printError("Error", err)
+			}
+			continue
+		}
+
+		if cli.config.UI.UnescapeInput {
+			input = unescapeInput(input)
+		}
+
+		if cli.isDuplicateSend(input) && !cli.confirm("⚠️  You just sent this same message. Send it again?") {
+			continue
+		}
+
+		cli.respond(input)
+	}
+
+	cli.saveSession()
+	return nil
+}
+
+// isDuplicateSend reports whether input repeats the previous prompt within the guard window
+func (cli *CLI) isDuplicateSend(input string) bool {
+	guard := cli.config.UI.DuplicateGuard
+	if !guard.Enabled || cli.lastSent == "" {
+		return false
+	}
+	return input == cli.lastSent && time.Since(cli.lastSentAt) <= time.Duration(guard.Window)*time.Second
+}
+
+// handleSendError reports a failed send and, when the session has expired,
+// offers to log in again
+func (cli *CLI) handleSendError(err error) {
+	printError("Error sending message", err)
+	if !errors.Is(err, errs.ErrNotLoggedIn) {
+		return
+	}
+	if cli.confirm("Your ChatGPT session expired. Run /login now?") {
+		if err := cli.login(); err != nil {
+			printError("Login failed", err)
+		}
+	}
+}
+
+// login restores the saved cookies and, if that isn't enough, waits for the
+// user to log in through the browser window, then saves the new cookies
+func (cli *CLI) login() error {
+	spinner := ui.NewSquareSpinner()
+	spinner.Start("Restoring session from cookies...")
+	// A failed restore usually just means the login wall is still up, which is checked next
+	_ = cli.chatgpt.RestoreSession()
+	spinner.Stop()
+
+	loggedOut, err := cli.chatgpt.IsLoggedOut()
+	if err != nil {
+		return err
+	}
+	if !loggedOut {
+		ui.PrintSuccess("Logged in")
+		return nil
+	}
+
+	if cli.chatgpt.IsHeadless() {
+		return fmt.Errorf("saved cookies are no longer valid - export fresh cookies to %s and restart", browser.NewCookieManager().GetCookiesPath())
+	}
+
+	if err := cli.chatgpt.FocusWindow(); err != nil {
+		return err
+	}
+	ui.PrintInfo("Log in to ChatGPT in the browser window, then press Enter here")
+	if _, ok := cli.input.ReadLine(""); !ok {
+		return nil
+	}
+
+	loggedOut, err = cli.chatgpt.IsLoggedOut()
+	if err != nil {
+		return err
+	}
+	if loggedOut {
+		return errs.New("login", errs.ErrNotLoggedIn)
+	}
+
+	if err := cli.chatgpt.SaveSession(); err != nil {
+		ui.PrintWarning(fmt.Sprintf("Logged in, but could not save cookies: %v", err))
+		return nil
+	}
+	ui.PrintSuccess("Logged in - cookies saved")
+	return nil
+}
+
+// rotateChat moves the conversation to a fresh chat seeded with a summary
+func (cli *CLI) rotateChat() {
+	spinner := ui.NewSquareSpinner()
+	spinner.Start("Context is getting large, moving to a fresh chat...")
+	err := cli.agent.RotateChat()
+	spinner.Stop()
+
+	if err != nil {
+		ui.PrintWarning(fmt.Sprintf("Could not rotate chat: %v", err))
+		return
+	}
+	fmt.Println(ui.Dim + "↻ Continued in a new chat with a summary of the previous one" + ui.Reset)
+}
+
+// printError prints an error along with a hint for the typed client errors
+func printError(prefix string, err error) {
+	ui.PrintError(fmt.Sprintf("%s: %v", prefix, err))
+
+	switch {
+	case errors.Is(err, errs.ErrSelectorNotFound):
+		ui.PrintInfo("Check that you're logged in, or use /focus to look at the browser")
+	case errors.Is(err, errs.ErrTimeout):
+		ui.PrintInfo("ChatGPT is slow to respond - try again in a moment")
+	case errors.Is(err, errs.ErrNotLoggedIn):
+		ui.PrintInfo("Run /login to restore the session")
+	case errors.Is(err, errs.ErrContextDead):
+		ui.PrintInfo("The browser session ended - restart the CLI to reconnect")
+	}
+}
+
+// sendMessage sends a prompt a command built with a spinner, waiting out
+// rate-limit toasts. It is never planned, even in auto mode.
+func (cli *CLI) sendMessage(message string) (string, error) {
+	return cli.send(message, false)
+}
+
+// sendTyped sends a message the user typed, which auto mode plans
+func (cli *CLI) sendTyped(message string) (string, error) {
+	return cli.send(message, true)
+}
+
+// send sends a message through the agent; typed says whether the user wrote
+// it, which lets auto mode plan it
+func (cli *CLI) send(message string, typed bool) (string, error) {
+	if cli.agent != nil && cli.agent.ShouldRotateChat(message) {
+		cli.rotateChat()
+	}
+
+	// The agent processes messages according to its mode and prompt pipeline
+	send := cli.chatgpt.SendMessage
+	planned := false
+	if cli.agent != nil {
+		send = cli.agent.ProcessPrompt
+		if typed {
+			send = cli.agent.ProcessMessage
+			planned = cli.agent.GetMode() == agent.AutoMode
+		}
+	}
+
+	// A plan waits out rate limits per step, so it is never retried whole
+	retryAgent := cli.agent
+	if !planned {
+		retryAgent = nil
+	}
+
+	started := time.Now()
+	response, err := retryOnRateLimit(cli.config.ChatGPT.RateLimit, retryAgent, func() (string, error) {
+		// Auto mode prints its plan and progress, and asks before running
+		if planned {
+			return send(message)
+		}
+		spinner := ui.NewSpinner()
+		spinner.Start("")
+		defer spinner.Stop()
+		return send(message)
+	})
+
+	if err == nil {
+		cli.lastResponse = response
+		if cli.agent != nil && cli.agent.LastAnswerCached() {
+			cli.sessionStats.CacheHits++
+		} else {
+			cli.sessionStats.record(response, time.Since(started))
+		}
+	}
+
+	notify := cli.config.UI.Notify
+	if err == nil && notify.OnComplete {
+		if elapsed := time.Since(started); elapsed >= time.Duration(notify.Threshold)*time.Second {
+			ui.NotifyComplete(elapsed, notify.Desktop)
+		}
+	}
+
+	return response, err
+}
+
+// handleCommand handles CLI commands
+func (cli *CLI) handleCommand(command string) error {
+	parts := strings.Fields(command)
+	if len(parts) == 0 {
+		return nil
+	}
+
+	cmd := parts[0]
+
+	switch cmd {
+	case "/help", "/h":
+		cli.printHelp()
+
+	case "/new", "/n":
+		spinner := ui.NewSquareSpinner()
+		spinner.Start("Starting new chat...")
+		err := cli.chatgpt.StartNewChat()
+		spinner.Stop()
+		
+		if err != nil {
+			return err
+		}
+		
+		ui.PrintSuccess("New chat started")
+		if cli.agent != nil {
+			cli.agent.ClearCache()
+		}
+		
+		// Auto-send system prompt with project context
+		return cli.sendSystemPromptForNewChat()
+
+	case "/branch", "/open-in-new":
+		if len(parts) < 2 {
+			fmt.Println("❌ Usage: /branch <new direction>")
+			return nil
+		}
+		return cli.branchChat(strings.TrimSpace(strings.TrimPrefix(command, cmd)))
+
+	case "/history", "/hist":
+		all, tag := false, ""
+		for i := 1; i < len(parts); i++ {
+			switch {
+			case parts[i] == "--all":
+				all = true
+			case parts[i] == "--tag" && i+1 < len(parts):
+				tag = parts[i+1]
+				i++
+			default:
+				fmt.Println("❌ Usage: /history [--all] [--tag <tag>]")
+				return nil
+			}
+		}
+		return cli.showHistory(all, tag)
+
+	case "/tag":
+		// Tags are single words so /history --tag can name them
+		if len(parts) != 2 {
+			fmt.Println("❌ Usage: /tag <tag> (one word, no spaces)")
+			return nil
+		}
+		return cli.tagChat(parts[1])
+
+	case "/tags":
+		return cli.showTags()
+
+	case "/search":
+		var keywords []string
+		limit := 0
+		for i := 1; i < len(parts); i++ {
+			if parts[i] == "-n" && i+1 < len(parts) {
+				n, err := strconv.Atoi(parts[i+1])
+				if err != nil || n <= 0 {
+					fmt.Println("❌ Usage: /search [-n <limit>] <keyword>")
+					return nil
+				}
+				limit = n
+				i++
+				continue
+			}
+			keywords = append(keywords, parts[i])
+		}
+		if len(keywords) == 0 {
+			fmt.Println("❌ Usage: /search [-n <limit>] <keyword>")
+			return nil
+		}
+		return cli.searchHistory(strings.Join(keywords, " "), limit)
+
+	case "/open", "/o":
+		if len(parts) < 2 {
+			fmt.Println("❌ Usage: /open <chat_id_or_number> | /open fav <number>")
+			return nil
+		}
+		if parts[1] == "fav" {
+			if len(parts) < 3 {
+				fmt.Println("❌ Usage: /open fav <number>")
+				return nil
+			}
+			return cli.openFavorite(parts[2])
+		}
+		return cli.openChat(parts[1])
+
+	case "/fav":
+		return cli.handleFavorite(parts[1:])
+
+	case "/favs", "/favorites":
+		return cli.showFavorites()
+
+	case "/delete":
+		return cli.deleteChat(parts[1:])
+
+	case "/rename", "/set-title", "/title":
+		title := strings.TrimSpace(strings.TrimPrefix(command, cmd))
+		if title == "" {
+			fmt.Printf("❌ Usage: %s <new title>\n", cmd)
+			return nil
+		}
+		spinner := ui.NewSquareSpinner()
+		spinner.Start("Renaming current chat...")
+		shown, err := cli.chatgpt.RenameActiveChat(title)
+		spinner.Stop()
+		if err != nil {
+			return err
+		}
+		ui.PrintSuccess(fmt.Sprintf("Chat renamed to: %s", shown))
+
+	case "/resume-topic", "/open-or-new":
+		topic := strings.Trim(strings.TrimSpace(strings.TrimPrefix(command, cmd)), `"'`)
+		if topic == "" {
+			fmt.Println("❌ Usage: /resume-topic <topic>")
+			return nil
+		}
+		return cli.resumeTopic(topic)
+
+	case "/compare-files-with-chat", "/reconcile":
+		if len(parts) < 3 {
+			fmt.Println("❌ Usage: /compare-files-with-chat <file1> <file2>")
+			return nil
+		}
+		return cli.reconcileFiles(parts[1], parts[2])
+
+	case "/gentests", "/generate-tests":
+		if len(parts) < 2 {
+			fmt.Println("❌ Usage: /gentests <file>")
+			return nil
+		}
+		return cli.generateTests(parts[1])
+
+	case "/tail", "/head":
+		if len(parts) < 2 {
+			fmt.Printf("❌ Usage: %s <file> [lines] [question]\n", cmd)
+			return nil
+		}
+		return cli.sendFileLines(cmd == "/tail", parts[1], parts[2:])
+
+	case "/file", "/f":
+		if len(parts) < 2 {
+			fmt.Println("❌ Usage: /file <path> [prompt]")
+			return nil
+		}
+		return cli.sendFile(parts[1], strings.Join(parts[2:], " "))
+
+	case "/exec":
+		shellCommand := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(command), cmd))
+		if shellCommand == "" {
+			fmt.Println("❌ Usage: /exec <shell command>")
+			return nil
+		}
+		return cli.execCommand(shellCommand)
+
+	case "/grep":
+		args := parts[1:]
+		caseSensitive := true
+		if len(args) > 0 && args[0] == "-i" {
+			caseSensitive = false
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			fmt.Println("❌ Usage: /grep [-i] <pattern>")
+			return nil
+		}
+		return cli.grepProject(strings.Join(args, " "), caseSensitive)
+
+	case "/diff":
+		if len(parts) < 2 {
+			fmt.Println("❌ Usage: /diff <file>")
+			return nil
+		}
+		return cli.showDiff(parts[1])
+
+	case "/apply":
+		return cli.writePending("")
+
+	case "/write", "/w":
+		path := ""
+		if len(parts) > 1 {
+			path = parts[1]
+		}
+		return cli.writePending(path)
+
+	case "/template", "/t":
+		return cli.useTemplate(parts[1:])
+
+	case "/chat-info", "/info":
+		return cli.showChatInfo()
+
+	case "/benchmark", "/bench":
+		return cli.runBenchmark(parts[1:])
+
+	case "/rerun-with-file", "/rerun":
+		return cli.rerunWithFiles(parts[1:])
+
+	case "/tabs":
+		return cli.showTabs()
+
+	case "/tab":
+		if len(parts) < 2 {
+			fmt.Println("❌ Usage: /tab <number>")
+			return nil
+		}
+		num, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid tab number: %s", parts[1])
+		}
+		tab, err := cli.chatgpt.SwitchTab(num)
+		if err != nil {
+			if errors.Is(err, errs.ErrNoSuchTab) {
+				ui.PrintInfo("Use /tabs to list the open tabs")
+			}
+			return err
+		}
+		ui.PrintSuccess(fmt.Sprintf("Switched to tab %d: %s", tab.Index, tab.Title))
+		if !tab.IsChatGPT {
+			ui.PrintWarning("This tab isn't a ChatGPT page - messages won't send until it is")
+		}
+
+	case "/mode":
+		return cli.handleMode(parts[1:])
+
+	case "/system":
+		if len(parts) < 2 {
+			fmt.Println("❌ Usage: /system <prompt> | /system show | /system clear")
+			return nil
+		}
+		return cli.handleSystemPrompt(strings.TrimSpace(strings.TrimPrefix(command, cmd)))
+
+	case "/save-code":
+		dir := "."
+		if len(parts) > 1 {
+			dir = parts[1]
+		}
+		return cli.saveCode(dir)
+
+	case "/models":
+		return cli.showModels(false)
+
+	case "/models-refresh":
+		return cli.showModels(true)
+
+	case "/model":
+		if len(parts) < 2 {
+			fmt.Println("❌ Usage: /model <name>")
+			return nil
+		}
+		return cli.switchModel(strings.Join(parts[1:], " "))
+
+	case "/usage", "/costs":
+		cli.showUsage()
+
+	case "/stats":
+		cli.showStats()
+
+	case "/project-report", "/stats-project":
+		return cli.projectReport()
+
+	case "/diffstat":
+		return cli.showDiffstat(len(parts) > 1 && parts[1] == "--reset")
+
+	case "/explain-error", "/explain":
+		return cli.explainError(strings.TrimSpace(strings.TrimPrefix(command, cmd)))
+
+	case "/diag":
+		return cli.showDiagnostics()
+
+	case "/selectors", "/raw-selectors":
+		return cli.showSelectors()
+
+	case "/where", "/open-config-dir":
+		cli.showWhere()
+
+	case "/status":
+		cli.showStatus()
+
+	case "/focus", "/open-in-browser":
+		if cli.chatgpt.IsHeadless() {
+			ui.PrintInfo("Browser is running headless - there is no window to focus")
+			return nil
+		}
+		if err := cli.chatgpt.FocusWindow(); err != nil {
+			return err
+		}
+		ui.PrintSuccess("Browser window focused - come back here when you're done")
+
+	case "/login":
+		return cli.login()
+
+	case "/copy-all", "/copy-conversation":
+		return cli.copyConversation()
+
+	case "/copy":
+		return cli.copyResponse(len(parts) > 1 && parts[1] == "code")
+
+	case "/screenshot":
+		if cli.isReadOnly() {
+			return agent.ErrReadOnly
+		}
+		path, err := cli.chatgpt.Screenshot(strings.Join(parts[1:], " "))
+		if err != nil {
+			return err
+		}
+		ui.PrintSuccess(fmt.Sprintf("Screenshot saved to %s", path))
+
+	case "/export":
+		filename := ""
+		if len(parts) > 1 {
+			filename = strings.Join(parts[1:], " ")
+		}
+		return cli.exportConversation(filename)
+
+	case "/read-chat", "/read":
+		if len(parts) < 2 {
+			fmt.Println("❌ Usage: /read-chat <chat_id_or_number>")
+			return nil
+		}
+		return cli.readChat(parts[1])
+
+	case "/quit", "/q", "/exit":
+		cli.saveSession()
+		ui.PrintSuccess("Goodbye!")
+		os.Exit(0)
+
+	case "/typing", "/toggle-typing":
+		return cli.setTyping(parts[1:])
+
+	case "/multiline":
+		cli.toggleMultiline()
+
+	case "/clear", "/cls":
+		ui.ClearScreen()
+
+	case "/cookies", "/c":
+		if len(parts) < 2 {
+			fmt.Println("❌ Usage: /cookies <validate|clean|status>")
+			return nil
+		}
+		return cli.handleCookies(parts[1])
+
+	default:
+		fmt.Printf("❌ Unknown command: %s\n", cmd)
+		fmt.Println("💡 Type /help for available commands")
+	}
+
+	return nil
+}
+
+// defaultPageSize is the /history page size when ui.page_size is unset
+const defaultPageSize = 10
+
+// pageSize returns the number of chats per /history page
+func (cli *CLI) pageSize() int {
+	if size := cli.config.UI.PageSize; size > 0 {
+		return size
+	}
+	return defaultPageSize
+}
+
+// numberedChat is a history chat with the number /open knows it by
+type numberedChat struct {
+	number int
+	chatgpt.ChatHistoryItem
+}
+
+// showHistory lists the sidebar chats a page at a time, with n/p/q to move
+// between pages. With a tag only the chats carrying it are listed, under
+// their history numbers; with all every chat is listed without paging.
+func (cli *CLI) showHistory(all bool, tag string) error {
+	tags, err := loadTags()
+	if err != nil {
+		ui.PrintWarning(err.Error())
+		tags = chatTags{}
+	}
+	size := cli.pageSize()
+
+	heading := "📜 Recent Chat History"
+	lastPage := 0 // unknown until a short page arrives
+	fetch := func(page int) ([]numberedChat, error) {
+		spinner := ui.NewSquareSpinner()
+		spinner.Start("Loading chat history...")
+		history, err := cli.chatgpt.GetChatHistory(page, size)
+		spinner.Stop()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get history: %v", err)
+		}
+		tags.apply(history)
+		chats := make([]numberedChat, len(history))
+		for i, item := range history {
+			chats[i] = numberedChat{(page-1)*size + i + 1, item}
+		}
+		return chats, nil
+	}
+
+	// A tag filter or --all needs the whole history, which is then paged here
+	if all || tag != "" {
+		spinner := ui.NewSquareSpinner()
+		spinner.Start("Loading chat history...")
+		history, err := cli.chatgpt.GetFullChatHistory(searchHistoryMax)
+		spinner.Stop()
+		if err != nil {
+			return fmt.Errorf("failed to get history: %v", err)
+		}
+		tags.apply(history)
+
+		var matching []numberedChat
+		for i, item := range history {
+			if tag == "" || tags.has(item.ID, tag) {
+				matching = append(matching, numberedChat{i + 1, item})
+			}
+		}
+		if len(matching) == 0 && tag != "" {
+			ui.PrintWarning(fmt.Sprintf("No chats are tagged %s", tag))
+			return nil
+		}
+		if tag != "" {
+			heading = "📜 Chats Tagged " + tag
+		}
+		if all {
+			size = max(len(matching), 1)
+		}
+		fetch = func(page int) ([]numberedChat, error) {
+			start := min((page-1)*size, len(matching))
+			return matching[start:min(start+size, len(matching))], nil
+		}
+		// The whole list is known, so a full last page needs no extra fetch
+		lastPage = max((len(matching)+size-1)/size, 1)
+	} else if limit := cli.config.History.DisplayLimit; limit > 0 {
+		// Paging stops at history.display_limit chats unless --all is given
+		pageChats := fetch
+		lastPage = (limit + size - 1) / size
+		fetch = func(page int) ([]numberedChat, error) {
+			chats, err := pageChats(page)
+			if err != nil || page < lastPage {
+				return chats, err
+			}
+			keep := 0
+			for keep < len(chats) && chats[keep].number <= limit {
+				keep++
+			}
+			if keep < len(chats) || len(chats) == size {
+				ui.PrintInfo(fmt.Sprintf("Showing the first %d chats; use /history --all for the rest", limit))
+			}
+			return chats[:keep], nil
+		}
+	}
+
+	if err := cli.pageHistory(heading, size, lastPage, fetch); err != nil {
+		return err
+	}
+	ui.PrintInfo("Use '/open <number>' or '/open <chat_id>' to open a chat")
+	return nil
+}
+
+// pageHistory shows the pages fetch returns, starting at the first, and reads
+// n, p or q to move to the next or previous page or stop. lastPage is the
+// number of pages when known up front, else 0; a page shorter than size is
+// then the last one.
+func (cli *CLI) pageHistory(heading string, size, lastPage int, fetch func(page int) ([]numberedChat, error)) error {
+	page := 1
+	for {
+		chats, err := fetch(page)
+		if err != nil {
+			return err
+		}
+		if len(chats) == 0 {
+			if page == 1 {
+				ui.PrintWarning("No chat history found")
+				return nil
+			}
+			// The previous page was exactly full
+			ui.PrintInfo("No more chats")
+			page--
+			lastPage = page
+		} else {
+			if len(chats) < size {
+				lastPage = page
+			}
+			printHistoryPage(heading, page, chats)
+		}
+
+		if page == 1 && lastPage == 1 {
+			return nil
+		}
+		for {
+			key, ok := cli.input.ReadKey("[n]ext page / [p]rev page / [q]uit: ")
+			switch {
+			case !ok || key == 'q':
+				return nil
+			case key == 'n' && page == lastPage:
+				ui.PrintInfo("This is the last page")
+				continue
+			case key == 'n':
+				page++
+			case key == 'p' && page == 1:
+				ui.PrintInfo("This is the first page")
+				continue
+			case key == 'p':
+				page--
+			default:
+				continue
+			}
+			break
+		}
+	}
+}
+
+// printHistoryPage prints one page of chats under their history numbers
+func printHistoryPage(heading string, page int, chats []numberedChat) {
+	fmt.Printf("\n%s (page %d):\n", heading, page)
+	ui.PrintSeparator()
+	for _, chat := range chats {
+		fmt.Printf("%d. %s\n", chat.number, chat.Title)
+		fmt.Printf("   ID: %s\n", chat.ID)
+		if len(chat.Tags) > 0 {
+			fmt.Printf("   Tags: %s\n", strings.Join(chat.Tags, ", "))
+		}
+		fmt.Println()
+	}
+}
+
+// searchHistoryMax caps how many sidebar chats /search reads
+const searchHistoryMax = 1000
+
+// searchHistory lists the chats, from the whole sidebar, whose title contains
+// keyword, ignoring case. With limit set at most that many are shown.
+func (cli *CLI) searchHistory(keyword string, limit int) error {
+	spinner := ui.NewSquareSpinner()
+	spinner.Start("Searching chat history...")
+	history, err := cli.chatgpt.GetFullChatHistory(searchHistoryMax)
+	spinner.Stop()
+	if err != nil {
+		return fmt.Errorf("failed to get history: %v", err)
+	}
+
+	// Hits keep their history numbers, which is what /open <number> takes
+	needle := strings.ToLower(keyword)
+	var matches []numberedChat
+	for i, item := range history {
+		if strings.Contains(strings.ToLower(item.Title), needle) {
+			matches = append(matches, numberedChat{i + 1, item})
+		}
+	}
+	if len(matches) == 0 {
+		ui.PrintWarning(fmt.Sprintf("No chats match \"%s\" (searched %d)", keyword, len(history)))
+		return nil
+	}
+
+	fmt.Printf("\n🔍 Chats matching \"%s\":\n", keyword)
+	ui.PrintSeparator()
+
+	shown := matches
+	if limit > 0 && len(matches) > limit {
+		shown = matches[:limit]
+	}
+	for _, chat := range shown {
+		fmt.Printf("%d. %s\n", chat.number, chat.Title)
+		fmt.Printf("   ID: %s\n", chat.ID)
+		fmt.Println()
+	}
+	if hidden := len(matches) - len(shown); hidden > 0 {
+		fmt.Printf("…%d more, raise -n to see them\n\n", hidden)
+	}
+
+	ui.PrintInfo("Use '/open <number>' or '/open <chat_id>' to open a chat")
+	return nil
+}
+
+// topicMatchThreshold is the share of topic words a chat title must contain
+const topicMatchThreshold = 0.6
+
+// resumeTopic opens the history chat whose title best matches topic, or starts
+// a new chat about it and names it after the topic
+func (cli *CLI) resumeTopic(topic string) error {
+	spinner := ui.NewSquareSpinner()
+	spinner.Start("Searching history...")
+	history, err := cli.chatgpt.GetChatHistory(1, cli.pageSize())
+	spinner.Stop()
+	if err != nil {
+		return fmt.Errorf("failed to get history: %v", err)
+	}
+
+	if index, ok := matchChatTitle(history, topic); ok {
+		chat := history[index]
+		ui.PrintInfo(fmt.Sprintf("Resuming existing chat: %s", chat.Title))
+		return cli.chatgpt.OpenChat(chat.ID)
+	}
+
+	ui.PrintInfo(fmt.Sprintf("No chat matches \"%s\" - starting a new one", topic))
+	if err := cli.chatgpt.StartNewChat(); err != nil {
+		return err
+	}
+	if err := cli.sendSystemPromptForNewChat(); err != nil {
+		return err
+	}
+
+	response, err := cli.sendMessage(fmt.Sprintf("Let's start working on: %s", topic))
+	if err != nil {
+		return err
+	}
+	cli.printResponse(response)
+
+	if _, err := cli.chatgpt.RenameActiveChat(topic); err != nil {
+		ui.PrintWarning(fmt.Sprintf("Could not name the chat: %v", err))
+		return nil
+	}
+	ui.PrintSuccess(fmt.Sprintf("New chat named: %s", topic))
+	return nil
+}
+
+// matchChatTitle returns the index of the chat whose title best matches topic.
+// A title containing the whole topic wins; otherwise the title must contain
+// enough of the topic's words, where "refactor" also matches "refactoring".
+func matchChatTitle(history []chatgpt.ChatHistoryItem, topic string) (int, bool) {
+	topic = strings.ToLower(topic)
+	words := strings.Fields(topic)
+	if len(words) == 0 {
+		return -1, false
+	}
+
+	best, bestScore := -1, 0.0
+	for i, chat := range history {
+		title := strings.ToLower(chat.Title)
+		score := 0.0
+		if strings.Contains(title, topic) {
+			score = 1.0
+		} else {
+			titleWords := strings.Fields(title)
+			matched := 0
+			for _, word := range words {
+				for _, tw := range titleWords {
+					if strings.HasPrefix(tw, word) || (len(tw) >= 4 && strings.HasPrefix(word, tw)) {
+						matched++
+						break
+					}
+				}
+			}
+			score = float64(matched) / float64(len(words))
+		}
+
+		// History is newest first, so ties keep the most recent chat
+		if score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+
+	return best, bestScore >= topicMatchThreshold
+}
+
+// reconcileFiles asks ChatGPT to merge two files and offers the result to /write
+func (cli *CLI) reconcileFiles(first, second string) error {
+	if cli.agent == nil {
+		return fmt.Errorf("agent system not available")
+	}
+
+	spinner := ui.NewSquareSpinner()
+	spinner.Start(fmt.Sprintf("Reconciling %s and %s...", first, second))
+	response, merged, err := cli.agent.ReconcileFiles(first, second)
+	spinner.Stop()
+	if err != nil {
+		return err
+	}
+
+	cli.printResponse(response)
+
+	if merged == "" {
+		ui.PrintWarning("No merged file found in the response")
+		return nil
+	}
+
+	cli.pendingWrite = &pendingWrite{path: first, content: merged}
+	ui.PrintInfo(fmt.Sprintf("Use /write to save the merged file to %s, or /write <path>", first))
+	return nil
+}
+
+// generateTests asks ChatGPT for tests of a file and offers them to /write
+func (cli *CLI) generateTests(filename string) error {
+	if cli.agent == nil {
+		return fmt.Errorf("agent system not available")
+	}
+
+	spinner := ui.NewSquareSpinner()
+	spinner.Start(fmt.Sprintf("Generating tests for %s...", filename))
+	response, tests, testPath, err := cli.agent.GenerateTests(filename)
+	spinner.Stop()
+	if err != nil {
+		return err
+	}
+
+	cli.printResponse(response)
+
+	if tests == "" {
+		ui.PrintWarning("No test code found in the response")
+		return nil
+	}
+
+	cli.pendingWrite = &pendingWrite{path: testPath, content: tests}
+	ui.PrintInfo(fmt.Sprintf("Use /write to save the tests to %s, or /write <path>", testPath))
+	return nil
+}
+
+// defaultLogLines is how many lines /tail and /head send when no count is given
+const defaultLogLines = 100
+
+// sendFileLines sends the first or last lines of a (possibly huge) file to
+// ChatGPT, followed by an optional question
+func (cli *CLI) sendFileLines(tail bool, filename string, args []string) error {
+	if cli.agent == nil {
+		return fmt.Errorf("agent system not available")
+	}
+
+	lines := defaultLogLines
+	if len(args) > 0 {
+		if n, err := strconv.Atoi(args[0]); err == nil && n > 0 {
+			lines = n
+			args = args[1:]
+		}
+	}
+
+	var content, position string
+	var err error
+	if tail {
+		content, err = cli.agent.TailFile(filename, lines)
+		position = "last"
+	} else {
+		content, err = cli.agent.HeadFile(filename, lines)
+		position = "first"
+	}
+	if err != nil {
+		return err
+	}
+
+	question := strings.Join(args, " ")
+	if question == "" {
+		question = "Please point out any errors or warnings and what is likely causing them."
+	}
+
+	prompt := fmt.Sprintf("Here are the %s %d lines of %s:\n\n```\n%s\n```\n\n%s", position, lines, filename, content, question)
+	response, err := cli.sendMessage(prompt)
+	if err != nil {
+		return fmt.Errorf("error sending message: %v", err)
+	}
+	cli.printResponse(response)
+	return nil
+}
+
+// sendFile sends a project file in a code block after a prompt. A file over
+// the size limit can be sent as its first and last agent.file_excerpt_lines lines.
+func (cli *CLI) sendFile(filename, prompt string) error {
+	if cli.agent == nil {
+		return fmt.Errorf("agent system not available")
+	}
+	if prompt == "" {
+		prompt = "Please review this file"
+	}
+
+	content, err := cli.agent.ReadFile(filename)
+	if errors.Is(err, agent.ErrFileTooLarge) {
+		lines := cli.config.Agent.FileExcerptLines
+		if lines <= 0 {
+			lines = 200
+		}
+		if !cli.confirm(fmt.Sprintf("⚠️  %s is too large to send whole. Send its first and last %d lines?", filename, lines)) {
+			return nil
+		}
+		content, err = cli.agent.ExcerptFile(filename, lines)
+	}
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("%s\n\n%s:\n```\n%s\n```", prompt, filename, strings.TrimRight(content, "\n"))
+	response, err := cli.sendMessage(message)
+	if err != nil {
+		return fmt.Errorf("error sending message: %v", err)
+	}
+	cli.printResponse(response)
+	return nil
+}
+
+// execCommand runs a shell command in the project directory and sends its
+// output to ChatGPT
+func (cli *CLI) execCommand(command string) error {
+	if cli.agent == nil {
+		return fmt.Errorf("agent system not available")
+	}
+
+	spinner := ui.NewSquareSpinner()
+	spinner.Start("Running " + command + "...")
+	output, err := cli.agent.RunCommand(command)
+	spinner.Stop()
+	if err != nil {
+		return err
+	}
+
+	prompt := fmt.Sprintf("Here is the output of running '%s':\n\n```\n%s\n```", command, strings.TrimRight(output, "\n"))
+	response, err := cli.sendMessage(prompt)
+	if err != nil {
+		return fmt.Errorf("error sending message: %v", err)
+	}
+	cli.printResponse(response)
+	return nil
+}
+
+// grepProject searches project files for pattern, prints the matching lines
+// grouped by file and sends them to ChatGPT for a summary
+func (cli *CLI) grepProject(pattern string, caseSensitive bool) error {
+	if cli.agent == nil {
+		return fmt.Errorf("agent system not available")
+	}
+
+	spinner := ui.NewSquareSpinner()
+	spinner.Start("Searching for " + pattern + "...")
+	matches, err := cli.agent.SearchContent(pattern, caseSensitive)
+	spinner.Stop()
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		ui.PrintInfo(fmt.Sprintf("No lines match %q", pattern))
+		return nil
+	}
+
+	var listing strings.Builder
+	files := 0
+	for i, match := range matches {
+		if i == 0 || match.File != matches[i-1].File {
+			if i > 0 {
+				listing.WriteString("\n")
+			}
+			listing.WriteString(match.File + ":\n")
+			files++
+		}
+		listing.WriteString(fmt.Sprintf("  %d: %s\n", match.Line, match.Text))
+	}
+
+	fmt.Printf("\n🔎 %d matches in %d files:\n\n%s", len(matches), files, listing.String())
+	if limit := cli.config.Agent.MaxContentMatches; limit > 0 && len(matches) >= limit {
+		ui.PrintWarning(fmt.Sprintf("Stopped at %d matches (agent.max_content_matches)", limit))
+	}
+
+	prompt := fmt.Sprintf("Here are the lines in my project matching '%s':\n\n```\n%s```\n\nSummarize where and how this is used across the files.", pattern, listing.String())
+	response, err := cli.sendMessage(prompt)
+	if err != nil {
+		return fmt.Errorf("error sending message: %v", err)
+	}
+	cli.printResponse(response)
+	return nil
+}
+
+// saveCode writes every code block of the last answer into dir, asking before
+// overwriting existing files
+func (cli *CLI) saveCode(dir string) error {
+	if cli.agent == nil {
+		return fmt.Errorf("agent system not available")
+	}
+	if cli.isReadOnly() {
+		return agent.ErrReadOnly
+	}
+
+	files := agent.NameCodeBlocks(agent.ExtractCodeBlocks(cli.lastResponse))
+	if len(files) == 0 {
+		ui.PrintWarning("No code blocks in the last response")
+		return nil
+	}
+
+	fmt.Printf("\n📦 %d code blocks:\n", len(files))
+	for _, file := range files {
+		fmt.Printf("  %s (%d lines)\n", filepath.Join(dir, file.Path), strings.Count(file.Code, "\n")+1)
+	}
+	if !cli.confirm(fmt.Sprintf("Write %d files to %s?", len(files), dir)) {
+		ui.PrintInfo("Save cancelled")
+		return nil
+	}
+
+	written := 0
+	for _, file := range files {
+		path := filepath.Join(dir, file.Path)
+		if cli.agent.FileExists(path) && !cli.confirm(fmt.Sprintf("%s exists. Overwrite?", path)) {
+			ui.PrintInfo(fmt.Sprintf("Skipped %s", path))
+			continue
+		}
+		if err := cli.agent.WriteFile(path, file.Code+"\n"); err != nil {
+			ui.PrintError(fmt.Sprintf("Failed to write %s: %v", path, err))
+			continue
+		}
+		written++
+	}
+
+	ui.PrintSuccess(fmt.Sprintf("Wrote %d of %d files", written, len(files)))
+	if written > 0 {
+		return cli.showDiffstat(false)
+	}
+	return nil
+}
+
+// showDiffstat prints a git-style summary of every file written since the
+// session started or the last reset
+func (cli *CLI) showDiffstat(reset bool) error {
+	if cli.agent == nil {
+		return fmt.Errorf("agent system not available")
+	}
+
+	changes := cli.agent.Changes()
+	if len(changes) == 0 {
+		ui.PrintInfo("No files changed")
+	} else {
+		fmt.Println("\n📝 Changes this run:")
+		fmt.Println(agent.Diffstat(changes))
+	}
+
+	if reset {
+		cli.agent.ResetChanges()
+		ui.PrintInfo("Change tracking reset")
+	}
+	return nil
+}
+
+// writePending writes the pending generated content after confirmation
+func (cli *CLI) writePending(path string) error {
+	if cli.pendingWrite == nil {
+		ui.PrintWarning("Nothing to write yet")
+		return nil
+	}
+	if cli.agent == nil {
+		return fmt.Errorf("agent system not available")
+	}
+	if cli.isReadOnly() {
+		return agent.ErrReadOnly
+	}
+	if path == "" {
+		path = cli.pendingWrite.path
+	}
+
+	lineCount := strings.Count(cli.pendingWrite.content, "\n") + 1
+	if !cli.confirm(fmt.Sprintf("Write %d lines to %s?", lineCount, path)) {
+		ui.PrintInfo("Write cancelled")
+		return nil
+	}
+
+	if err := cli.agent.WriteFile(path, cli.pendingWrite.content); err != nil {
+		return err
+	}
+
+	cli.pendingWrite = nil
+	ui.PrintSuccess(fmt.Sprintf("Wrote %s", path))
+	return nil
+}
+
+// confirm asks a yes/no question on the input scanner, defaulting to no
+func (cli *CLI) confirm(question string) bool {
+	return ui.Confirm(question)
+}
+
+// useTemplate fills a prompt template and sends it, or lists templates
+func (cli *CLI) useTemplate(args []string) error {
+	templates, err := config.GetTemplates()
+	if err != nil {
+		ui.PrintWarning("Could not load templates, using defaults")
+	}
+
+	if len(args) == 0 || args[0] == "list" {
+		fmt.Println("\n📝 Prompt Templates:")
+		ui.PrintSeparator()
+		for _, name := range templates.Names() {
+			fmt.Printf("%s\n", name)
+			fmt.Printf("   %s\n", templates[name])
+		}
+		ui.PrintSeparator()
+		ui.PrintInfo("Use '/t <name> <args...>' to fill and send a template")
+		return nil
+	}
+
+	prompt, err := templates.Fill(args[0], args[1:])
+	if err != nil {
+		return err
+	}
+
+	response, err := cli.sendMessage(prompt)
+	if err != nil {
+		return fmt.Errorf("error sending message: %v", err)
+	}
+	cli.printResponse(response)
+	return nil
+}
+
+// rerunWithFiles re-sends the previous prompt with @-mentions of the given
+// files appended, so the prompt pipeline injects their current content
+func (cli *CLI) rerunWithFiles(files []string) error {
+	if cli.lastSent == "" {
+		return fmt.Errorf("no previous prompt to rerun")
+	}
+	if len(files) == 0 {
+		fmt.Println("❌ Usage: /rerun-with-file @<file> [@<file>...]")
+		return nil
+	}
+
+	if cli.agent == nil {
+		return fmt.Errorf("agent system not available")
+	}
+
+	// A mention that doesn't expand would be sent as a bare @path
+	mentions := make([]string, len(files))
+	for i, file := range files {
+		path := strings.TrimPrefix(file, "@")
+		if err := cli.agent.CheckMention(path); err != nil {
+			return fmt.Errorf("cannot include %s: %v", path, err)
+		}
+		mentions[i] = "@" + path
+	}
+	prompt := cli.lastSent + "\n\nUpdated files: " + strings.Join(mentions, " ")
+
+	fmt.Println(ui.Dim + "↻ Rerunning: " + cli.lastSent + ui.Reset)
+	response, err := cli.sendMessage(prompt)
+	if err != nil {
+		return err
+	}
+
+	cli.printResponse(response)
+	return nil
+}
+
+// showTabs lists the open browser tabs
+func (cli *CLI) showTabs() error {
+	tabs, err := cli.chatgpt.Tabs()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("\n🗂️  Open tabs (%d):\n", len(tabs))
+	for _, tab := range tabs {
+		marker := "  "
+		if tab.Active {
+			marker = "▶ "
+		}
+		title := tab.Title
+		if title == "" {
+			title = "(untitled)"
+		}
+		fmt.Printf("%s%d. %s\n", marker, tab.Index, title)
+		fmt.Printf("     %s%s%s\n", ui.Dim, tab.URL, ui.Reset)
+	}
+	ui.PrintInfo("Use /tab <number> to switch")
+	return nil
+}
+
+// usageWindow is the rolling window ChatGPT plans apply message caps over
+const usageWindow = 3 * time.Hour
+
+// showUsage prints local estimates of what this session has used
+func (cli *CLI) showUsage() {
+	usage := cli.chatgpt.Usage()
+	elapsed := time.Since(usage.Started)
+
+	fmt.Println("\n📈 Session Usage (local estimate):")
+	ui.PrintSeparator()
+	fmt.Printf("💬 Messages sent: %d (%d in the last %s)\n", usage.Messages, usage.SentSince(usageWindow), usageWindow)
+	fmt.Printf("📤 Prompt characters: %d (~%d tokens)\n", usage.PromptChars, (usage.PromptChars+3)/4)
+	fmt.Printf("📥 Response characters: %d (~%d tokens)\n", usage.ResponseChars, (usage.ResponseChars+3)/4)
+	fmt.Printf("⏱️  Session duration: %s\n", elapsed.Round(time.Second))
+	if usage.Messages > 0 && elapsed >= time.Minute {
+		fmt.Printf("📊 Rate: %.1f messages/hour\n", float64(usage.Messages)/elapsed.Hours())
+	}
+	ui.PrintSeparator()
+}
+
+// handleMode shows the agent modes, or switches to the named one
+func (cli *CLI) handleMode(args []string) error {
+	if cli.agent == nil {
+		return fmt.Errorf("agent system not available")
+	}
+
+	if len(args) == 0 {
+		current := cli.agent.GetMode()
+		fmt.Printf("\n🤖 Current mode: %s\n\nAvailable modes:\n", current)
+		for _, m := range agent.Modes {
+			marker := "  "
+			if m.Mode == current {
+				marker = "▶ "
+			}
+			fmt.Printf("%s%-12s - %s\n", marker, m.Mode, m.Description)
+		}
+		ui.PrintInfo("Use /mode <name> to switch")
+		return nil
+	}
+
+	mode, ok := agent.ParseMode(args[0])
+	if !ok {
+		return fmt.Errorf("unknown mode: %s (use /mode to list modes)", args[0])
+	}
+	cli.agent.SetMode(mode)
+	return nil
+}
+
+// handleSystemPrompt sets, shows or clears the system prompt put before every message
+func (cli *CLI) handleSystemPrompt(arg string) error {
+	if cli.agent == nil {
+		return fmt.Errorf("agent system not available")
+	}
+
+	switch arg {
+	case "show":
+		if prompt := cli.agent.SystemPrompt(); prompt != "" {
+			fmt.Printf("\n🧭 System prompt: %s\n\n", prompt)
+		} else {
+			ui.PrintInfo("No system prompt set - use /system <prompt> to set one")
+		}
+	case "clear":
+		cli.agent.SetSystemPrompt("")
+		ui.PrintSuccess("System prompt cleared")
+	default:
+		cli.agent.SetSystemPrompt(arg)
+		ui.PrintSuccess("System prompt set - it goes before every message until /system clear")
+	}
+	return nil
+}
+
+// showModels lists the models in the picker, re-scraping it when refresh is set
+func (cli *CLI) showModels(refresh bool) error {
+	spinner := ui.NewSquareSpinner()
+	spinner.Start("Reading model picker...")
+	models, err := cli.chatgpt.Models(refresh)
+	spinner.Stop()
+	if err != nil {
+		if errors.Is(err, errs.ErrSelectorNotFound) {
+			ui.PrintInfo("The model picker may have moved - update model_picker/model_option in configs/selectors.json")
+		}
+		return err
+	}
+
+	fmt.Printf("\n🧠 Available models (%d):\n", len(models))
+	for i, model := range models {
+		fmt.Printf("  %d. %s\n", i+1, model)
+	}
+	return nil
+}
+
+// selectModel switches the model through the picker
+func (cli *CLI) selectModel(name string) error {
+	spinner := ui.NewSquareSpinner()
+	spinner.Start("Switching model...")
+	err := cli.chatgpt.SetModel(name)
+	spinner.Stop()
 	if err != nil {
-		// Continue without agent if creation fails
-		ui.PrintWarning("Could not initialize agent system")
-		agentInstance = nil
+		if errors.Is(err, errs.ErrSelectorNotFound) {
+			ui.PrintInfo("The model picker may have moved - update model_picker/model_option in configs/selectors.json")
+		}
+		return err
 	}
-	
-	return &CLI{
-		chatgpt: chatgptClient,
-		scanner: bufio.NewScanner(os.Stdin),
-		agent:   agentInstance,
-		config:  config,
+	ui.PrintSuccess("Switched to " + name)
+	return nil
+}
+
+// switchModel selects a model and saves it as chatgpt.default_model, so
+// later sessions start on it
+func (cli *CLI) switchModel(name string) error {
+	if err := cli.selectModel(name); err != nil {
+		return err
 	}
+	cli.config.ChatGPT.DefaultModel = name
+	if err := cli.config.SaveConfig(); err != nil {
+		ui.PrintWarning(fmt.Sprintf("Using %s for this session, but it could not be saved as the default: %v", name, err))
+	}
+	return nil
 }
 
-// Start starts the CLI interface
-func (cli *CLI) Start() error {
-	cli.printWelcome()
-	
-	// Auto-send system prompt for initial context
-	if err := cli.sendSystemPromptForNewChat(); err != nil {
-		ui.PrintWarning("Could not establish initial project context")
+// applyDefaultModel switches to chatgpt.default_model, if one is set, for
+// runs that send without the interactive welcome. Problems go to stderr so
+// stdout stays the responses.
+func (cli *CLI) applyDefaultModel() {
+	model := cli.config.ChatGPT.DefaultModel
+	if model == "" {
+		return
+	}
+	if err := cli.chatgpt.SetModel(model); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not switch to the default model %s: %v\n", model, err)
 	}
+}
 
-	for {
-		fmt.Print("\n> ")
+// showChatInfo shows metadata about the open chat
+func (cli *CLI) showChatInfo() error {
+	info, err := cli.chatgpt.GetChatInfo()
+	if err != nil {
+		return err
+	}
 
-		if !cli.scanner.Scan() {
-			break
-		}
+	title := info.Title
+	if title == "" {
+		title = "(untitled)"
+	}
 
-		input := strings.TrimSpace(cli.scanner.Text())
-		if input == "" {
-			continue
+	fmt.Println("\n💬 Chat Info:")
+	ui.PrintSeparator()
+	fmt.Printf("Title:   %s\n", title)
+	fmt.Printf("ID:      %s\n", info.ID)
+	fmt.Printf("URL:     %s\n", info.URL)
+	if info.Model != "" {
+		fmt.Printf("Model:   %s\n", info.Model)
+	}
+	fmt.Printf("Turns:   %d (%d sent this session)\n", info.Turns, info.SentByUs)
+	ui.PrintSeparator()
+	return nil
+}
+
+// showStatus shows the active chat and agent state
+func (cli *CLI) showStatus() {
+	fmt.Println("\n📊 Session Status:")
+	ui.PrintSeparator()
+
+	if active, err := cli.chatgpt.ActiveChat(); err != nil {
+		fmt.Println("💬 Chat: new (not saved yet)")
+	} else {
+		title := active.Title
+		if title == "" {
+			title = "(untitled)"
 		}
+		fmt.Printf("💬 Chat: %s\n", title)
+		fmt.Printf("   ID: %s\n", active.ID)
+	}
 
-		// Handle commands
-		if strings.HasPrefix(input, "/") {
-			if err := cli.handleCommand(input); err != nil {
-				ui.PrintError(fmt.Sprintf("Error: %v", err))
-			}
-			continue
+	if cli.agent != nil {
+		fmt.Printf("🤖 Agent mode: %s\n", cli.agent.GetMode())
+		if cli.agent.IsReadOnly() {
+			fmt.Println("🔒 Safe mode: read-only, file writes are disabled")
 		}
+	}
+	ui.PrintSeparator()
+}
 
-		// Send message to ChatGPT with spinner
-		spinner := ui.NewSpinner()
-		spinner.Start("")
+// openChat opens a specific chat
+func (cli *CLI) openChat(identifier string) error {
+	chatID, title, err := cli.resolveChat(identifier)
+	if err != nil {
+		return err
+	}
 
-		response, err := cli.chatgpt.SendMessage(input)
-		spinner.Stop()
+	if title != "" {
+		fmt.Printf("📂 Opening chat: %s\n", title)
+	} else {
+		fmt.Printf("📂 Opening chat ID: %s\n", chatID)
+	}
+	return cli.chatgpt.OpenChat(chatID)
+}
+
+// deleteChat deletes the chat named by a history number or chat ID, or the
+// current chat, after a confirmation that --yes skips.
+// Usage: /delete [<id_or_number>] [--yes]
+func (cli *CLI) deleteChat(args []string) error {
+	skipConfirm := false
+	var identifier string
+	for _, arg := range args {
+		switch {
+		case arg == "--yes" || arg == "-y":
+			skipConfirm = true
+		case identifier == "":
+			identifier = arg
+		default:
+			fmt.Println("❌ Usage: /delete [<chat_id_or_number>] [--yes]")
+			return nil
+		}
+	}
 
+	var chatID, title string
+	if identifier == "" {
+		active, err := cli.chatgpt.ActiveChat()
 		if err != nil {
-			ui.PrintError(fmt.Sprintf("Error sending message: %v", err))
-			continue
+			return err
+		}
+		chatID, title = active.ID, active.Title
+	} else {
+		var err error
+		if chatID, title, err = cli.resolveChat(identifier); err != nil {
+			return err
 		}
+	}
 
-		cli.printResponse(response)
+	name := chatID
+	if title != "" {
+		name = fmt.Sprintf("%q", title)
+	}
+	if !skipConfirm && !cli.confirm(fmt.Sprintf("Delete chat %s? This cannot be undone.", name)) {
+		ui.PrintInfo("Delete cancelled")
+		return nil
 	}
 
+	spinner := ui.NewSquareSpinner()
+	spinner.Start("Deleting chat...")
+	err := cli.chatgpt.DeleteChat(chatID)
+	spinner.Stop()
+	if err != nil {
+		return err
+	}
+	ui.PrintSuccess(fmt.Sprintf("Deleted chat %s", name))
 	return nil
 }
 
-// handleCommand handles CLI commands
-func (cli *CLI) handleCommand(command string) error {
-	parts := strings.Fields(command)
-	if len(parts) == 0 {
-		return nil
+// resolveChat turns a history number or chat ID into a chat ID and, for
+// history numbers, the chat's title
+func (cli *CLI) resolveChat(identifier string) (string, string, error) {
+	// Check if it's a number (history index)
+	num, err := strconv.Atoi(identifier)
+	if err != nil {
+		// Otherwise treat as chat ID
+		return identifier, "", nil
 	}
 
-	cmd := parts[0]
+	if num < 1 {
+		return "", "", fmt.Errorf("invalid history number: %d", num)
+	}
 
-	switch cmd {
-	case "/help", "/h":
-		cli.printHelp()
+	// Get history up to that number and look it up by index. The whole
+	// sidebar is scrolled as needed, as /history --all and /search do, so
+	// their numbers resolve to the same chats.
+	history, err := cli.chatgpt.GetFullChatHistory(num)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get history: %v", err)
+	}
 
-	case "/new", "/n":
-		spinner := ui.NewSquareSpinner()
-		spinner.Start("Starting new chat...")
-		err := cli.chatgpt.StartNewChat()
-		spinner.Stop()
-		
-		if err != nil {
-			return err
+	if num < 1 || num > len(history) {
+		return "", "", fmt.Errorf("invalid history number: %d (available: 1-%d)", num, len(history))
+	}
+
+	return history[num-1].ID, history[num-1].Title, nil
+}
+
+// readChat prints a past conversation without switching the active chat
+func (cli *CLI) readChat(identifier string) error {
+	chatID, title, err := cli.resolveChat(identifier)
+	if err != nil {
+		return err
+	}
+	if title == "" {
+		title = chatID
+	}
+
+	spinner := ui.NewSquareSpinner()
+	spinner.Start(fmt.Sprintf("Reading chat: %s...", title))
+	turns, err := cli.chatgpt.ReadConversation(chatID)
+	spinner.Stop()
+	if err != nil {
+		return err
+	}
+
+	if len(turns) == 0 {
+		ui.PrintWarning("No messages found in this chat")
+		return nil
+	}
+
+	fmt.Printf("\n📖 %s (%d messages, read-only)\n", title, len(turns))
+	for _, turn := range turns {
+		if turn.Role == "assistant" {
+			cli.printResponse(turn.Text)
+			continue
 		}
-		
-		ui.PrintSuccess("New chat started")
-		
-		// Auto-send system prompt with project context
-		return cli.sendSystemPromptForNewChat()
+		fmt.Println("\n" + ui.Cyan + ui.Bold + "You:" + ui.Reset)
+		fmt.Println(turn.Text)
+	}
 
-	case "/history", "/hist":
-		return cli.showHistory()
+	ui.PrintInfo("Your active chat is unchanged - use /open to continue this one")
+	return nil
+}
 
-	case "/open", "/o":
-		if len(parts) < 2 {
-			fmt.Println("❌ Usage: /open <chat_id_or_number>")
+// branchChat continues the current conversation in a new chat, in a new
+// direction, and switches to it. The original chat is not modified.
+func (cli *CLI) branchChat(direction string) error {
+	if cli.agent == nil {
+		return fmt.Errorf("branching needs the agent, which failed to initialize")
+	}
+
+	origin := "the current chat"
+	if active, err := cli.chatgpt.ActiveChat(); err == nil && active.Title != "" {
+		origin = active.Title
+	}
+
+	spinner := ui.NewSquareSpinner()
+	spinner.Start("Branching into a new chat...")
+	response, err := cli.agent.BranchChat(direction)
+	spinner.Stop()
+	if err != nil {
+		return err
+	}
+
+	cli.lastResponse = response
+	fmt.Println(ui.Dim + "⑂ Branched from " + origin + " - the original chat is unchanged" + ui.Reset)
+	cli.printResponse(response)
+	return nil
+}
+
+// projectReport sends a structured report of the project and prints
+// ChatGPT's architectural assessment of it
+func (cli *CLI) projectReport() error {
+	if cli.agent == nil {
+		return fmt.Errorf("the project report needs the agent, which failed to initialize")
+	}
+
+	spinner := ui.NewSquareSpinner()
+	spinner.Start("Building project report and asking for an assessment...")
+	response, err := cli.agent.SendProjectReport()
+	spinner.Stop()
+	if err != nil {
+		return err
+	}
+
+	cli.lastResponse = response
+	cli.printResponse(response)
+	return nil
+}
+
+// setTyping turns the response typing effect on or off and saves the choice.
+// Without an argument it toggles. Usage: /typing [on|off]
+func (cli *CLI) setTyping(args []string) error {
+	enabled := !ui.TypingEnabled()
+	if len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "on":
+			enabled = true
+		case "off":
+			enabled = false
+		default:
+			fmt.Println("❌ Usage: /typing [on|off]")
 			return nil
 		}
-		return cli.openChat(parts[1])
+	}
 
-	case "/quit", "/q", "/exit":
-		ui.PrintSuccess("Goodbye!")
-		os.Exit(0)
+	ui.SetTyping(enabled)
+	cli.config.UI.TypingEffect = enabled
 
-	case "/clear", "/cls":
-		ui.ClearScreen()
+	state := "off"
+	if enabled {
+		state = "on"
+	}
+	if err := cli.config.SaveConfig(); err != nil {
+		ui.PrintWarning(fmt.Sprintf("Typing effect %s for this session, but it could not be saved: %v", state, err))
+		return nil
+	}
+	ui.PrintSuccess(fmt.Sprintf("Typing effect %s (saved)", state))
+	return nil
+}
 
-	case "/cookies", "/c":
-		if len(parts) < 2 {
-			fmt.Println("❌ Usage: /cookies <validate|clean|status>")
-			return nil
+// explainError sends an error or stack trace with the code it references.
+// Without an inline trace it reads a multi-line paste, ended by two empty
+// lines since traces themselves can contain single blank lines.
+func (cli *CLI) explainError(trace string) error {
+	if cli.agent == nil {
+		return fmt.Errorf("agent system not available")
+	}
+
+	if trace == "" {
+		ui.PrintInfo("Paste the error or stack trace, then press Enter on two empty lines")
+		var lines []string
+		blank := 0
+		for blank < 2 {
+			line, ok := cli.input.ReadLine("")
+			if !ok {
+				break
+			}
+			if strings.TrimSpace(line) == "" {
+				blank++
+			} else {
+				blank = 0
+			}
+			lines = append(lines, line)
 		}
-		return cli.handleCookies(parts[1])
+		trace = strings.TrimSpace(strings.Join(lines, "\n"))
+	}
+	if trace == "" {
+		fmt.Println("❌ Usage: /explain-error [trace], or paste the trace after the command")
+		return nil
+	}
 
-	default:
-		fmt.Printf("❌ Unknown command: %s\n", cmd)
-		fmt.Println("💡 Type /help for available commands")
+	if refs := agent.ParseTraceRefs(trace); len(refs) > 0 {
+		fmt.Printf(ui.Dim+"Found %d file references in the trace"+ui.Reset+"\n", len(refs))
+	}
+
+	spinner := ui.NewSpinner()
+	spinner.Start("")
+	response, err := cli.agent.ExplainError(trace)
+	spinner.Stop()
+	if err != nil {
+		return err
 	}
 
+	cli.lastResponse = response
+	cli.printResponse(response)
 	return nil
 }
 
-// showHistory shows chat history
-func (cli *CLI) showHistory() error {
-	spinner := ui.NewSquareSpinner()
-	spinner.Start("Loading chat history...")
+// copyWarnSize is the transcript size, in bytes, above which /copy-all asks first
+const copyWarnSize = 200 * 1024
 
-	history, err := cli.chatgpt.GetChatHistory()
+// copyConversation puts the whole current chat onto the clipboard as Markdown
+func (cli *CLI) copyConversation() error {
+	spinner := ui.NewSquareSpinner()
+	spinner.Start("Reading conversation...")
+	turns, err := cli.chatgpt.CurrentConversation()
 	spinner.Stop()
-
 	if err != nil {
-		return fmt.Errorf("failed to get history: %v", err)
+		return err
 	}
-
-	if len(history) == 0 {
-		ui.PrintWarning("No chat history found")
+	if len(turns) == 0 {
+		ui.PrintWarning("No messages in the current chat")
 		return nil
 	}
 
-	fmt.Println("\n📜 Recent Chat History:")
-	ui.PrintSeparator()
+	title := "ChatGPT conversation"
+	if active, err := cli.chatgpt.ActiveChat(); err == nil && active.Title != "" {
+		title = active.Title
+	}
+	transcript := conversationMarkdown(title, turns)
 
-	for i, item := range history {
-		fmt.Printf("%d. %s\n", i+1, item.Title)
-		fmt.Printf("   ID: %s\n", item.ID)
-		fmt.Println()
+	if len(transcript) > copyWarnSize &&
+		!cli.confirm(fmt.Sprintf("⚠️  The conversation is %d KB. Copy it anyway?", len(transcript)/1024)) {
+		return nil
 	}
 
-	ui.PrintInfo("Use '/open <number>' or '/open <chat_id>' to open a chat")
+	if err := ui.CopyToClipboard(transcript); err != nil {
+		return err
+	}
+	ui.PrintSuccess(fmt.Sprintf("Copied %d messages (%d KB) to the clipboard", len(turns), (len(transcript)+1023)/1024))
 	return nil
 }
 
-// openChat opens a specific chat
-func (cli *CLI) openChat(identifier string) error {
-	// Check if it's a number (history index)
-	if num, err := strconv.Atoi(identifier); err == nil {
-		// Get history and open by index
-		history, err := cli.chatgpt.GetChatHistory()
-		if err != nil {
-			return fmt.Errorf("failed to get history: %v", err)
-		}
+// copyResponse puts the last answer, or with code set only its last code
+// block, onto the clipboard
+func (cli *CLI) copyResponse(code bool) error {
+	if cli.lastResponse == "" {
+		ui.PrintWarning("No answer to copy yet")
+		return nil
+	}
 
-		if num < 1 || num > len(history) {
-			return fmt.Errorf("invalid history number: %d (available: 1-%d)", num, len(history))
+	text, what := cli.lastResponse, "the last answer"
+	if code {
+		block, ok := lastCodeBlock(cli.lastResponse)
+		if !ok {
+			ui.PrintWarning("The last answer has no code block")
+			return nil
 		}
+		text, what = block, "the code block"
+	}
 
-		chatID := history[num-1].ID
-		fmt.Printf("📂 Opening chat: %s\n", history[num-1].Title)
-		return cli.chatgpt.OpenChat(chatID)
+	if err := ui.CopyToClipboard(text); err != nil {
+		return err
 	}
+	ui.PrintSuccess(fmt.Sprintf("Copied %s (%d lines) to the clipboard", what, strings.Count(strings.TrimRight(text, "\n"), "\n")+1))
+	return nil
+}
 
-	// Otherwise treat as chat ID
-	fmt.Printf("📂 Opening chat ID: %s\n", identifier)
-	return cli.chatgpt.OpenChat(identifier)
+// conversationMarkdown renders turns as a Markdown document with a heading per speaker
+func conversationMarkdown(title string, turns []chatgpt.Turn) string {
+	var md strings.Builder
+	md.WriteString("# " + title + "\n")
+	for _, turn := range turns {
+		speaker := "You"
+		if turn.Role == "assistant" {
+			speaker = "ChatGPT"
+		}
+		md.WriteString("\n## " + speaker + "\n\n")
+		md.WriteString(ui.StripANSI(turn.Text) + "\n")
+	}
+	return md.String()
 }
 
 // printWelcome prints welcome message
@@ -221,12 +1911,14 @@ func (cli *CLI) printHelp() {
 	fmt.Println("=" + strings.Repeat("=", 30))
 	fmt.Println()
 	fmt.Println("🔧 Commands:")
-	fmt.Println("  /help, /h           - Show this help")
-	fmt.Println("  /new, /n            - Start a new chat")
-	fmt.Println("  /history, /hist     - Show recent chat history")
-	fmt.Println("  /open <id>, /o <id> - Open chat by ID or number")
-	fmt.Println("  /clear, /cls        - Clear screen")
-	fmt.Println("  /quit, /q, /exit    - Exit the CLI")
+	for _, entry := range commandHelp {
+		fmt.Printf("  %-19s - %s\n", entry.Usage, entry.Description)
+		if strings.HasPrefix(entry.Usage, "/model ") {
+			if models := cli.chatgpt.CachedModels(); len(models) > 0 {
+				fmt.Println("                        models: " + strings.Join(models, ", "))
+			}
+		}
+	}
 	fmt.Println()
 	fmt.Println("💬 Usage:")
 	fmt.Println("  - Type any message to send to ChatGPT")
@@ -245,6 +1937,9 @@ func (cli *CLI) printHelp() {
 
 // printResponse prints ChatGPT response with formatting and typing effect
 func (cli *CLI) printResponse(response string) {
+	// Never leave code colors active if rendering stops mid-line
+	defer fmt.Print(ui.Reset)
+
 	// Simple clean formatting without aggressive code detection
 	response = strings.TrimSpace(response)
 
@@ -258,49 +1953,76 @@ func (cli *CLI) printResponse(response string) {
 
 	fmt.Println()
 
+	if cli.config.UI.ShowTOC {
+		if toc := formatter.TableOfContents(response); toc != "" {
+			fmt.Println(toc)
+			fmt.Println()
+		}
+	}
+
 	// Calculate responsive box width based on terminal size
 	boxWidth := ui.GetTerminalWidth()
-	headerText := "  Response   "
-	headerLine := headerText + strings.Repeat("─", boxWidth-len(headerText)-2)
-
-	// Print the header line immediately (no typing effect for border)
-	fmt.Print("\033[92m╭" + headerLine + "╮\033[0m\n")
+	printBoxTop(boxWidth)
 
 	// Process response with code highlighting
 	responseLines := ui.ProcessResponseWithCodeHighlight(response)
+	codeBg, codeFg := ui.CodeColors(cli.config.UI.Colors)
 
 	for _, responseLine := range responseLines {
-		// Print border immediately
-		fmt.Print("\033[92m│   \033[0m")
+		printBoxLine(responseLine, boxWidth, codeBg, codeFg)
+	}
+
+	printBoxBottom(boxWidth)
+}
+
+// printBoxTop prints the header line of the response box immediately (no typing effect for border)
+func printBoxTop(boxWidth int) {
+	headerText := "  Response   "
+	headerLine := headerText + strings.Repeat("─", boxWidth-len(headerText)-2)
+	fmt.Print("\033[92m╭" + headerLine + "╮\033[0m\n")
+}
+
+// printBoxLine prints one line of the response box with its borders
+func printBoxLine(responseLine ui.ResponseLine, boxWidth int, codeBg, codeFg string) {
+	// Print border immediately
+	fmt.Print("\033[92m│   \033[0m")
+
+	// Apply code highlighting if this is a code line
+	if responseLine.IsCode {
+		// Themed background and text for code
+		fmt.Print(codeBg + codeFg)
+		ui.TypeText(responseLine.Text, 20*time.Millisecond) // Slightly faster for code
+		fmt.Print("\033[0m")                                // Reset colors
+	} else {
+		// Normal text with typing effect
+		ui.TypeText(responseLine.Text, 30*time.Millisecond)
+	}
 
-		// Apply code highlighting if this is a code line
+	// Calculate padding to fill the line
+	padding := boxWidth - len(responseLine.Text) - 5 // 5 = "│   " + "│"
+	if padding > 0 {
 		if responseLine.IsCode {
-			// Navy blue background with white text for code
-			fmt.Print(ui.NavyBlue + ui.CodeText)
-			ui.TypeText(responseLine.Text, 20*time.Millisecond) // Slightly faster for code
-			fmt.Print("\033[0m")                                // Reset colors
+			// Continue code background for padding
+			fmt.Print(codeBg + strings.Repeat(" ", padding) + "\033[0m")
 		} else {
-			// Normal text with typing effect
-			ui.TypeText(responseLine.Text, 30*time.Millisecond)
-		}
-
-		// Calculate padding to fill the line
-		padding := boxWidth - len(responseLine.Text) - 5 // 5 = "│   " + "│"
-		if padding > 0 {
-			if responseLine.IsCode {
-				// Continue navy blue background for padding
-				fmt.Print(ui.NavyBlue + strings.Repeat(" ", padding) + "\033[0m")
-			} else {
-				fmt.Print(strings.Repeat(" ", padding))
-			}
+			fmt.Print(strings.Repeat(" ", padding))
 		}
-		fmt.Print("\033[92m│\033[0m\n")
 	}
+	fmt.Print("\033[92m│\033[0m\n")
+}
 
-	// Print the bottom border immediately (no typing effect)
+// printBoxBottom prints the bottom border of the response box immediately (no typing effect)
+func printBoxBottom(boxWidth int) {
 	fmt.Print("\033[92m╰" + strings.Repeat("─", boxWidth-2) + "╯\033[0m\n")
 }
 
+// printSources prints the links the last response cited, below its box
+func (cli *CLI) printSources() {
+	if sources := cli.chatgpt.LastSources(); len(sources) > 0 {
+		fmt.Println(ui.Dim + chatgpt.FormatSources(sources) + ui.Reset)
+	}
+}
+
 // clearScreen clears the terminal screen (deprecated - use ui.ClearScreen)
 func (cli *CLI) clearScreen() {
 	ui.ClearScreen()
@@ -377,17 +2099,18 @@ func (cli *CLI) analyzeProjectStructure() string {
 		}
 	}
 	
-	// Build analysis
+	// Build analysis, capping long lists so large projects keep a small prompt
+	maxListed := cli.config.Agent.MaxListedFiles
 	if len(configFiles) > 0 {
-		analysis.WriteString(fmt.Sprintf("Config files: %s\n", strings.Join(configFiles, ", ")))
+		analysis.WriteString(fmt.Sprintf("Config files: %s\n", agent.SummarizeNames(configFiles, maxListed)))
 	}
 	
 	if len(codeFiles) > 0 {
-		analysis.WriteString(fmt.Sprintf("Code files: %s\n", strings.Join(codeFiles, ", ")))
+		analysis.WriteString(fmt.Sprintf("Code files: %s\n", agent.SummarizeNames(codeFiles, maxListed)))
 	}
 	
 	if len(folders) > 0 {
-		analysis.WriteString(fmt.Sprintf("Directories: %s\n", strings.Join(folders, ", ")))
+		analysis.WriteString(fmt.Sprintf("Directories: %s\n", agent.SummarizeNames(folders, maxListed)))
 	}
 	
 	// Detect project type
@@ -448,8 +2171,8 @@ func (cli *CLI) sendSystemPromptForNewChat() error {
 	spinner := ui.NewSquareSpinner()
 	spinner.Start("Analyzing project and setting up context...")
 	
-	// Send system prompt
-	_, err := cli.chatgpt.SendMessage(systemPrompt)
+	// Send system prompt; its greeting is never printed
+	_, err := cli.chatgpt.SendQuiet(systemPrompt)
 	spinner.Stop()
 	
 	if err != nil {
@@ -457,7 +2180,9 @@ func (cli *CLI) sendSystemPromptForNewChat() error {
 		return err
 	}
 	
-	ui.PrintSuccess("Project context established! 🎯")
+	if !cli.config.UI.QuietSystemPrompt {
+		ui.PrintSuccess("Project context established! 🎯")
+	}
 	return nil
 }
 
@@ -480,6 +2205,10 @@ func (cli *CLI) handleCookies(action string) error {
 		return nil
 		
 	case "clean", "c":
+		if !ui.Confirm("Remove expired cookies from " + cookieManager.GetCookiesPath() + "?") {
+			ui.PrintInfo("Cleanup cancelled")
+			return nil
+		}
 		spinner := ui.NewSquareSpinner()
 		spinner.Start("Cleaning expired cookies...")
 		err := cookieManager.CleanExpiredCookies()
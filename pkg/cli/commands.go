@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"sort"
+	"strings"
+)
+
+// CommandHelp is one line of the /help command list
+type CommandHelp struct {
+	Usage       string // command names and arguments, e.g. "/open <id>, /o <id>"
+	Description string
+}
+
+// commandHelp lists the commands in the order /help shows them
+var commandHelp = []CommandHelp{
+	{"/help, /h", "Show this help"},
+	{"/new, /n", "Start a new chat"},
+	{"/branch <direction>", "Continue this chat in a new one, in a new direction"},
+	{"/history [--all]", "Show recent chat history (--all lifts the limit)"},
+	{"/history --tag <t>", "Show recent chats carrying a tag"},
+	{"/tag <tag>", "Label the current chat with a one-word tag"},
+	{"/tags", "List tags with their chat counts"},
+	{"/search [-n N] <keyword>", "Search all chat titles for a keyword"},
+	{"/open <id>, /o <id>", "Open chat by ID or number"},
+	{"/open fav <n>", "Open a favorite chat"},
+	{"/fav add <id>", "Save a chat (ID or history number) as a favorite"},
+	{"/favs", "List favorite chats"},
+	{"/read-chat <id>", "Read a chat without switching to it"},
+	{"/copy [code]", "Copy the last answer, or its last code block"},
+	{"/copy-all", "Copy the whole chat to the clipboard as Markdown"},
+	{"/export [file]", "Save the chat as a Markdown file"},
+	{"/rename <title>, /set-title <title>", "Rename the current chat"},
+	{"/delete [id] [--yes]", "Delete a chat, by default the current one"},
+	{"/resume-topic <t>", "Open the chat matching a topic, or start one"},
+	{"/status", "Show the current chat and agent mode"},
+	{"/mode [name]", "Show or switch the agent mode"},
+	{"/system <prompt>", "Put a prompt before every message (show, clear)"},
+	{"/usage", "Show messages and characters sent this session"},
+	{"/stats", "Show session statistics: messages, tokens, time"},
+	{"/diag", "Print an environment report for bug reports"},
+	{"/selectors", "Show how each selector matches the current page"},
+	{"/where", "Show which config, cookies and output paths are in use"},
+	{"/project-report", "Send a project report for an architectural assessment"},
+	{"/rerun @<file>", "Re-send your last prompt with the file's current content"},
+	{"/tabs", "List open browser tabs"},
+	{"/tab <n>", "Switch to another browser tab"},
+	{"/save-code [dir]", "Save every code block of the last answer as files"},
+	{"/models", "List available models (cached)"},
+	{"/models-refresh", "Re-scrape the model picker"},
+	{"/model <name>", "Switch to another model"},
+	{"/chat-info", "Show the current chat's ID, URL, model and turns"},
+	{"/benchmark [n]", "Measure response latency over n fresh chats"},
+	{"/compare-files-with-chat <a> <b>", "Ask ChatGPT to merge two files"},
+	{"/gentests <file>", "Generate tests for a source file"},
+	{"/tail <file> [n]", "Send the last n lines of a log (also /head)"},
+	{"/file <path> [prompt]", "Send a file for review, or with your own prompt"},
+	{"/grep [-i] <pattern>", "Search file contents and ask ChatGPT to summarize the matches"},
+	{"/exec <command>", "Run a shell command and send its output"},
+	{"/diffstat [--reset]", "Summarize the files written this run (+added -removed lines)"},
+	{"/explain-error", "Paste a stack trace to diagnose it with the code it points to"},
+	{"/write [path]", "Save the last generated file"},
+	{"/diff <file>", "Diff a file against the last answer's code block"},
+	{"/apply", "Write the version shown by /diff"},
+	{"/t <name> <args>", "Send a prompt template (/t list to show all)"},
+	{"/focus", "Bring the browser window to the front"},
+	{"/screenshot [file]", "Save a PNG of what the browser shows, for bug reports"},
+	{"/login", "Restore an expired ChatGPT session"},
+	{"/typing [on|off]", "Toggle the typing effect (saved to config)"},
+	{"/multiline", "Toggle multi-line input; .send on its own line sends"},
+	{"/clear, /cls", "Clear screen"},
+	{"/quit, /q, /exit", "Exit the CLI"},
+}
+
+// CommandRegistry maps every command name in the help list to its
+// description. Tab completion reads it, so a command added to the help list
+// completes without further changes.
+var CommandRegistry = buildCommandRegistry(commandHelp)
+
+// buildCommandRegistry collects the command names of each usage, such as
+// /open and /o from "/open <id>, /o <id>"
+func buildCommandRegistry(entries []CommandHelp) map[string]string {
+	registry := make(map[string]string)
+	for _, entry := range entries {
+		for _, field := range strings.Fields(strings.ReplaceAll(entry.Usage, ",", " ")) {
+			if strings.HasPrefix(field, "/") {
+				if _, ok := registry[field]; !ok {
+					registry[field] = entry.Description
+				}
+			}
+		}
+	}
+	return registry
+}
+
+// commandNames returns the registered command names in alphabetical order
+func commandNames() []string {
+	names := make([]string, 0, len(CommandRegistry))
+	for name := range CommandRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
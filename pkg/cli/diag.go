@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/chatgpt-element-recorder/pkg/browser"
+	"github.com/chatgpt-element-recorder/pkg/config"
+	"github.com/chatgpt-element-recorder/pkg/ui"
+	"golang.org/x/term"
+)
+
+// showDiagnostics prints an environment report formatted for pasting into an issue.
+// Cookie values are never included.
+func (cli *CLI) showDiagnostics() error {
+	spinner := ui.NewSquareSpinner()
+	spinner.Start("Collecting diagnostics...")
+	diag, diagErr := cli.chatgpt.Diagnostics()
+	spinner.Stop()
+
+	var report strings.Builder
+	line := func(format string, args ...interface{}) {
+		report.WriteString(fmt.Sprintf(format, args...) + "\n")
+	}
+
+	line("### Environment")
+	line("- Generated: %s", time.Now().Format(time.RFC3339))
+	line("- OS/arch: %s/%s", runtime.GOOS, runtime.GOARCH)
+	line("- Go: %s", runtime.Version())
+	line("- chromedp: %s", moduleVersion("github.com/chromedp/chromedp"))
+	line("- cdproto: %s", moduleVersion("github.com/chromedp/cdproto"))
+	if width, height, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+		line("- Terminal: %dx%d", width, height)
+	} else {
+		line("- Terminal: not a TTY")
+	}
+	line("- Headless: %t", cli.chatgpt.IsHeadless())
+
+	line("")
+	line("### Browser")
+	if diagErr != nil {
+		line("- Error: %v", diagErr)
+	} else {
+		line("- Product: %s", diag.Product)
+		line("- Protocol: %s", diag.Protocol)
+		line("- User agent: %s", diag.UserAgent)
+		line("- URL: %s", diag.URL)
+		line("")
+		line("### Selectors (matches on current page)")
+		for _, s := range diag.Selectors {
+			status := fmt.Sprintf("%d", s.Count)
+			if s.Count < 0 {
+				status = "invalid"
+			}
+			line("- %s `%s`: %s", s.Name, s.Selector, status)
+		}
+	}
+
+	line("")
+	line("### Cookies")
+	cookies, err := browser.NewCookieManager().LoadCookies()
+	if err != nil {
+		line("- Error: %v", err)
+	} else {
+		line("- %d cookies loaded (values redacted)", len(cookies))
+		for _, c := range cookies {
+			line("  - %s (%s)", c.Name, c.Domain)
+		}
+	}
+
+	line("")
+	line("### Effective config")
+	configJSON, err := json.MarshalIndent(cli.config, "", "  ")
+	if err != nil {
+		line("- Error: %v", err)
+	} else {
+		line("```json")
+		line("%s", configJSON)
+		line("```")
+	}
+
+	fmt.Println()
+	fmt.Print(report.String())
+	ui.PrintInfo("Copy the report above into your issue")
+	return nil
+}
+
+// moduleVersion returns the version of a dependency compiled into the binary
+func moduleVersion(path string) string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == path {
+			return dep.Version
+		}
+	}
+	return "unknown"
+}
+
+// showWhere prints the absolute paths of the configuration files, cookies and
+// output directory in use, and whether each configuration file was read from
+// disk or replaced by the built-in defaults
+func (cli *CLI) showWhere() {
+	fmt.Println("\n📁 Files in use:")
+	ui.PrintSeparator()
+	for _, source := range config.Sources() {
+		status := "missing"
+		switch {
+		case source.FromDisk:
+			status = "loaded from disk"
+		case source.Err != nil && source.Exists:
+			status = "defaults - file could not be parsed"
+		case source.Err != nil:
+			status = "defaults - file not found"
+		case source.Exists:
+			status = "exists"
+		}
+		fmt.Printf("%-11s %s\n", source.Name+":", source.Path)
+		fmt.Printf("            %s%s%s\n", ui.Dim, status, ui.Reset)
+	}
+	ui.PrintSeparator()
+	if wd, err := os.Getwd(); err == nil {
+		ui.PrintInfo(fmt.Sprintf("Paths are relative to the directory the CLI was started in: %s", wd))
+	}
+}
+
+// showSelectors runs every candidate selector against the current page and
+// shows which one each role resolves to, for tracking down selector drift
+func (cli *CLI) showSelectors() error {
+	reports, err := cli.chatgpt.SelectorReport()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("\n🎯 Selectors on the current page:")
+	ui.PrintSeparator()
+	for _, report := range reports {
+		if report.Winner >= 0 {
+			fmt.Printf("%s✅ %s%s\n", ui.Green, report.Role, ui.Reset)
+		} else {
+			fmt.Printf("%s❌ %s - nothing matched%s\n", ui.Red, report.Role, ui.Reset)
+		}
+		for i, match := range report.Candidates {
+			marker := "   "
+			if i == report.Winner {
+				marker = " ▶ "
+			}
+			count := fmt.Sprintf("%d", match.Count)
+			if match.Count < 0 {
+				count = "invalid"
+			}
+			fmt.Printf("%s%-7s %s%s%s\n", marker, count, ui.Dim, match.Selector, ui.Reset)
+		}
+	}
+	ui.PrintSeparator()
+	ui.PrintInfo("▶ marks the first selector that matched; edit configs/selectors.json to change the candidates")
+	return nil
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chatgpt-element-recorder/pkg/agent"
+	"github.com/chatgpt-element-recorder/pkg/formatter"
+	"github.com/chatgpt-element-recorder/pkg/ui"
+)
+
+// diffContext is how many unchanged lines /diff shows around each change
+const diffContext = 3
+
+// showDiff compares a file with the last code block of the most recent answer
+// and offers the suggested version to /apply
+func (cli *CLI) showDiff(filename string) error {
+	if cli.agent == nil {
+		return fmt.Errorf("agent system not available")
+	}
+	if cli.lastResponse == "" {
+		ui.PrintWarning("No answer to compare with yet")
+		return nil
+	}
+
+	suggested, ok := lastCodeBlock(cli.lastResponse)
+	if !ok {
+		ui.PrintWarning("The last answer has no code block")
+		return nil
+	}
+	if !strings.HasSuffix(suggested, "\n") {
+		suggested += "\n"
+	}
+
+	current := ""
+	if cli.agent.FileExists(filename) {
+		content, err := cli.agent.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		current = content
+	}
+
+	diff := agent.UnifiedDiff(filename, current, suggested, diffContext)
+	if diff == "" {
+		ui.PrintInfo(fmt.Sprintf("%s already matches the suggested version", filename))
+		return nil
+	}
+
+	printDiff(diff)
+	cli.pendingWrite = &pendingWrite{path: filename, content: suggested}
+	ui.PrintInfo(fmt.Sprintf("Use /apply to write this version to %s", filename))
+	return nil
+}
+
+// lastCodeBlock returns the last code block of a response, also when the
+// scraped text has lost its fences
+func lastCodeBlock(response string) (string, bool) {
+	if _, code, ok := formatter.ExtractLastCodeBlock(response); ok {
+		return code, true
+	}
+	blocks := agent.ExtractCodeBlocks(response)
+	if len(blocks) == 0 {
+		return "", false
+	}
+	return blocks[len(blocks)-1].Code, true
+}
+
+// printDiff prints a unified diff in the response box, removed lines in red
+// and added lines in green
+func printDiff(diff string) {
+	boxWidth := ui.GetTerminalWidth()
+	printBoxTop(boxWidth)
+	for _, line := range strings.Split(strings.TrimRight(diff, "\n"), "\n") {
+		color := ""
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			color = ui.Bold
+		case strings.HasPrefix(line, "@@"):
+			color = ui.Cyan
+		case strings.HasPrefix(line, "-"):
+			color = ui.Red
+		case strings.HasPrefix(line, "+"):
+			color = ui.Green
+		}
+
+		fmt.Print("\033[92m│   \033[0m" + color + line + ui.Reset)
+		if padding := boxWidth - len(line) - 5; padding > 0 {
+			fmt.Print(strings.Repeat(" ", padding))
+		}
+		fmt.Print("\033[92m│\033[0m\n")
+	}
+	printBoxBottom(boxWidth)
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chatgpt-element-recorder/pkg/agent"
+	"github.com/chatgpt-element-recorder/pkg/chatgpt"
+	"github.com/chatgpt-element-recorder/pkg/ui"
+)
+
+// exportConversation writes the current conversation to a Markdown file,
+// by default conversation-<chatID>-<date>.md in the output directory
+func (cli *CLI) exportConversation(filename string) error {
+	if cli.isReadOnly() {
+		return agent.ErrReadOnly
+	}
+
+	turns := cli.chatgpt.Conversation()
+	if len(turns) == 0 {
+		// Chats opened with /open or restored were not sent through this
+		// client, so read them off the page instead
+		spinner := ui.NewSquareSpinner()
+		spinner.Start("Reading conversation...")
+		scraped, err := cli.chatgpt.CurrentConversation()
+		spinner.Stop()
+		if err != nil {
+			return err
+		}
+		for _, turn := range scraped {
+			turns = append(turns, chatgpt.TrackedTurn{Role: turn.Role, Content: turn.Text})
+		}
+	}
+	if len(turns) == 0 {
+		ui.PrintWarning("No messages in the current chat")
+		return nil
+	}
+
+	chatID, title, model := "new", "ChatGPT conversation", ""
+	if info, err := cli.chatgpt.GetChatInfo(); err == nil {
+		chatID, model = info.ID, info.Model
+		if info.Title != "" {
+			title = info.Title
+		}
+	}
+
+	now := time.Now()
+	if filename == "" {
+		filename = filepath.Join(cli.config.Files.OutputDir,
+			fmt.Sprintf("conversation-%s-%s.md", chatID, now.Format("2006-01-02")))
+	}
+	if dir := filepath.Dir(filename); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %v", err)
+		}
+	}
+
+	content := exportMarkdown(chatID, model, title, now, turns)
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to export conversation: %v", err)
+	}
+	ui.PrintSuccess(fmt.Sprintf("Exported %d messages to %s", len(turns), filename))
+	return nil
+}
+
+// exportMarkdown renders turns under ## User / ## Assistant headings after a
+// YAML front matter block. Message text is written as is, so fenced code
+// blocks in it stay intact.
+func exportMarkdown(chatID, model, title string, exported time.Time, turns []chatgpt.TrackedTurn) string {
+	// JSON strings are valid YAML double-quoted scalars
+	quote := func(value string) string {
+		data, _ := json.Marshal(value)
+		return string(data)
+	}
+
+	var md strings.Builder
+	md.WriteString("---\n")
+	md.WriteString("chat_id: " + quote(chatID) + "\n")
+	md.WriteString("exported: " + quote(exported.Format(time.RFC3339)) + "\n")
+	md.WriteString("model: " + quote(model) + "\n")
+	md.WriteString("---\n\n")
+	md.WriteString("# " + title + "\n")
+
+	for _, turn := range turns {
+		heading := "User"
+		if turn.Role == "assistant" {
+			heading = "Assistant"
+		}
+		md.WriteString("\n## " + heading + "\n\n")
+		if !turn.Timestamp.IsZero() {
+			md.WriteString("_" + turn.Timestamp.Format("2006-01-02 15:04:05") + "_\n\n")
+		}
+		md.WriteString(strings.TrimRight(ui.StripANSI(turn.Content), "\n") + "\n")
+	}
+	return md.String()
+}
@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/chatgpt-element-recorder/pkg/file"
+	"github.com/chatgpt-element-recorder/pkg/ui"
+)
+
+// favoritesPath is where /fav stores the favorite chats
+const favoritesPath = "configs/favorites.json"
+
+// favorite is a saved reference to a chat
+type favorite struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// loadFavorites reads the favorites file; a missing file is an empty list
+func loadFavorites() ([]favorite, error) {
+	var favorites []favorite
+	if err := file.ReadJSONFile(favoritesPath, &favorites); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read favorites: %v", err)
+	}
+	return favorites, nil
+}
+
+// saveFavorites writes the favorites file
+func saveFavorites(favorites []favorite) error {
+	if err := file.WriteJSONFile(favoritesPath, favorites); err != nil {
+		return fmt.Errorf("failed to save favorites: %v", err)
+	}
+	return nil
+}
+
+// handleFavorite runs /fav subcommands. Usage: /fav add <number_or_id>
+func (cli *CLI) handleFavorite(args []string) error {
+	if len(args) < 2 || args[0] != "add" {
+		fmt.Println("❌ Usage: /fav add <chat_id_or_number>")
+		return nil
+	}
+	return cli.addFavorite(args[1])
+}
+
+// addFavorite stores a chat from history or by ID, skipping chats already saved
+func (cli *CLI) addFavorite(identifier string) error {
+	chatID, title, err := cli.resolveChat(identifier)
+	if err != nil {
+		return err
+	}
+	if title == "" {
+		title = cli.chatTitle(chatID)
+	}
+
+	favorites, err := loadFavorites()
+	if err != nil {
+		return err
+	}
+	for _, fav := range favorites {
+		if fav.ID == chatID {
+			ui.PrintInfo(fmt.Sprintf("Already a favorite: %s", fav.Title))
+			return nil
+		}
+	}
+
+	favorites = append(favorites, favorite{ID: chatID, Title: title})
+	if err := saveFavorites(favorites); err != nil {
+		return err
+	}
+	ui.PrintSuccess(fmt.Sprintf("Added to favorites (#%d): %s", len(favorites), title))
+	return nil
+}
+
+// chatTitle looks up a chat's title in the history, falling back to its ID
+func (cli *CLI) chatTitle(chatID string) string {
+	history, err := cli.chatgpt.GetChatHistory(1, cli.pageSize())
+	if err == nil {
+		for _, item := range history {
+			if item.ID == chatID {
+				return item.Title
+			}
+		}
+	}
+	return chatID
+}
+
+// showFavorites lists the favorite chats
+func (cli *CLI) showFavorites() error {
+	favorites, err := loadFavorites()
+	if err != nil {
+		return err
+	}
+	if len(favorites) == 0 {
+		ui.PrintWarning("No favorites yet")
+		ui.PrintInfo("Use '/fav add <number>' to save a chat from /history")
+		return nil
+	}
+
+	fmt.Println("\n⭐ Favorite Chats:")
+	ui.PrintSeparator()
+	for i, fav := range favorites {
+		fmt.Printf("%d. %s\n", i+1, fav.Title)
+		fmt.Printf("   ID: %s\n", fav.ID)
+		fmt.Println()
+	}
+
+	ui.PrintInfo("Use '/open fav <number>' to open a favorite")
+	return nil
+}
+
+// openFavorite opens the favorite at a 1-based index
+func (cli *CLI) openFavorite(index string) error {
+	favorites, err := loadFavorites()
+	if err != nil {
+		return err
+	}
+
+	num, err := strconv.Atoi(index)
+	if err != nil || num < 1 || num > len(favorites) {
+		if len(favorites) == 0 {
+			return fmt.Errorf("no favorites yet - use /fav add <number> first")
+		}
+		return fmt.Errorf("invalid favorite number: %s (available: 1-%d)", index, len(favorites))
+	}
+
+	fav := favorites[num-1]
+	fmt.Printf("📂 Opening chat: %s\n", fav.Title)
+	return cli.chatgpt.OpenChat(fav.ID)
+}
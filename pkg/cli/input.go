@@ -0,0 +1,356 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/chatgpt-element-recorder/pkg/agent"
+	"github.com/chatgpt-element-recorder/pkg/ui"
+	"golang.org/x/term"
+)
+
+// inputReader reads user input. Features that need raw terminal mode go
+// through withRawMode, which falls back to line-based reading when the
+// terminal (Git Bash, some CI shells) cannot switch to raw mode.
+type inputReader struct {
+	stdin        *bufio.Reader
+	scanner      *bufio.Scanner
+	maxLine      int  // longest line the scanner accepts, in bytes
+	tooLong      bool // the last ReadLine dropped a line over maxLine
+	fd           int
+	rawAvailable bool
+	noticeOnce   sync.Once
+	editor       *lineEditor // nil without ui.line_editing
+}
+
+// newInputReader creates an input reader for stdin that accepts lines up to
+// maxKB kilobytes, and probes raw mode support. With lineEditing set, lines
+// typed at a terminal are read through the line editor.
+func newInputReader(maxKB int, lineEditing bool) *inputReader {
+	if maxKB <= 0 {
+		maxKB = 1024
+	}
+	r := &inputReader{
+		stdin:   bufio.NewReader(os.Stdin),
+		maxLine: maxKB * 1024,
+		fd:      int(os.Stdin.Fd()),
+	}
+	r.resetScanner()
+	r.rawAvailable = r.probeRawMode()
+	if lineEditing && r.rawAvailable {
+		r.editor = newLineEditor(r.stdin)
+	}
+	return r
+}
+
+// resetScanner starts a fresh scanner on stdin. A scanner stops for good after
+// an error, so this is how reading resumes after an over-long line.
+func (r *inputReader) resetScanner() {
+	r.scanner = bufio.NewScanner(r.stdin)
+	// The scanner's limit is the larger of the buffer's capacity and max
+	initial := 64 * 1024
+	if initial > r.maxLine {
+		initial = r.maxLine
+	}
+	r.scanner.Buffer(make([]byte, 0, initial), r.maxLine)
+}
+
+// probeRawMode checks once whether stdin can be switched to raw mode
+func (r *inputReader) probeRawMode() bool {
+	// Piped input never has raw mode and does not need a notice
+	if !term.IsTerminal(r.fd) {
+		return false
+	}
+
+	state, err := term.MakeRaw(r.fd)
+	if err != nil {
+		r.notifyFallback()
+		return false
+	}
+	term.Restore(r.fd, state)
+	return true
+}
+
+// notifyFallback prints the line-input fallback notice at most once
+func (r *inputReader) notifyFallback() {
+	r.noticeOnce.Do(func() {
+		ui.PrintInfo("Terminal raw mode unavailable - using basic line input")
+	})
+}
+
+// RawAvailable reports whether raw-mode input features can be used
+func (r *inputReader) RawAvailable() bool {
+	return r.rawAvailable
+}
+
+// withRawMode runs fn with the terminal in raw mode. If raw mode cannot be
+// entered it disables raw features for the session and returns false so the
+// caller can fall back to ReadLine.
+func (r *inputReader) withRawMode(fn func() error) (bool, error) {
+	if !r.rawAvailable {
+		return false, nil
+	}
+
+	state, err := term.MakeRaw(r.fd)
+	if err != nil {
+		r.rawAvailable = false
+		r.notifyFallback()
+		return false, nil
+	}
+	defer term.Restore(r.fd, state)
+
+	// Leave raw mode even if the process is interrupted while in it
+	removeHook := ui.AddRestoreHook(func() { term.Restore(r.fd, state) })
+	defer removeHook()
+
+	return true, fn()
+}
+
+// ReadLine prints the prompt and returns the next input line.
+// ok is false when input is exhausted. A line longer than the limit is
+// dropped rather than truncated: the user is told, the rest of the line is
+// discarded and ReadLine returns an empty line with TooLong set.
+func (r *inputReader) ReadLine(prompt string) (line string, ok bool) {
+	return r.readLine(prompt, false)
+}
+
+// ReadInput is ReadLine for messages and commands, which the line editor
+// keeps in its history
+func (r *inputReader) ReadInput(prompt string) (line string, ok bool) {
+	return r.readLine(prompt, true)
+}
+
+func (r *inputReader) readLine(prompt string, remember bool) (line string, ok bool) {
+	r.tooLong = false
+	if r.editor != nil {
+		// Without raw mode readEdited shows the prompt and leaves the read to us
+		if line, ok, edited := r.readEdited(prompt, remember); edited {
+			return line, ok
+		}
+	} else {
+		fmt.Print(prompt)
+	}
+	if r.scanner.Scan() {
+		return r.scanner.Text(), true
+	}
+	if !errors.Is(r.scanner.Err(), bufio.ErrTooLong) {
+		return "", false
+	}
+
+	// The scanner's buffer holds the start of the line; skip the remainder
+	r.stdin.ReadString('\n')
+	r.resetScanner()
+	r.tooLong = true
+	ui.PrintWarning(fmt.Sprintf("Input is longer than %d KB and was not sent", r.maxLine/1024))
+	ui.PrintInfo("Paste it over several lines with ui.send_mode \"double-enter\", or raise ui.max_input_kb")
+	return "", true
+}
+
+// readEdited reads a line through the line editor. edited is false when raw
+// mode could not be entered and the caller should read a plain line instead.
+func (r *inputReader) readEdited(prompt string, remember bool) (line string, ok, edited bool) {
+	// The editor draws a single-line prompt; lines before it are printed as is
+	if i := strings.LastIndex(prompt, "\n"); i >= 0 {
+		fmt.Print(prompt[:i+1])
+		prompt = prompt[i+1:]
+	}
+
+	var err error
+	edited, _ = r.withRawMode(func() error {
+		line, err = r.editor.readLine(r.fd, prompt, remember)
+		return nil
+	})
+	if !edited {
+		fmt.Print(prompt)
+		return "", false, false
+	}
+	if err != nil {
+		// Ctrl-D on an empty line, Ctrl-C or closed input
+		return "", false, true
+	}
+	if len(line) > r.maxLine {
+		r.tooLong = true
+		ui.PrintWarning(fmt.Sprintf("Input is longer than %d KB and was not sent", r.maxLine/1024))
+		ui.PrintInfo("Raise ui.max_input_kb to send it")
+		return "", true, true
+	}
+	return line, true, true
+}
+
+// ReadKey prints the prompt and returns the next key pressed, without waiting
+// for Enter when raw mode is available; otherwise it returns the first
+// character of the next line. Ctrl-C and Ctrl-D read as 'q'. ok is false when
+// input is exhausted.
+func (r *inputReader) ReadKey(prompt string) (key rune, ok bool) {
+	fmt.Print(prompt)
+	var err error
+	raw, _ := r.withRawMode(func() error {
+		key, _, err = r.stdin.ReadRune()
+		return nil
+	})
+	if !raw {
+		line, ok := r.ReadLine("")
+		if !ok {
+			return 0, false
+		}
+		key, _ = utf8.DecodeRuneInString(strings.TrimSpace(line))
+		return unicode.ToLower(key), true
+	}
+
+	fmt.Println()
+	if err != nil {
+		return 0, false
+	}
+	if key == 3 || key == 4 {
+		return 'q', true
+	}
+	return unicode.ToLower(key), true
+}
+
+// TooLong reports whether the last ReadLine dropped an over-long line
+func (r *inputReader) TooLong() bool {
+	return r.tooLong
+}
+
+// Send modes for ui.send_mode
+const (
+	sendModeEnter       = "enter"
+	sendModeDoubleEnter = "double-enter"
+)
+
+// unescapeInput turns the escapes \n and \t into a newline and a tab. A
+// literal backslash has to be typed as \\; other escapes are left as typed.
+func unescapeInput(text string) string {
+	if !strings.Contains(text, `\`) {
+		return text
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 == len(text) {
+			b.WriteByte(text[i])
+			continue
+		}
+		switch text[i+1] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			b.WriteByte(text[i])
+			continue
+		}
+		i++
+	}
+	return b.String()
+}
+
+// continuationPrompt is shown while a message spans several lines
+const continuationPrompt = "... > "
+
+// multilineSend on a line of its own sends the message in /multiline mode
+const multilineSend = ".send"
+
+// readMessage reads the next message. A line ending in \ continues on the
+// next line; in /multiline mode every line is kept until .send on its own
+// line; in double-enter mode Enter starts a new line and a blank line
+// submits. Commands still run on a single Enter.
+func (cli *CLI) readMessage() (string, bool) {
+	line, ok := cli.input.ReadInput("\n" + cli.renderPrompt())
+	if !ok {
+		return line, ok
+	}
+
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "/") {
+		return line, true
+	}
+
+	switch {
+	case cli.multiline:
+		if trimmed == multilineSend {
+			return "", true
+		}
+		return cli.readContinuation(line, continuationPrompt, func(next string) (string, bool, bool) {
+			if strings.TrimSpace(next) == multilineSend {
+				return "", false, true
+			}
+			return next, true, false
+		})
+	case strings.HasSuffix(line, `\`):
+		return cli.readContinuation(strings.TrimSuffix(line, `\`), continuationPrompt, func(next string) (string, bool, bool) {
+			if strings.HasSuffix(next, `\`) {
+				return strings.TrimSuffix(next, `\`), true, false
+			}
+			return next, true, true
+		})
+	case cli.config.UI.SendMode == sendModeDoubleEnter && trimmed != "":
+		return cli.readContinuation(line, "  ", func(next string) (string, bool, bool) {
+			if strings.TrimSpace(next) == "" {
+				return "", false, true
+			}
+			return next, true, false
+		})
+	}
+	return line, true
+}
+
+// readContinuation reads further lines of a message that starts with first.
+// Each line is passed to next, which returns the text to add, whether to add
+// it and whether the message is complete. Input ending also completes it.
+func (cli *CLI) readContinuation(first, prompt string, next func(line string) (text string, keep, done bool)) (string, bool) {
+	lines := []string{first}
+	for {
+		line, ok := cli.input.ReadLine(prompt)
+		if cli.input.TooLong() {
+			// Sending the lines before the dropped one would be a truncated message
+			return "", true
+		}
+		if !ok {
+			break
+		}
+		text, keep, done := next(line)
+		if keep {
+			lines = append(lines, text)
+		}
+		if done {
+			break
+		}
+	}
+	return strings.Join(lines, "\n"), true
+}
+
+// renderPrompt returns the input prompt: the agent mode unless it is the
+// default interactive one, a lock in safe mode, and >> in /multiline mode
+func (cli *CLI) renderPrompt() string {
+	prompt := "> "
+	if cli.multiline {
+		prompt = ">> "
+	}
+	if cli.isReadOnly() {
+		prompt = "🔒" + prompt
+	}
+	if cli.agent != nil && cli.agent.GetMode() != agent.InteractiveMode {
+		prompt = "[" + string(cli.agent.GetMode()) + "] " + prompt
+	}
+	return prompt
+}
+
+// toggleMultiline switches /multiline mode, where Enter adds a line and
+// .send on its own line sends the message
+func (cli *CLI) toggleMultiline() {
+	cli.multiline = !cli.multiline
+	if cli.multiline {
+		ui.PrintSuccess("Multi-line mode on - type " + multilineSend + " on its own line to send")
+	} else {
+		ui.PrintSuccess("Multi-line mode off")
+	}
+}
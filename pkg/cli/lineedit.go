@@ -0,0 +1,210 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// historyFileName is the file in the home directory that keeps entered lines
+// between runs
+const historyFileName = ".gpt5dev_history"
+
+// historyLimit is how many entries the history keeps
+const historyLimit = 1000
+
+// Keys the line editor handles itself
+const (
+	keyTab   = '\t'
+	keyCtrlR = 18
+)
+
+// lineHistory is the up/down arrow history, loaded from and appended to the
+// history file. Only lines read while record is set are kept, so answers to
+// confirmations stay out of it.
+type lineHistory struct {
+	entries []string // oldest first
+	path    string
+	record  bool
+}
+
+// loadLineHistory reads the history file; a missing file is an empty history
+func loadLineHistory() *lineHistory {
+	h := &lineHistory{}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return h
+	}
+	h.path = filepath.Join(home, historyFileName)
+
+	f, err := os.Open(h.path)
+	if err != nil {
+		return h
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if len(h.entries) > historyLimit {
+		h.entries = h.entries[len(h.entries)-historyLimit:]
+	}
+	return h
+}
+
+// Add implements term.History. Blank lines and repeats of the last entry are skipped.
+func (h *lineHistory) Add(entry string) {
+	if !h.record || strings.TrimSpace(entry) == "" || strings.ContainsAny(entry, "\r\n") {
+		return
+	}
+	if n := len(h.entries); n > 0 && h.entries[n-1] == entry {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if len(h.entries) > historyLimit {
+		h.entries = h.entries[len(h.entries)-historyLimit:]
+	}
+
+	if h.path == "" {
+		return
+	}
+	if f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600); err == nil {
+		fmt.Fprintln(f, entry)
+		f.Close()
+	}
+}
+
+// Len implements term.History
+func (h *lineHistory) Len() int {
+	return len(h.entries)
+}
+
+// At implements term.History; index 0 is the newest entry
+func (h *lineHistory) At(idx int) string {
+	return h.entries[len(h.entries)-1-idx]
+}
+
+// lineEditor reads lines with cursor movement, Ctrl-A/E style editing, arrow
+// key history, Ctrl-R reverse search and Tab completion of commands. It must
+// run with the terminal in raw mode.
+type lineEditor struct {
+	terminal *term.Terminal
+	history  *lineHistory
+
+	searching   bool   // Ctrl-R was the last key
+	searchQuery string // text searched for while searching
+	searchIndex int    // history index of the current match
+}
+
+// newLineEditor creates a line editor reading from in and drawing on stdout
+func newLineEditor(in io.Reader) *lineEditor {
+	e := &lineEditor{history: loadLineHistory()}
+	e.terminal = term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{in, os.Stdout}, "")
+	e.terminal.History = e.history
+	e.terminal.AutoCompleteCallback = e.onKey
+	return e
+}
+
+// readLine reads one line after showing prompt, which must not contain
+// newlines. With remember set the line is added to the history.
+func (e *lineEditor) readLine(fd int, prompt string, remember bool) (string, error) {
+	if width, height, err := term.GetSize(fd); err == nil {
+		e.terminal.SetSize(width, height)
+	}
+	e.terminal.SetPrompt(prompt)
+	e.history.record = remember
+	e.searching = false
+
+	// Pasted text isn't subject to the editor's line length cap. The mode is
+	// only on while reading so the shell gets the terminal back unchanged.
+	e.terminal.SetBracketedPasteMode(true)
+	defer e.terminal.SetBracketedPasteMode(false)
+
+	line, err := e.terminal.ReadLine()
+	if err == term.ErrPasteIndicator {
+		err = nil
+	}
+	return line, err
+}
+
+// onKey is the terminal's per-key hook for Tab and Ctrl-R; other keys are
+// left to the terminal
+func (e *lineEditor) onKey(line string, pos int, key rune) (string, int, bool) {
+	if key != keyCtrlR {
+		e.searching = false
+	}
+
+	switch key {
+	case keyTab:
+		return e.complete(line, pos)
+	case keyCtrlR:
+		return e.reverseSearch(line, pos)
+	}
+	return "", 0, false
+}
+
+// complete completes the command name before the cursor. A unique match is
+// completed with a trailing space; several matches are completed to their
+// common prefix, or listed when that adds nothing.
+func (e *lineEditor) complete(line string, pos int) (string, int, bool) {
+	word := line[:pos]
+	if !strings.HasPrefix(word, "/") || strings.ContainsAny(word, " \t") {
+		return line, pos, true
+	}
+
+	var matches []string
+	for _, name := range commandNames() {
+		if strings.HasPrefix(name, word) {
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return line, pos, true
+	case 1:
+		completed := matches[0] + " "
+		return completed + line[pos:], len(completed), true
+	}
+
+	prefix := matches[0]
+	for _, match := range matches[1:] {
+		for !strings.HasPrefix(match, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	if len(prefix) > len(word) {
+		return prefix + line[pos:], len(prefix), true
+	}
+	fmt.Fprintln(e.terminal, strings.Join(matches, "  "))
+	return line, pos, true
+}
+
+// reverseSearch replaces the line with the newest history entry containing
+// the text typed before Ctrl-R; pressing Ctrl-R again steps to older matches
+func (e *lineEditor) reverseSearch(line string, pos int) (string, int, bool) {
+	if !e.searching {
+		e.searching = true
+		e.searchQuery = line
+		e.searchIndex = -1
+	}
+
+	for i := e.searchIndex + 1; i < e.history.Len(); i++ {
+		if entry := e.history.At(i); strings.Contains(entry, e.searchQuery) {
+			e.searchIndex = i
+			return entry, len(entry), true
+		}
+	}
+	// No older match: stay on the current one
+	return line, pos, true
+}
@@ -5,21 +5,33 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/chatgpt-element-recorder/pkg/agent"
+	"github.com/chatgpt-element-recorder/pkg/config"
+	"github.com/chatgpt-element-recorder/pkg/formatter"
+	"github.com/chatgpt-element-recorder/pkg/ui"
 )
 
 // CLIArgs represents parsed command line arguments
 type CLIArgs struct {
-	Mode        string
-	Query       string
-	Interactive bool
-	Config      string
-	Help        bool
-	Version     bool
-	Debug       bool
-	NoContext   bool
-	OutputFile  string
+	Mode         string
+	Query        string
+	Interactive  bool
+	Config       string
+	Help         bool
+	Version      bool
+	Debug        bool
+	NoContext    bool
+	OutputFile   string
+	Ask          string // Prompt sent at startup before the interactive loop
+	Safe         bool   // Read-only mode: no file writes
+	Timeout      int    // Seconds to wait for pages and responses; 0 means no timeout
+	TimeoutSet   bool   // Whether --timeout was given, overriding the config
+	Yes          bool   // Answer yes to every confirmation prompt
+	OutputFormat string // "text" or "json" for non-interactive responses
+	ConfigFormat string // "json", "yaml" or "toml": which config file to use
+	ConvertTo    string // format to convert the config file to, then exit
 }
 
 // ParseArgs parses command line arguments similar to sengpt
@@ -44,6 +56,15 @@ func ParseArgs() (*CLIArgs, error) {
 	flag.BoolVar(&args.NoContext, "no-context", false, "Disable project context analysis")
 	flag.StringVar(&args.OutputFile, "output", "", "Output file for responses")
 	flag.StringVar(&args.OutputFile, "o", "", "Output file (short)")
+	flag.StringVar(&args.Ask, "ask", "", "Prompt to send at startup, then stay interactive")
+	flag.StringVar(&args.Ask, "a", "", "Startup prompt (short)")
+	flag.BoolVar(&args.Safe, "safe", false, "Read-only mode: disallow all file writes and shell commands")
+	flag.IntVar(&args.Timeout, "timeout", 0, "Seconds to wait for pages and responses this run (0 = no timeout)")
+	flag.BoolVar(&args.Yes, "yes", false, "Answer yes to every confirmation prompt")
+	flag.BoolVar(&args.Yes, "y", false, "Answer yes to confirmations (short)")
+	flag.StringVar(&args.OutputFormat, "output-format", "text", "Response format for non-interactive modes: text or json")
+	flag.StringVar(&args.ConfigFormat, "config-format", "", "Config file format to use: json, yaml or toml")
+	flag.StringVar(&args.ConvertTo, "convert-config", "", "Write the config file in another format (json, yaml or toml) and exit")
 	
 	// Custom usage function
 	flag.Usage = func() {
@@ -51,6 +72,11 @@ func ParseArgs() (*CLIArgs, error) {
 	}
 	
 	flag.Parse()
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "timeout" {
+			args.TimeoutSet = true
+		}
+	})
 	
 	// Handle remaining arguments as query if no -q flag
 	if args.Query == "" && len(flag.Args()) > 0 {
@@ -80,9 +106,36 @@ func validateArgs(args *CLIArgs) error {
 		return fmt.Errorf("invalid mode: %s. Valid modes: %s", args.Mode, strings.Join(validModes, ", "))
 	}
 	
-	// Query mode requires a query
-	if args.Mode == "query" && args.Query == "" {
-		return fmt.Errorf("query mode requires a query (-q or --query)")
+	// Query mode requires a query, unless queries are piped on stdin
+	if args.Mode == "query" && args.Query == "" && !StdinPiped() {
+		return fmt.Errorf("query mode requires a query (-q, --query or piped on stdin)")
+	}
+
+	// --ask keeps the session open, so it only makes sense interactively
+	if args.Ask != "" && args.Mode != "interactive" {
+		return fmt.Errorf("--ask is only supported in interactive mode; use -q for a single query")
+	}
+
+	if args.OutputFormat != "text" && args.OutputFormat != "json" {
+		return fmt.Errorf("invalid --output-format: %s. Valid formats: text, json", args.OutputFormat)
+	}
+	if args.OutputFormat == "json" && args.Mode == "interactive" {
+		return fmt.Errorf("--output-format json needs a non-interactive mode, e.g. -m query")
+	}
+
+	if args.ConfigFormat != "" {
+		if err := config.SetConfigFormat(args.ConfigFormat); err != nil {
+			return fmt.Errorf("invalid --config-format: %v", err)
+		}
+	}
+	if args.ConvertTo != "" {
+		if _, err := config.ParseConfigFormat(args.ConvertTo); err != nil {
+			return fmt.Errorf("invalid --convert-config: %v", err)
+		}
+	}
+
+	if args.TimeoutSet && args.Timeout < 0 {
+		return fmt.Errorf("invalid --timeout: %d. Use a positive number of seconds, or 0 for no timeout", args.Timeout)
 	}
 	
 	return nil
@@ -107,7 +160,14 @@ Options:
   -i, --interactive      Force interactive mode
   -c, --config FILE      Path to config file
   -o, --output FILE      Output file for responses
+  -a, --ask PROMPT       Send PROMPT at startup, then keep chatting
   --no-context          Disable project context analysis
+  --safe                Read-only mode: the agent never writes files
+  --timeout SECONDS     Override the page and response timeouts (0 = no timeout)
+  -y, --yes             Answer yes to every confirmation prompt
+  --output-format FMT   Print responses as text (default) or json objects
+  --config-format FMT   Use configs/config.FMT (json, yaml or toml)
+  --convert-config FMT  Write the config file as FMT next to it, then exit
   -d, --debug           Enable debug mode
   -h, --help            Show this help message
   -v, --version         Show version information
@@ -118,9 +178,13 @@ Examples:
   %s -m context "help with Go project" # Context-aware mode
   %s -i --no-context                   # Interactive without context
   %s -o output.txt -q "generate docs"  # Save response to file
+  %s --ask "summarize this repo"        # Kick off, then stay interactive
+  %s --timeout 1800 -q "plan the migration" # Allow a long reasoning answer
+  echo "explain main.go" | %s -m query  # One query per piped line
+  %s -m query -q "list TODOs" --output-format json | jq .response
 
 For more information, visit: https://github.com/your-repo/chatgpt-cli
-`, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+`, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
 }
 
 // ExecuteWithArgs executes the CLI with parsed arguments
@@ -135,6 +199,10 @@ func ExecuteWithArgs(args *CLIArgs, cliInstance *CLI) error {
 		printVersion()
 		return nil
 	}
+
+	if args.ConvertTo != "" {
+		return convertConfig(args.ConvertTo)
+	}
 	
 	// Load custom config if specified
 	if args.Config != "" {
@@ -148,6 +216,9 @@ func ExecuteWithArgs(args *CLIArgs, cliInstance *CLI) error {
 	if err != nil {
 		return fmt.Errorf("failed to create agent: %v", err)
 	}
+	if args.Safe {
+		agentInstance.SetReadOnly(true)
+	}
 	
 	// Set agent mode
 	switch args.Mode {
@@ -164,11 +235,29 @@ func ExecuteWithArgs(args *CLIArgs, cliInstance *CLI) error {
 	// Initialize session unless disabled
 	if !args.NoContext {
 		if err := agentInstance.InitializeSession(); err != nil {
-			// Don't fail, just warn
-			fmt.Printf("Warning: Could not initialize project context: %v\n", err)
+			// Don't fail, just warn; stdout is kept for responses
+			fmt.Fprintf(os.Stderr, "Warning: Could not initialize project context: %v\n", err)
 		}
 	}
 	
+	// Interactive mode switches models in Start, after its welcome
+	switch args.Mode {
+	case "query", "auto", "context":
+		cliInstance.applyDefaultModel()
+	}
+
+	// Without a query, lines piped on stdin are the queries
+	if args.Mode != "interactive" && args.Query == "" && StdinPiped() {
+		cfg, _ := config.LoadDynamicConfig()
+		emit := printPlain
+		if args.OutputFormat == "json" {
+			emit = func(query, response string) {
+				printJSON(agentInstance, query, response)
+			}
+		}
+		return runBatch(os.Stdin, cfg.UI.MaxInputKB, agentInstance, agentInstance.ProcessMessage, emit)
+	}
+
 	// Execute based on mode
 	switch args.Mode {
 	case "query":
@@ -184,22 +273,56 @@ func ExecuteWithArgs(args *CLIArgs, cliInstance *CLI) error {
 	}
 }
 
-// executeQueryMode executes a single query
+// executeQueryMode executes a single query, waiting out rate limits so
+// unattended runs resume instead of aborting
 func executeQueryMode(agent *agent.Agent, args *CLIArgs) error {
-	response, err := agent.ProcessMessage(args.Query)
+	if err := checkOutputFile(agent, args.OutputFile); err != nil {
+		return err
+	}
+	cfg, _ := config.LoadDynamicConfig()
+	response, err := retryOnRateLimit(cfg.ChatGPT.RateLimit, agent, func() (string, error) {
+		return agent.ProcessMessage(args.Query)
+	})
 	if err != nil {
 		return fmt.Errorf("query failed: %v", err)
 	}
+
+	if args.OutputFormat == "json" {
+		if response, err = renderJSONResponse(agent, args.Query, response); err != nil {
+			return err
+		}
+	}
 	
 	// Output response
 	if args.OutputFile != "" {
-		return writeToFile(args.OutputFile, response)
+		return writeToFile(agent, args.OutputFile, response)
 	}
 	
 	fmt.Println(response)
 	return nil
 }
 
+// renderJSONResponse renders a response as the --output-format json object,
+// with the model and chat ID of the open chat when they can be read
+func renderJSONResponse(agent *agent.Agent, query, response string) (string, error) {
+	info, _ := agent.ChatInfo()
+	output, err := formatter.RenderJSONResponse(query, response, info.Model, info.ID, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("failed to render JSON: %v", err)
+	}
+	return output, nil
+}
+
+// printJSON prints a batch response as a JSON object
+func printJSON(agent *agent.Agent, query, response string) {
+	output, err := renderJSONResponse(agent, query, response)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	fmt.Println(output)
+}
+
 // executeInteractiveMode starts interactive mode
 func executeInteractiveMode(cliInstance *CLI, agentInstance *agent.Agent, args *CLIArgs) error {
 	// Set the agent in CLI instance
@@ -209,15 +332,21 @@ func executeInteractiveMode(cliInstance *CLI, agentInstance *agent.Agent, args *
 	return cliInstance.Start()
 }
 
-// executeAutoMode executes autonomous mode
-func executeAutoMode(agent *agent.Agent, args *CLIArgs) error {
-	// Auto mode implementation would go here
-	// For now, fall back to query mode
+// executeAutoMode executes autonomous mode: the agent, set to auto mode,
+// plans the task into subtasks and runs them one by one
+func executeAutoMode(agentInstance *agent.Agent, args *CLIArgs) error {
 	if args.Query != "" {
-		return executeQueryMode(agent, args)
+		if err := executeQueryMode(agentInstance, args); err != nil {
+			return err
+		}
+		// Report the scope of the run on stderr so stdout stays the response
+		if changes := agentInstance.Changes(); len(changes) > 0 {
+			fmt.Fprintln(os.Stderr, agent.Diffstat(changes))
+		}
+		return nil
 	}
 	
-	fmt.Println("Auto mode: Please specify a task with -q or --query")
+	ui.Statusf("Auto mode: Please specify a task with -q or --query\n")
 	return nil
 }
 
@@ -229,10 +358,10 @@ func executeContextMode(agent *agent.Agent, args *CLIArgs) error {
 	}
 	
 	// Show project context
+	// Written as status output, which --output-format json keeps off stdout
 	context := agent.GetProjectContext()
 	if context != nil {
-		fmt.Println("Project Context:")
-		fmt.Println(context.GetProjectInfo())
+		ui.Statusf("Project Context:\n%s\n", context.GetProjectInfo())
 	}
 	
 	return nil
@@ -255,8 +384,31 @@ func loadCustomConfig(configPath string) error {
 	return nil
 }
 
-// writeToFile writes content to a file
-func writeToFile(filename, content string) error {
+// convertConfig writes the config file in use in another format, for
+// --convert-config
+func convertConfig(format string) error {
+	input := config.ActiveConfigPath()
+	if err := config.ConvertFormat(input, format); err != nil {
+		return err
+	}
+	fmt.Printf("Converted %s to %s - use it with --config-format %s\n", input, format, format)
+	return nil
+}
+
+// checkOutputFile returns agent.ErrReadOnly when an output file is given
+// but safe mode is on, so a query isn't sent for an answer that can't be saved
+func checkOutputFile(a *agent.Agent, filename string) error {
+	if filename != "" && a.IsReadOnly() {
+		return agent.ErrReadOnly
+	}
+	return nil
+}
+
+// writeToFile writes content to a file unless safe mode is on
+func writeToFile(a *agent.Agent, filename, content string) error {
+	if err := checkOutputFile(a, filename); err != nil {
+		return err
+	}
 	return os.WriteFile(filename, []byte(content), 0644)
 }
 
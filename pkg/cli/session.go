@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chatgpt-element-recorder/pkg/agent"
+	"github.com/chatgpt-element-recorder/pkg/file"
+	"github.com/chatgpt-element-recorder/pkg/ui"
+)
+
+// dataDir is the directory in the home directory for state kept between runs
+const dataDir = ".gpt5dev"
+
+// sessionFile is where the working set is kept between runs, in dataDir,
+// when agent.session_persistence is on
+const sessionFile = "session.json"
+
+// defaultSessionTTL is how long a session may sit idle and still be offered
+// when agent.session_ttl_hours is unset
+const defaultSessionTTL = 24 * time.Hour
+
+// SessionState is the working set restored on the next launch
+type SessionState struct {
+	ChatID       string    `json:"chat_id,omitempty"`
+	ChatTitle    string    `json:"chat_title,omitempty"`
+	AgentMode    string    `json:"agent_mode"`
+	ProjectDir   string    `json:"project_dir"`
+	PinnedFiles  []string  `json:"pinned_files,omitempty"`
+	SystemPrompt string    `json:"system_prompt,omitempty"`
+	StartedAt    time.Time `json:"started_at"`
+	LastActivity time.Time `json:"last_activity"`
+}
+
+// dataPath returns the location of a file in dataDir
+func dataPath(name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, dataDir, name), nil
+}
+
+// loadSession reads the saved working set; a missing file is no session
+func loadSession() (*SessionState, error) {
+	path, err := dataPath(sessionFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session: %v", err)
+	}
+
+	var session SessionState
+	if err := file.ReadJSONFile(path, &session); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read session: %v", err)
+	}
+	return &session, nil
+}
+
+// Interrupted saves the session when the process is stopped by Ctrl+C or a
+// kill, which exits without returning through Start or /quit
+func (cli *CLI) Interrupted() {
+	cli.saveSession()
+}
+
+// saveSession writes the current chat, agent mode, project directory and
+// pinned files so the next launch can pick up from here. It does nothing
+// unless agent.session_persistence is on.
+func (cli *CLI) saveSession() {
+	if !cli.config.Agent.SessionPersistence || cli.agent == nil {
+		return
+	}
+
+	session := SessionState{
+		AgentMode:    string(cli.agent.GetMode()),
+		PinnedFiles:  cli.agent.PinnedFiles(),
+		SystemPrompt: cli.agent.SystemPrompt(),
+		StartedAt:    cli.chatgpt.Usage().Started,
+		LastActivity: cli.agent.LastActivity(),
+	}
+	if session.LastActivity.IsZero() {
+		session.LastActivity = time.Now()
+	}
+	if dir, err := os.Getwd(); err == nil {
+		session.ProjectDir = dir
+	}
+	if active, err := cli.chatgpt.ActiveChat(); err == nil {
+		session.ChatID, session.ChatTitle = active.ID, active.Title
+	}
+
+	path, err := dataPath(sessionFile)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+	}
+	if err != nil {
+		ui.PrintWarning(fmt.Sprintf("Could not save session: %v", err))
+		return
+	}
+	if err := file.WriteJSONFile(path, session); err != nil {
+		ui.PrintWarning(fmt.Sprintf("Could not save session: %v", err))
+	}
+}
+
+// restoreSession offers to resume the saved working set if it belongs to this
+// project and was active within agent.session_ttl_hours. It reports whether a
+// saved chat was reopened, in which case the chat already has its context and
+// the system prompt is not sent again.
+func (cli *CLI) restoreSession() bool {
+	if !cli.config.Agent.SessionPersistence || cli.agent == nil {
+		return false
+	}
+
+	session, err := loadSession()
+	if err != nil {
+		ui.PrintWarning(err.Error())
+		return false
+	}
+	if session == nil {
+		return false
+	}
+
+	ttl := time.Duration(cli.config.Agent.SessionTTLHours) * time.Hour
+	if ttl <= 0 {
+		ttl = defaultSessionTTL
+	}
+	if time.Since(session.LastActivity) > ttl {
+		return false
+	}
+	// Pinned files and the chat's context belong to the project they were saved in
+	if dir, err := os.Getwd(); err == nil && session.ProjectDir != "" && session.ProjectDir != dir {
+		return false
+	}
+
+	fmt.Println("\n💾 Previous session (last active " + session.LastActivity.Format("2006-01-02 15:04") + "):")
+	if session.ChatID != "" {
+		title := session.ChatTitle
+		if title == "" {
+			title = session.ChatID
+		}
+		fmt.Printf("   Chat: %s\n", title)
+	}
+	fmt.Printf("   Mode: %s\n", session.AgentMode)
+	if len(session.PinnedFiles) > 0 {
+		fmt.Printf("   Pinned: %s\n", strings.Join(session.PinnedFiles, ", "))
+	}
+	if session.SystemPrompt != "" {
+		fmt.Printf("   System prompt: %s\n", session.SystemPrompt)
+	}
+	if !cli.confirm("Resume previous session?") {
+		ui.PrintInfo("Starting fresh")
+		return false
+	}
+
+	if mode, ok := agent.ParseMode(session.AgentMode); ok {
+		cli.agent.SetMode(mode)
+	}
+	cli.agent.SetSystemPrompt(session.SystemPrompt)
+
+	for _, pinned := range session.PinnedFiles {
+		if err := cli.agent.PinFile(pinned); err != nil {
+			ui.PrintWarning(fmt.Sprintf("Not re-pinning %s: it no longer exists", pinned))
+		}
+	}
+
+	if session.ChatID == "" {
+		return false
+	}
+	if !cli.reopenChat(session.ChatID) {
+		ui.PrintWarning("The saved chat no longer exists - starting a new one")
+		return false
+	}
+	ui.PrintSuccess("Session restored")
+	return true
+}
+
+// reopenChat opens a saved chat and reports whether it still exists. ChatGPT
+// redirects deleted or unknown chats to a new chat, so the URL it lands on
+// must still name the chat.
+func (cli *CLI) reopenChat(chatID string) bool {
+	spinner := ui.NewSquareSpinner()
+	spinner.Start("Reopening saved chat...")
+	err := cli.chatgpt.OpenChat(chatID)
+	spinner.Stop()
+	if err != nil {
+		return false
+	}
+
+	active, err := cli.chatgpt.ActiveChat()
+	if err != nil || active.ID != chatID {
+		// Leave the stale chat's page for a clean new chat
+		if err := cli.chatgpt.StartNewChat(); err != nil {
+			ui.PrintWarning(fmt.Sprintf("Could not start a new chat: %v", err))
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/chatgpt-element-recorder/pkg/ui"
+)
+
+// SessionStats counts what the CLI has exchanged since it started
+type SessionStats struct {
+	Started       time.Time
+	MessagesSent  int
+	CharsReceived int
+	TotalLatency  time.Duration // time from sending to having the whole answer, summed
+	CacheHits     int           // messages answered from the cache, which count nowhere else
+}
+
+// record adds one answered message that took latency to arrive
+func (s *SessionStats) record(response string, latency time.Duration) {
+	s.MessagesSent++
+	s.CharsReceived += utf8.RuneCountInString(response)
+	s.TotalLatency += latency
+}
+
+// showStats prints the session statistics as a table
+func (cli *CLI) showStats() {
+	stats := cli.sessionStats
+	average := "-"
+	if stats.MessagesSent > 0 {
+		average = (stats.TotalLatency / time.Duration(stats.MessagesSent)).Round(100 * time.Millisecond).String()
+	}
+
+	rows := [][]string{
+		{"Messages sent", fmt.Sprintf("%d", stats.MessagesSent)},
+		{"Characters received", fmt.Sprintf("%d", stats.CharsReceived)},
+		{"Estimated tokens", fmt.Sprintf("~%d", (stats.CharsReceived+3)/4)},
+		{"Session duration", time.Since(stats.Started).Round(time.Second).String()},
+		{"Average response time", average},
+		{"Answered from cache", fmt.Sprintf("%d", stats.CacheHits)},
+	}
+
+	fmt.Println("\n📊 Session Statistics:")
+	fmt.Print(ui.RenderTable([]string{"Statistic", "Value"}, rows))
+}
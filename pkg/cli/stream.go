@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chatgpt-element-recorder/pkg/ui"
+)
+
+// streamLookahead is how many complete lines a streamed line waits behind.
+// Code highlighting decides a line's block from up to two lines after it.
+const streamLookahead = 3
+
+// respond sends a chat message and prints the answer, streamed while it is
+// written when ui.stream_responses is on
+func (cli *CLI) respond(message string) {
+	var err error
+	if cli.config.UI.StreamResponses {
+		_, err = cli.streamMessage(message)
+	} else {
+		var response string
+		if response, err = cli.sendTyped(message); err == nil {
+			cli.printResponse(response)
+		}
+	}
+	cli.lastSent, cli.lastSentAt = message, time.Now()
+	if err != nil {
+		cli.handleSendError(err)
+		return
+	}
+	cli.printSources()
+}
+
+// streamMessage sends a message and renders the answer in the response box
+// line by line as it arrives, returning the whole answer as the page settled it
+func (cli *CLI) streamMessage(message string) (string, error) {
+	if cli.agent != nil && cli.agent.ShouldRotateChat(message) {
+		cli.rotateChat()
+	}
+	if cli.agent != nil {
+		message = cli.agent.PreparePrompt(message)
+	}
+
+	started := time.Now()
+	spinner := ui.NewSpinner()
+	spinner.Start("")
+	chunks, err := cli.chatgpt.SendMessageStream(context.Background(), message)
+	if err != nil {
+		spinner.Stop()
+		return "", err
+	}
+
+	var response strings.Builder
+	renderer := newStreamRenderer(cli.config.UI.Colors)
+	for chunk := range chunks {
+		if response.Len() == 0 {
+			// The box replaces the spinner once the answer starts
+			spinner.Stop()
+			fmt.Println()
+			printBoxTop(renderer.boxWidth)
+		}
+		response.WriteString(chunk)
+		renderer.update(response.String(), false)
+	}
+	spinner.Stop()
+
+	if response.Len() > 0 {
+		renderer.update(response.String(), true)
+		fmt.Print(ui.Reset)
+		printBoxBottom(renderer.boxWidth)
+	}
+	final, err := cli.chatgpt.StreamResult()
+	if err != nil {
+		return "", err
+	}
+
+	cli.lastResponse = final
+	cli.sessionStats.record(cli.lastResponse, time.Since(started))
+	notify := cli.config.UI.Notify
+	if notify.OnComplete {
+		if elapsed := time.Since(started); elapsed >= time.Duration(notify.Threshold)*time.Second {
+			ui.NotifyComplete(elapsed, notify.Desktop)
+		}
+	}
+	return final, nil
+}
+
+// streamRenderer draws a growing answer into the response box, printing each
+// line once enough lines follow it for its highlighting to be settled
+type streamRenderer struct {
+	boxWidth int
+	codeBg   string
+	codeFg   string
+	printed  int // rendered lines already on screen
+}
+
+func newStreamRenderer(colors map[string]string) *streamRenderer {
+	codeBg, codeFg := ui.CodeColors(colors)
+	return &streamRenderer{boxWidth: ui.GetTerminalWidth(), codeBg: codeBg, codeFg: codeFg}
+}
+
+// update prints the lines of text that are now settled; with final set the
+// text is complete and every remaining line is printed
+func (r *streamRenderer) update(text string, final bool) {
+	lines := strings.Split(text, "\n")
+	if !final {
+		// The last line is still being written
+		lines = lines[:len(lines)-1]
+	}
+
+	rendered := ui.ProcessResponseWithCodeHighlight(strings.Join(lines, "\n"))
+	settled := len(rendered)
+	if !final {
+		if len(lines) <= streamLookahead {
+			return
+		}
+		prefix := strings.Join(lines[:len(lines)-streamLookahead], "\n")
+		settled = len(ui.ProcessResponseWithCodeHighlight(prefix))
+	}
+
+	for ; r.printed < settled && r.printed < len(rendered); r.printed++ {
+		printBoxLine(rendered[r.printed], r.boxWidth, r.codeBg, r.codeFg)
+	}
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chatgpt-element-recorder/pkg/chatgpt"
+	"github.com/chatgpt-element-recorder/pkg/file"
+	"github.com/chatgpt-element-recorder/pkg/ui"
+)
+
+// tagsFile is where /tag stores chat labels, in dataDir
+const tagsFile = "tags.json"
+
+// chatTags maps chat IDs to their tags
+type chatTags map[string][]string
+
+// loadTags reads the tag store; a missing file has no tags
+func loadTags() (chatTags, error) {
+	tags := chatTags{}
+	path, err := dataPath(tagsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tags: %v", err)
+	}
+	if err := file.ReadJSONFile(path, &tags); err != nil {
+		if os.IsNotExist(err) {
+			return chatTags{}, nil
+		}
+		return nil, fmt.Errorf("failed to read tags: %v", err)
+	}
+	return tags, nil
+}
+
+// saveTags writes the tag store
+func saveTags(tags chatTags) error {
+	path, err := dataPath(tagsFile)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+	}
+	if err == nil {
+		err = file.WriteJSONFile(path, tags)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save tags: %v", err)
+	}
+	return nil
+}
+
+// has reports whether a chat carries tag, ignoring case
+func (t chatTags) has(chatID, tag string) bool {
+	for _, existing := range t[chatID] {
+		if strings.EqualFold(existing, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply fills in the Tags of each history item
+func (t chatTags) apply(history []chatgpt.ChatHistoryItem) {
+	for i := range history {
+		history[i].Tags = t[history[i].ID]
+	}
+}
+
+// tagChat labels the current chat with tag
+func (cli *CLI) tagChat(tag string) error {
+	active, err := cli.chatgpt.ActiveChat()
+	if err != nil {
+		return err
+	}
+
+	tags, err := loadTags()
+	if err != nil {
+		return err
+	}
+	if tags.has(active.ID, tag) {
+		ui.PrintInfo(fmt.Sprintf("This chat is already tagged %s", tag))
+		return nil
+	}
+
+	tags[active.ID] = append(tags[active.ID], tag)
+	if err := saveTags(tags); err != nil {
+		return err
+	}
+	ui.PrintSuccess(fmt.Sprintf("Tagged this chat %s (%s)", tag, strings.Join(tags[active.ID], ", ")))
+	return nil
+}
+
+// showTags lists every tag with the number of chats carrying it
+func (cli *CLI) showTags() error {
+	tags, err := loadTags()
+	if err != nil {
+		return err
+	}
+
+	counts := map[string]int{}
+	names := map[string]string{} // first spelling seen of each tag
+	for _, chatTagList := range tags {
+		for _, tag := range chatTagList {
+			key := strings.ToLower(tag)
+			if _, ok := names[key]; !ok {
+				names[key] = tag
+			}
+			counts[key]++
+		}
+	}
+	if len(counts) == 0 {
+		ui.PrintInfo("No tags yet - use /tag <tag> to label the current chat")
+		return nil
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Println("\n🏷️  Tags:")
+	for _, key := range keys {
+		fmt.Printf("  %-20s %d chat(s)\n", names[key], counts[key])
+	}
+	fmt.Println()
+	ui.PrintInfo("Use '/history --tag <tag>' to list a tag's chats")
+	return nil
+}
@@ -4,10 +4,31 @@ package config
 func getDefaultConfig() *DynamicConfig {
 	return &DynamicConfig{
 		ChatGPT: ChatGPTConfig{
-			BaseURL:       "https://chatgpt.com",
-			Timeout:       300,
-			RetryAttempts: 3,
-			WaitTimeout:   30,
+			BaseURL:                   "https://chatgpt.com",
+			Timeout:                   300,
+			RetryAttempts:             3,
+			RetryBackoffMs:            1000,
+			WaitTimeout:               30,
+			AutoSelectTab:             true,
+			ScrapeStrategy:            "selector",
+			ExtractorJS:               "",
+			ResponseFormat:            "text",
+			DefaultModel:              "",
+			UIActionLabels:            []string{"Copy", "Edit", "Regenerate", "Share", "Good response", "Bad response", "Read aloud", "More actions"},
+			CookieSaveIntervalSeconds: 300,
+			RateLimit: RateLimitConfig{
+				Cooldown:   30,
+				MaxRetries: 3,
+				MaxWait:    600,
+			},
+			Citations: CitationsConfig{
+				Strip:          true,
+				CollectSources: false,
+			},
+			Keepalive: KeepaliveConfig{
+				Enabled:  false,
+				Interval: 240,
+			},
 		},
 		Browser: BrowserConfig{
 			Headless:          false,
@@ -15,6 +36,9 @@ func getDefaultConfig() *DynamicConfig {
 			UserAgent:         "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
 			DisableAutomation: true,
 			DisableExtensions: false,
+			AllowedDomains:    []string{},
+			ProxyServer:       "",
+			ProxyBypass:       "",
 		},
 		Files: FilesConfig{
 			CookiesFile: "cookies/chatgpt.json",
@@ -22,9 +46,10 @@ func getDefaultConfig() *DynamicConfig {
 			ConfigDir:   "configs",
 		},
 		UI: UIConfig{
-			SpinnerType: "square",
-			TypingSpeed: 30,
-			BorderSpeed: 10,
+			SpinnerType:  "square",
+			TypingEffect: true,
+			TypingSpeed:  30,
+			BorderSpeed:  10,
 			Colors: map[string]string{
 				"success": "\033[32m",
 				"error":   "\033[31m",
@@ -32,13 +57,55 @@ func getDefaultConfig() *DynamicConfig {
 				"info":    "\033[36m",
 				"dim":     "\033[2m",
 				"reset":   "\033[0m",
+				"code_bg": "\033[48;5;17m",
+				"code_fg": "\033[97m",
+			},
+			SendMode: "enter",
+			Notify: NotifyConfig{
+				OnComplete: false,
+				Threshold:  20,
+				Desktop:    false,
 			},
+			DuplicateGuard: DuplicateGuardConfig{
+				Enabled: true,
+				Window:  30,
+			},
+			ShowTOC:           true,
+			UnescapeInput:     false,
+			MaxInputKB:        1024,
+			QuietSystemPrompt: false,
+			OmitSystemPrompt:  false,
+			StreamResponses:   false,
+			LineEditing:       true,
+			PageSize:          10,
 		},
 		Agent: AgentConfig{
 			Mode:               "interactive",
 			AutoContext:        true,
 			ProjectAnalysis:    true,
 			SessionPersistence: true,
+			HideReasoning:      true,
+			AutoRotateChat:     false,
+			RotateThreshold:    60000,
+			MaxListedFiles:     15,
+			PromptPipeline:     []string{"file_refs", "redact", "context"},
+			MentionBudget:      24000,
+			ReadOnly:           false,
+			AllowWrite:         false,
+			AnalysisDepth:      3,
+			AutoConfirm:        false,
+			MaxExecOutputBytes: 8192,
+			MaxAutoSteps:       10,
+			FileExcerptLines:   200,
+			SessionTTLHours:    24,
+			MaxContentMatches:  500,
+			CacheEnabled:       true,
+			CacheSize:          50,
+		},
+		History: HistoryConfig{
+			ScrollSteps:  5,
+			ScrollDelay:  150,
+			DisplayLimit: 20,
 		},
 	}
 }
@@ -49,9 +116,10 @@ func getDefaultSelectors() *Selectors {
 		Input: SelectorGroup{
 			Primary: "#prompt-textarea",
 			Fallback: []string{
-				"textarea[placeholder*='Message']",
+				"textarea[name='prompt-textarea']",
 				"textarea[data-id='root']",
 				"[contenteditable='true'][data-testid*='textbox']",
+				"form [contenteditable='true'][translate='no']",
 			},
 		},
 		SendButton: SelectorGroup{
@@ -85,6 +153,28 @@ func getDefaultSelectors() *Selectors {
 			"signup_button": "[data-testid='signup-button']",
 			"user_menu":     "[data-testid='user-menu']",
 		},
+		ModelPicker: SelectorGroup{
+			Primary: "[data-testid='model-switcher-dropdown-button']",
+			Fallback: []string{
+				"button[aria-label*='Model selector']",
+				"button[aria-haspopup='menu'][id^='radix-']:has(svg)",
+			},
+		},
+		ModelOption: SelectorGroup{
+			Primary: "[data-testid^='model-switcher-'][role='menuitem']",
+			Fallback: []string{
+				"[role='menuitemradio']",
+				"[role='menu'] [role='menuitem']",
+			},
+		},
+		InputPlaceholders: SelectorMap{
+			"en": "Message",
+			"de": "Nachricht",
+			"es": "Mensaje",
+			"fr": "message",
+			"id": "Pesan",
+			"pt": "Mensagem",
+		},
 	}
 }
 
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"net/url"
+	"strings"
+)
+
+// defaultAllowedDomains are always trusted for navigation and cookie loading
+var defaultAllowedDomains = []string{"chatgpt.com", "openai.com"}
+
+// AllowedDomains returns the built-in ChatGPT domains merged with the host of
+// chatgpt.base_url and browser.allowed_domains, without duplicates
+func (c *DynamicConfig) AllowedDomains() []string {
+	candidates := append([]string{}, defaultAllowedDomains...)
+	if u, err := url.Parse(c.ChatGPT.BaseURL); err == nil && u.Hostname() != "" {
+		candidates = append(candidates, u.Hostname())
+	}
+	candidates = append(candidates, c.Browser.AllowedDomains...)
+
+	var domains []string
+	seen := make(map[string]bool)
+	for _, d := range candidates {
+		d = normalizeDomain(d)
+		if d != "" && !seen[d] {
+			seen[d] = true
+			domains = append(domains, d)
+		}
+	}
+	return domains
+}
+
+// IsAllowedDomain reports whether a host or cookie domain (".example.com")
+// is an allowed domain or one of its subdomains
+func (c *DynamicConfig) IsAllowedDomain(domain string) bool {
+	domain = normalizeDomain(domain)
+	if domain == "" {
+		return false
+	}
+	for _, allowed := range c.AllowedDomains() {
+		if domain == allowed || strings.HasSuffix(domain, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAllowedURL reports whether rawURL points at an allowed domain
+func (c *DynamicConfig) IsAllowedURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return c.IsAllowedDomain(u.Hostname())
+}
+
+// normalizeDomain lowercases a domain and drops a cookie-style leading dot
+func normalizeDomain(domain string) string {
+	return strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
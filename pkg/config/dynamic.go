@@ -15,24 +15,69 @@ type DynamicConfig struct {
 	Files   FilesConfig   `json:"files"`
 	UI      UIConfig      `json:"ui"`
 	Agent   AgentConfig   `json:"agent"`
+	History HistoryConfig `json:"history"`
 	mu      sync.RWMutex  `json:"-"`
+
+	envOverrides map[string]envOverride // settings taken from the environment, by variable
 }
 
 // ChatGPTConfig contains ChatGPT-specific settings
 type ChatGPTConfig struct {
-	BaseURL       string `json:"base_url"`
-	Timeout       int    `json:"timeout"`
-	RetryAttempts int    `json:"retry_attempts"`
-	WaitTimeout   int    `json:"wait_timeout"`
+	BaseURL                   string          `json:"base_url"`
+	Timeout                   int             `json:"timeout"`
+	RetryAttempts             int             `json:"retry_attempts"`
+	RetryBackoffMs            int             `json:"retry_backoff_ms"`             // wait before the first retry of a failed send, doubled for each further retry
+	WaitTimeout               int             `json:"wait_timeout"`
+	Debug                     bool            `json:"debug"`
+	AutoSelectTab             bool            `json:"auto_select_tab"`              // bind to a ChatGPT tab when several are open
+	ScrapeStrategy            string          `json:"scrape_strategy"`              // "selector" or "js"
+	ExtractorJS               string          `json:"extractor_js"`                 // custom JS returning the last answer's text, for "js"
+	ResponseFormat            string          `json:"response_format"`              // "text" (rendered) or "markdown" (source via the copy button)
+	DefaultModel              string          `json:"default_model"`                // model picked at startup; empty keeps ChatGPT's choice
+	UIActionLabels            []string        `json:"ui_action_labels"`             // action-row text trimmed from the end of responses
+	CookieSaveIntervalSeconds int             `json:"cookie_save_interval_seconds"` // least time between background cookie saves after sends; 0 turns them off
+	RateLimit                 RateLimitConfig `json:"rate_limit"`
+	Citations                 CitationsConfig `json:"citations"`
+	Keepalive                 KeepaliveConfig `json:"keepalive"`
+}
+
+// KeepaliveConfig controls touching the page while idle so the session isn't logged out
+type KeepaliveConfig struct {
+	Enabled  bool `json:"enabled"`
+	Interval int  `json:"interval"` // seconds of idleness between keepalives
+}
+
+// CitationsConfig controls the citation chips browsing answers carry
+type CitationsConfig struct {
+	Strip          bool `json:"strip"`           // leave citation chips and markers out of scraped text
+	CollectSources bool `json:"collect_sources"` // gather the cited links for a separate "Sources:" footer
+}
+
+// RateLimitConfig controls waiting out rate-limit toasts before retrying a send
+type RateLimitConfig struct {
+	Cooldown   int `json:"cooldown"`    // seconds to wait when the toast doesn't say how long
+	MaxRetries int `json:"max_retries"` // retries of the same step before giving up
+	MaxWait    int `json:"max_wait"`    // longest cooldown, in seconds, worth waiting for
 }
 
 // BrowserConfig contains browser automation settings
 type BrowserConfig struct {
-	Headless           bool   `json:"headless"`
-	WindowSize         string `json:"window_size"`
-	UserAgent          string `json:"user_agent"`
-	DisableAutomation  bool   `json:"disable_automation"`
-	DisableExtensions  bool   `json:"disable_extensions"`
+	Headless           bool     `json:"headless"`
+	WindowSize         string   `json:"window_size"`
+	UserAgent          string   `json:"user_agent"`
+	DisableAutomation  bool     `json:"disable_automation"`
+	DisableExtensions  bool     `json:"disable_extensions"`
+	AllowedDomains     []string `json:"allowed_domains"` // extra domains for custom/enterprise instances, merged with the defaults
+	ProxyServer        string   `json:"proxy_server"`    // e.g. http://proxy:8080 or socks5://proxy:1080; empty connects directly
+	ProxyBypass        string   `json:"proxy_bypass"`    // semicolon-separated hosts that skip the proxy
+	// ProxyAuth comes from GPT5DEV_PROXY_USER / GPT5DEV_PROXY_PASS and is never saved
+	ProxyAuth ProxyAuth `json:"-"`
+}
+
+// ProxyAuth holds the credentials for an authenticating proxy
+type ProxyAuth struct {
+	Username string
+	Password string
 }
 
 // FilesConfig contains file path settings
@@ -44,18 +89,70 @@ type FilesConfig struct {
 
 // UIConfig contains UI appearance settings
 type UIConfig struct {
-	SpinnerType  string            `json:"spinner_type"`
-	TypingSpeed  int               `json:"typing_speed"`
-	BorderSpeed  int               `json:"border_speed"`
-	Colors       map[string]string `json:"colors"`
+	SpinnerType    string               `json:"spinner_type"`
+	TypingEffect   bool                 `json:"typing_effect"` // print responses character by character
+	TypingSpeed    int                  `json:"typing_speed"`
+	BorderSpeed    int                  `json:"border_speed"`
+	Colors         map[string]string    `json:"colors"`
+	SendMode       string               `json:"send_mode"` // "enter" or "double-enter"
+	Notify         NotifyConfig         `json:"notify"`
+	DuplicateGuard DuplicateGuardConfig `json:"duplicate_guard"`
+	ShowTOC        bool                 `json:"show_toc"`       // table of contents above answers with 3+ headers
+	UnescapeInput  bool                 `json:"unescape_input"` // turn \n and \t typed in a message into real newlines/tabs
+	MaxInputKB     int                  `json:"max_input_kb"`   // longest single input line accepted, in KB
+	// QuietSystemPrompt sends the project system prompt without any output
+	// beyond the spinner; OmitSystemPrompt also leaves that exchange out of
+	// transcripts (/copy-all, /read-chat, /branch)
+	QuietSystemPrompt bool `json:"quiet_system_prompt"`
+	OmitSystemPrompt  bool `json:"omit_system_prompt"`
+	StreamResponses   bool `json:"stream_responses"` // render answers while ChatGPT is still writing them
+	LineEditing       bool `json:"line_editing"`     // arrow-key history, Ctrl-R search and Tab completion at the prompt
+	PageSize          int  `json:"page_size"`        // chats per /history page
+}
+
+// DuplicateGuardConfig controls the confirmation before resending the same prompt
+type DuplicateGuardConfig struct {
+	Enabled bool `json:"enabled"`
+	Window  int  `json:"window"` // seconds after a send in which a repeat asks first
+}
+
+// NotifyConfig controls the response-complete notification
+type NotifyConfig struct {
+	OnComplete bool `json:"on_complete"`
+	Threshold  int  `json:"threshold"` // seconds a generation must take before notifying
+	Desktop    bool `json:"desktop"`
 }
 
 // AgentConfig contains agent behavior settings
 type AgentConfig struct {
-	Mode               string `json:"mode"`
-	AutoContext        bool   `json:"auto_context"`
-	ProjectAnalysis    bool   `json:"project_analysis"`
-	SessionPersistence bool   `json:"session_persistence"`
+	Mode               string   `json:"mode"`
+	AutoContext        bool     `json:"auto_context"`
+	ProjectAnalysis    bool     `json:"project_analysis"`
+	SessionPersistence bool     `json:"session_persistence"`
+	HideReasoning      bool     `json:"hide_reasoning"`
+	AutoRotateChat     bool     `json:"auto_rotate_chat"`
+	RotateThreshold    int      `json:"rotate_threshold"` // estimated tokens before rotating
+	MaxListedFiles     int      `json:"max_listed_files"` // per category in the project summary, 0 for all
+	PromptPipeline     []string `json:"prompt_pipeline"`  // transforms applied before sending, in order
+	MentionBudget      int      `json:"mention_budget"`   // characters of file content @-mentions may inject per prompt
+	ReadOnly           bool     `json:"read_only"`        // safe mode: disallow all file writes
+	AnalysisDepth      int      `json:"analysis_depth"`   // directory levels scanned for project context, 1 for the top level only
+	AutoConfirm        bool     `json:"auto_confirm"`     // answer yes to every confirmation prompt, like --yes
+	MaxExecOutputBytes int      `json:"max_exec_output_bytes"` // command output /exec sends, longer output is truncated
+	MaxAutoSteps       int      `json:"max_auto_steps"`        // subtasks an auto mode plan may run
+	FileExcerptLines   int      `json:"file_excerpt_lines"`    // lines kept from each end of a file too large for /file
+	SessionTTLHours    int      `json:"session_ttl_hours"`     // saved sessions idle longer than this are not offered for restore
+	MaxContentMatches  int      `json:"max_content_matches"`   // lines /grep returns at most
+	CacheEnabled       bool     `json:"cache_enabled"`         // answer a repeated message from the cache instead of resending it
+	CacheSize          int      `json:"cache_size"`            // responses the cache holds
+	AllowWrite         bool     `json:"allow_write"`           // let the agent apply diffs to project files; off by default
+}
+
+// HistoryConfig contains chat history scraping settings
+type HistoryConfig struct {
+	ScrollSteps  int `json:"scroll_steps"`  // sidebar scroll steps per /history page, 0 to scroll until no new chats load
+	ScrollDelay  int `json:"scroll_delay"`  // milliseconds between scroll steps
+	DisplayLimit int `json:"display_limit"` // chats /history pages through, 0 for all
 }
 
 // Selectors represents CSS selectors configuration
@@ -66,6 +163,11 @@ type Selectors struct {
 	ChatControls   SelectorMap   `json:"chat_controls"`
 	PageElements   SelectorMap   `json:"page_elements"`
 	Authentication SelectorMap   `json:"authentication"`
+	ModelPicker    SelectorGroup `json:"model_picker"`
+	ModelOption    SelectorGroup `json:"model_option"`
+	// InputPlaceholders maps a UI locale to text in the prompt box's placeholder.
+	// Matching on it is the last resort, after every attribute-based selector.
+	InputPlaceholders SelectorMap `json:"input_placeholders"`
 }
 
 // SelectorGroup represents a primary selector with fallbacks
@@ -115,21 +217,27 @@ var (
 	globalSelectors *Selectors
 	globalPrompts   *Prompts
 	configOnce      sync.Once
+	cacheMu         sync.Mutex // guards globalSelectors and globalPrompts
 )
 
-// LoadDynamicConfig loads configuration from JSON files
+// LoadDynamicConfig loads the configuration from configs/config.json, or its
+// YAML or TOML counterpart (see ActiveConfigPath)
 func LoadDynamicConfig() (*DynamicConfig, error) {
 	var err error
 	configOnce.Do(func() {
 		globalConfig, err = loadConfigFromFile()
+		recordLoad(ActiveConfigPath(), err)
 	})
 	return globalConfig, err
 }
 
 // GetSelectors loads and returns CSS selectors
 func GetSelectors() (*Selectors, error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
 	if globalSelectors == nil {
 		selectors, err := loadSelectorsFromFile()
+		recordLoad(SelectorsPath, err)
 		if err != nil {
 			return nil, err
 		}
@@ -140,8 +248,11 @@ func GetSelectors() (*Selectors, error) {
 
 // GetPrompts loads and returns system prompts
 func GetPrompts() (*Prompts, error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
 	if globalPrompts == nil {
 		prompts, err := loadPromptsFromFile()
+		recordLoad(PromptsPath, err)
 		if err != nil {
 			return nil, err
 		}
@@ -152,24 +263,27 @@ func GetPrompts() (*Prompts, error) {
 
 // loadConfigFromFile loads main configuration
 func loadConfigFromFile() (*DynamicConfig, error) {
-	configPath := "configs/config.json"
-	data, err := os.ReadFile(configPath)
+	// Start from defaults so keys missing from the file keep sensible values
+	config := getDefaultConfig()
+	data, err := os.ReadFile(ActiveConfigPath())
 	if err != nil {
-		return getDefaultConfig(), fmt.Errorf("failed to read config file: %v", err)
+		applyEnvOverrides(config)
+		return config, fmt.Errorf("failed to read config file: %v", err)
 	}
 
-	var config DynamicConfig
-	if err := json.Unmarshal(data, &config); err != nil {
-		return getDefaultConfig(), fmt.Errorf("failed to parse config file: %v", err)
+	if err := unmarshalConfig(data, activeConfigFormat(), config); err != nil {
+		config = getDefaultConfig()
+		applyEnvOverrides(config)
+		return config, fmt.Errorf("failed to parse config file: %v", err)
 	}
 
-	return &config, nil
+	applyEnvOverrides(config)
+	return config, nil
 }
 
 // loadSelectorsFromFile loads CSS selectors
 func loadSelectorsFromFile() (*Selectors, error) {
-	selectorsPath := "configs/selectors.json"
-	data, err := os.ReadFile(selectorsPath)
+	data, err := os.ReadFile(SelectorsPath)
 	if err != nil {
 		return getDefaultSelectors(), fmt.Errorf("failed to read selectors file: %v", err)
 	}
@@ -184,8 +298,7 @@ func loadSelectorsFromFile() (*Selectors, error) {
 
 // loadPromptsFromFile loads system prompts
 func loadPromptsFromFile() (*Prompts, error) {
-	promptsPath := "configs/prompts.json"
-	data, err := os.ReadFile(promptsPath)
+	data, err := os.ReadFile(PromptsPath)
 	if err != nil {
 		return getDefaultPrompts(), fmt.Errorf("failed to read prompts file: %v", err)
 	}
@@ -198,24 +311,28 @@ func loadPromptsFromFile() (*Prompts, error) {
 	return &prompts, nil
 }
 
-// SaveConfig saves the current configuration to file
+// SaveConfig saves the current configuration to the file it was loaded from,
+// in that file's format
 func (c *DynamicConfig) SaveConfig() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	configPath := "configs/config.json"
-	
+	path := ActiveConfigPath()
 	// Ensure config directory exists
-	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %v", err)
 	}
 
-	data, err := json.MarshalIndent(c, "", "  ")
+	var data []byte
+	err := c.withFileValues(func() (err error) {
+		data, err = marshalConfig(c, activeConfigFormat())
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %v", err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0644); err != nil {
+	if err := os.WriteFile(path, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %v", err)
 	}
 
@@ -254,4 +371,12 @@ func (c *DynamicConfig) GetBaseURL() string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	return c.ChatGPT.BaseURL
-}
\ No newline at end of file
+}
+
+// IsDebug reports whether debug logging is on. Background goroutines use it
+// rather than reading ChatGPT.Debug, which a reload may be replacing.
+func (c *DynamicConfig) IsDebug() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ChatGPT.Debug
+}
@@ -0,0 +1,168 @@
+package config
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// envPrefix starts every environment variable the configuration reads
+const envPrefix = "GPT5DEV_"
+
+// Environment variables read into the configuration. Each overrides the
+// config.json key noted beside it; the proxy credentials have no key and are
+// only ever taken from here so they never end up in config.json.
+//
+// Booleans accept what strconv.ParseBool does (1, true, 0, false, ...).
+const (
+	EnvBaseURL         = "GPT5DEV_BASE_URL"         // chatgpt.base_url
+	EnvTimeout         = "GPT5DEV_TIMEOUT"          // chatgpt.timeout
+	EnvDebug           = "GPT5DEV_DEBUG"            // chatgpt.debug
+	EnvDefaultModel    = "GPT5DEV_DEFAULT_MODEL"    // chatgpt.default_model
+	EnvHeadless        = "GPT5DEV_HEADLESS"         // browser.headless
+	EnvUserAgent       = "GPT5DEV_USER_AGENT"       // browser.user_agent
+	EnvProxyServer     = "GPT5DEV_PROXY_SERVER"     // browser.proxy_server
+	EnvProxyUser       = "GPT5DEV_PROXY_USER"       // proxy username, never saved
+	EnvProxyPass       = "GPT5DEV_PROXY_PASS"       // proxy password, never saved
+	EnvCookiesFile     = "GPT5DEV_COOKIES_FILE"     // files.cookies_file
+	EnvOutputDir       = "GPT5DEV_OUTPUT_DIR"       // files.output_dir
+	EnvStreamResponses = "GPT5DEV_STREAM_RESPONSES" // ui.stream_responses
+	EnvAgentMode       = "GPT5DEV_AGENT_MODE"       // agent.mode
+	EnvReadOnly        = "GPT5DEV_READ_ONLY"        // agent.read_only
+	EnvAutoConfirm     = "GPT5DEV_AUTO_CONFIRM"     // agent.auto_confirm
+
+	// EnvConfigFormat picks the config file by format (json, yaml or toml)
+	// rather than by which one exists; --config-format takes precedence
+	EnvConfigFormat = "GPT5DEV_CONFIG_FORMAT"
+)
+
+// envFields maps each environment variable to the field it sets, which must
+// be a *string, *bool or *int
+var envFields = map[string]func(c *DynamicConfig) interface{}{
+	EnvBaseURL:         func(c *DynamicConfig) interface{} { return &c.ChatGPT.BaseURL },
+	EnvTimeout:         func(c *DynamicConfig) interface{} { return &c.ChatGPT.Timeout },
+	EnvDebug:           func(c *DynamicConfig) interface{} { return &c.ChatGPT.Debug },
+	EnvDefaultModel:    func(c *DynamicConfig) interface{} { return &c.ChatGPT.DefaultModel },
+	EnvHeadless:        func(c *DynamicConfig) interface{} { return &c.Browser.Headless },
+	EnvUserAgent:       func(c *DynamicConfig) interface{} { return &c.Browser.UserAgent },
+	EnvProxyServer:     func(c *DynamicConfig) interface{} { return &c.Browser.ProxyServer },
+	EnvProxyUser:       func(c *DynamicConfig) interface{} { return &c.Browser.ProxyAuth.Username },
+	EnvProxyPass:       func(c *DynamicConfig) interface{} { return &c.Browser.ProxyAuth.Password },
+	EnvCookiesFile:     func(c *DynamicConfig) interface{} { return &c.Files.CookiesFile },
+	EnvOutputDir:       func(c *DynamicConfig) interface{} { return &c.Files.OutputDir },
+	EnvStreamResponses: func(c *DynamicConfig) interface{} { return &c.UI.StreamResponses },
+	EnvAgentMode:       func(c *DynamicConfig) interface{} { return &c.Agent.Mode },
+	EnvReadOnly:        func(c *DynamicConfig) interface{} { return &c.Agent.ReadOnly },
+	EnvAutoConfirm:     func(c *DynamicConfig) interface{} { return &c.Agent.AutoConfirm },
+}
+
+// envOverride is a setting taken from the environment, with the value the
+// config file gave it, which is what SaveConfig writes back
+type envOverride struct {
+	file, env interface{}
+}
+
+// EnvOverride merges settings from the environment into c, the same way
+// loading the config does. Secrets are only ever taken from here so they
+// never end up in config.json.
+func EnvOverride(c *DynamicConfig) {
+	applyEnvOverrides(c)
+}
+
+// applyEnvOverrides merges settings from the environment into c. A value
+// that doesn't parse as its field's type is logged and ignored, as is any
+// GPT5DEV_ variable that isn't listed above, which is most likely a typo.
+func applyEnvOverrides(c *DynamicConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var unknown []string
+	for _, entry := range os.Environ() {
+		name, value, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(name, envPrefix) {
+			continue
+		}
+		if name == EnvConfigFormat {
+			// Read when the config file is picked, before any config is loaded
+			continue
+		}
+		field, ok := envFields[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+
+		target := field(c)
+		before := fieldValue(target)
+		switch field := target.(type) {
+		case *string:
+			*field = value
+		case *bool:
+			b, err := strconv.ParseBool(strings.TrimSpace(value))
+			if err != nil {
+				log.Printf("config: ignoring %s: %q is not true or false", name, value)
+				continue
+			}
+			*field = b
+		case *int:
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				log.Printf("config: ignoring %s: %q is not a whole number", name, value)
+				continue
+			}
+			*field = n
+		}
+		if c.envOverrides == nil {
+			c.envOverrides = map[string]envOverride{}
+		}
+		c.envOverrides[name] = envOverride{file: before, env: fieldValue(target)}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		log.Printf("config: ignoring unrecognized environment variables: %s", strings.Join(unknown, ", "))
+	}
+}
+
+// withFileValues runs fn with each setting that still holds its environment
+// value put back to the config file's value, so a save doesn't write the
+// environment into the file. A setting changed since, by a command for
+// instance, keeps its new value. The caller holds c.mu.
+func (c *DynamicConfig) withFileValues(fn func() error) error {
+	for name, override := range c.envOverrides {
+		field := envFields[name](c)
+		if fieldValue(field) != override.env {
+			continue
+		}
+		setFieldValue(field, override.file)
+		defer setFieldValue(field, override.env)
+	}
+	return fn()
+}
+
+// fieldValue returns the value a field pointer from envFields points to
+func fieldValue(field interface{}) interface{} {
+	switch field := field.(type) {
+	case *string:
+		return *field
+	case *bool:
+		return *field
+	case *int:
+		return *field
+	}
+	return nil
+}
+
+// setFieldValue stores value, as returned by fieldValue, through field
+func setFieldValue(field, value interface{}) {
+	switch field := field.(type) {
+	case *string:
+		*field = value.(string)
+	case *bool:
+		*field = value.(bool)
+	case *int:
+		*field = value.(int)
+	}
+}
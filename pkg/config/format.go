@@ -0,0 +1,388 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ConfigFormat is a file format the main configuration can be written in
+type ConfigFormat string
+
+const (
+	FormatJSON ConfigFormat = "json"
+	FormatYAML ConfigFormat = "yaml"
+	FormatTOML ConfigFormat = "toml"
+)
+
+// ParseConfigFormat returns the format with the given name; "yml" is YAML
+func ParseConfigFormat(name string) (ConfigFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "toml":
+		return FormatTOML, nil
+	}
+	return "", fmt.Errorf("unknown config format %q (use json, yaml or toml)", name)
+}
+
+// formatOfFile returns the format a file's extension names
+func formatOfFile(path string) (ConfigFormat, error) {
+	return ParseConfigFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+var (
+	formatOverride ConfigFormat // set by SetConfigFormat
+	configFileOnce sync.Once
+	configFile     string       // the main config file in use
+	configFormat   ConfigFormat // its format
+)
+
+// SetConfigFormat picks the config file by format instead of by which file
+// exists, as --config-format does. It only has an effect before the
+// configuration is first loaded.
+func SetConfigFormat(name string) error {
+	format, err := ParseConfigFormat(name)
+	if err != nil {
+		return err
+	}
+	formatOverride = format
+	return nil
+}
+
+// ActiveConfigPath returns the main config file in use. An explicit format
+// (SetConfigFormat, then GPT5DEV_CONFIG_FORMAT) selects configs/config.<format>;
+// otherwise config.json is used when it exists, else the first of
+// config.yaml, config.yml and config.toml that does.
+func ActiveConfigPath() string {
+	configFileOnce.Do(resolveConfigFile)
+	return configFile
+}
+
+// activeConfigFormat returns the format of the main config file in use
+func activeConfigFormat() ConfigFormat {
+	configFileOnce.Do(resolveConfigFile)
+	return configFormat
+}
+
+func resolveConfigFile() {
+	format := formatOverride
+	if name, ok := os.LookupEnv(EnvConfigFormat); ok && format == "" {
+		parsed, err := ParseConfigFormat(name)
+		if err != nil {
+			log.Printf("config: ignoring %s: %v", EnvConfigFormat, err)
+		}
+		format = parsed
+	}
+
+	base := strings.TrimSuffix(ConfigPath, filepath.Ext(ConfigPath))
+	candidates := []string{ConfigPath, base + ".yaml", base + ".yml", base + ".toml"}
+	for _, path := range candidates {
+		pathFormat, _ := formatOfFile(path)
+		if format != "" && pathFormat != format {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			configFile, configFormat = path, pathFormat
+			return
+		}
+	}
+
+	// Nothing exists yet: a save creates the file in the chosen format
+	if format == "" {
+		format = FormatJSON
+	}
+	configFile, configFormat = base+"."+string(format), format
+}
+
+// decodeConfig parses data written in format into a tree of *orderedMap,
+// []interface{} and scalars
+func decodeConfig(data []byte, format ConfigFormat) (interface{}, error) {
+	switch format {
+	case FormatYAML:
+		return decodeYAML(data)
+	case FormatTOML:
+		return decodeTOML(data)
+	}
+	return decodeJSON(data)
+}
+
+// encodeConfig writes a tree from decodeConfig in format
+func encodeConfig(tree interface{}, format ConfigFormat) ([]byte, error) {
+	switch format {
+	case FormatYAML, FormatTOML:
+		root, ok := tree.(*orderedMap)
+		if !ok {
+			return nil, fmt.Errorf("a %s config must be a table of settings", format)
+		}
+		if format == FormatYAML {
+			return encodeYAML(root)
+		}
+		return encodeTOML(root)
+	}
+	return json.MarshalIndent(tree, "", "  ")
+}
+
+// unmarshalConfig parses data written in format into v, which is filled as
+// json.Unmarshal would fill it so the json tags name the keys in every format
+func unmarshalConfig(data []byte, format ConfigFormat, v interface{}) error {
+	if format == FormatJSON {
+		return json.Unmarshal(data, v)
+	}
+	tree, err := decodeConfig(data, format)
+	if err != nil {
+		return err
+	}
+	asJSON, err := json.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(asJSON, v)
+}
+
+// marshalConfig writes v in format, keeping the field order of its json encoding
+func marshalConfig(v interface{}, format ConfigFormat) ([]byte, error) {
+	if format == FormatJSON {
+		return json.MarshalIndent(v, "", "  ")
+	}
+	asJSON, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := decodeJSON(asJSON)
+	if err != nil {
+		return nil, err
+	}
+	return encodeConfig(tree, format)
+}
+
+// ConvertFormat rewrites the config file input in outputFmt, next to it with
+// the new extension (configs/config.json becomes configs/config.yaml). Keys
+// are copied as they are, without filling in defaults. An existing output
+// file is never overwritten.
+func ConvertFormat(input, outputFmt string) error {
+	from, err := formatOfFile(input)
+	if err != nil {
+		return err
+	}
+	to, err := ParseConfigFormat(outputFmt)
+	if err != nil {
+		return err
+	}
+	if from == to {
+		return fmt.Errorf("%s is already %s", input, to)
+	}
+
+	output := strings.TrimSuffix(input, filepath.Ext(input)) + "." + string(to)
+	if _, err := os.Stat(output); err == nil {
+		return fmt.Errorf("%s already exists", output)
+	}
+
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", input, err)
+	}
+	tree, err := decodeConfig(data, from)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %v", input, err)
+	}
+	converted, err := encodeConfig(tree, to)
+	if err != nil {
+		return fmt.Errorf("failed to convert %s: %v", input, err)
+	}
+	if err := os.WriteFile(output, converted, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %v", output, err)
+	}
+	return nil
+}
+
+// orderedMap is a table of settings that remembers the order of its keys, so
+// a converted or saved file lists them as the original did
+type orderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedMap() *orderedMap {
+	return &orderedMap{values: map[string]interface{}{}}
+}
+
+// get returns the value under key
+func (m *orderedMap) get(key string) (interface{}, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// set stores value under key, keeping the key's place if it was already set
+func (m *orderedMap) set(key string, value interface{}) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// MarshalJSON writes the table as a JSON object in key order
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		keyJSON, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		valueJSON, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(keyJSON)
+		buf.WriteByte(':')
+		buf.Write(valueJSON)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeJSON parses JSON into a tree that keeps object key order. Numbers
+// are kept as json.Number so integers stay integers.
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeJSONValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err == nil {
+		return nil, fmt.Errorf("unexpected data after the top-level value")
+	}
+	return value, nil
+}
+
+func decodeJSONValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		object := newOrderedMap()
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.set(keyToken.(string), value)
+		}
+		_, err := decoder.Token()
+		return object, err
+	case json.Delim('['):
+		array := []interface{}{}
+		for decoder.More() {
+			value, err := decodeJSONValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err := decoder.Token()
+		return array, err
+	}
+	return token, nil
+}
+
+// scanUnquoted walks s outside quoted strings and returns the index of the
+// first byte match accepts, given the bracket depth there, or -1. Double
+// quotes honor backslash escapes; single quotes do not.
+func scanUnquoted(s string, match func(i, depth int) bool) int {
+	var quote byte
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case match(i, depth):
+			return i
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return -1
+}
+
+// openBrackets reports whether s ends inside an array or inline table
+func openBrackets(s string) bool {
+	depth := 0
+	scanUnquoted(s, func(i, d int) bool {
+		depth = d
+		if s[i] == '[' || s[i] == '{' {
+			depth++
+		} else if s[i] == ']' || s[i] == '}' {
+			depth--
+		}
+		return false
+	})
+	return depth > 0
+}
+
+// splitUnquoted splits s at each sep outside quotes and brackets
+func splitUnquoted(s string, sep byte) []string {
+	var parts []string
+	for {
+		i := scanUnquoted(s, func(i, depth int) bool { return s[i] == sep && depth == 0 })
+		if i < 0 {
+			return append(parts, s)
+		}
+		parts = append(parts, s[:i])
+		s = s[i+1:]
+	}
+}
+
+// splitItems splits the inside of a flow list or table into its items,
+// allowing a trailing comma
+func splitItems(inner string) []string {
+	if strings.TrimSpace(inner) == "" {
+		return nil
+	}
+	items := splitUnquoted(inner, ',')
+	if strings.TrimSpace(items[len(items)-1]) == "" {
+		items = items[:len(items)-1]
+	}
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
+
+// stripComment cuts a # comment from a line. YAML only starts a comment at
+// a # that begins the line or follows a space.
+func stripComment(line string, afterSpace bool) string {
+	i := scanUnquoted(line, func(i, depth int) bool {
+		return line[i] == '#' && (!afterSpace || i == 0 || line[i-1] == ' ' || line[i-1] == '\t')
+	})
+	if i < 0 {
+		return line
+	}
+	return line[:i]
+}
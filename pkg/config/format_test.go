@@ -0,0 +1,253 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// treeJSON renders a decoded tree as compact JSON, in key order
+func treeJSON(t *testing.T, tree interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatalf("marshal tree: %v", err)
+	}
+	return string(data)
+}
+
+// sameTree reports whether two decoded trees hold the same values, ignoring
+// key order, which TOML changes by writing a table's own keys before its
+// sub-tables
+func sameTree(t *testing.T, a, b interface{}) bool {
+	t.Helper()
+	var plainA, plainB interface{}
+	if err := json.Unmarshal([]byte(treeJSON(t, a)), &plainA); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(treeJSON(t, b)), &plainB); err != nil {
+		t.Fatal(err)
+	}
+	return reflect.DeepEqual(plainA, plainB)
+}
+
+func TestConvertShippedConfig(t *testing.T) {
+	data, err := os.ReadFile("../../configs/config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree, err := decodeJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want DynamicConfig
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []ConfigFormat{FormatJSON, FormatYAML, FormatTOML} {
+		t.Run(string(format), func(t *testing.T) {
+			encoded, err := encodeConfig(tree, format)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			decoded, err := decodeConfig(encoded, format)
+			if err != nil {
+				t.Fatalf("decode: %v\n%s", err, encoded)
+			}
+			if !sameTree(t, decoded, tree) {
+				t.Errorf("round trip changed the config\ngot  %s\nwant %s", treeJSON(t, decoded), treeJSON(t, tree))
+			}
+
+			var got DynamicConfig
+			if err := unmarshalConfig(encoded, format, &got); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(got.ChatGPT, want.ChatGPT) || !reflect.DeepEqual(got.Browser, want.Browser) ||
+				!reflect.DeepEqual(got.Files, want.Files) || !reflect.DeepEqual(got.UI, want.UI) ||
+				!reflect.DeepEqual(got.Agent, want.Agent) || !reflect.DeepEqual(got.History, want.History) {
+				t.Errorf("settings differ after a %s round trip", format)
+			}
+		})
+	}
+}
+
+func TestDecodeYAML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"nested mappings", "a:\n  b:\n    c: 1\n  d: x\n", `{"a":{"b":{"c":1},"d":"x"}}`},
+		{"key order kept", "z: 1\na: 2\nm: 3\n", `{"z":1,"a":2,"m":3}`},
+		{"full-line and trailing comments", "# header\na: 1 # one\n  # indented\nb: 2\n", `{"a":1,"b":2}`},
+		{"hash inside quotes", "a: \"x # y\"\nb: 'p # q'\n", `{"a":"x # y","b":"p # q"}`},
+		{"hash without a space before it", "a: x#y\n", `{"a":"x#y"}`},
+		{"double-quoted key", "\"a b\": 1\n", `{"a b":1}`},
+		{"single-quoted key", "'a: b': 1\n", `{"a: b":1}`},
+		{"block list", "a:\n  - x\n  - 2\n", `{"a":["x",2]}`},
+		{"list at the key's indentation", "a:\n- x\n- y\nb: 1\n", `{"a":["x","y"],"b":1}`},
+		{"flow list", "a: [1, \"two\", three]\n", `{"a":[1,"two","three"]}`},
+		{"flow list with a trailing comma", "a: [1, 2,]\n", `{"a":[1,2]}`},
+		{"flow mapping", "a: {b: 1, c: [x]}\n", `{"a":{"b":1,"c":["x"]}}`},
+		{"empty flow collections", "a: []\nb: {}\n", `{"a":[],"b":{}}`},
+		{"scalars", "i: 42\nn: -3\nh: 0x1F\nf: 1.5\nt: true\nF: False\nz: ~\ne:\ns: yes\n",
+			`{"i":42,"n":-3,"h":31,"f":1.5,"t":true,"F":false,"z":null,"e":null,"s":"yes"}`},
+		{"leading zero is decimal", "a: 010\n", `{"a":10}`},
+		{"escapes", "a: \"tab\\tnew\\nline\"\nb: 'it''s'\n", `{"a":"tab\tnew\nline","b":"it's"}`},
+		{"colon inside a value", "url: http://example.com:8080/x\n", `{"url":"http://example.com:8080/x"}`},
+		{"document markers", "---\na: 1\n...\n", `{"a":1}`},
+		{"CRLF line endings", "a: 1\r\nb: 2\r\n", `{"a":1,"b":2}`},
+		{"empty document", "# nothing\n", `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := decodeYAML([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("decodeYAML(%q): %v", tt.input, err)
+			}
+			if got := treeJSON(t, tree); got != tt.want {
+				t.Errorf("decodeYAML(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeTOML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"tables", "a = 1\n[b]\nc = \"x\"\n[b.d]\ne = true\n", `{"a":1,"b":{"c":"x","d":{"e":true}}}`},
+		{"key order kept", "z = 1\na = 2\n", `{"z":1,"a":2}`},
+		{"comments", "# header\na = 1 # one\n[t] # table\nb = \"x # not a comment\"\n", `{"a":1,"t":{"b":"x # not a comment"}}`},
+		{"double-quoted key", "\"a b\" = 1\n", `{"a b":1}`},
+		{"single-quoted key", "'a.b' = 1\n", `{"a.b":1}`},
+		{"quoted table name", "[\"x y\".z]\na = 1\n", `{"x y":{"z":{"a":1}}}`},
+		{"dotted keys", "a.b.c = 1\na.d = 2\n", `{"a":{"b":{"c":1},"d":2}}`},
+		{"multi-line array", "a = [\n  1,\n  2, # two\n  3,\n]\nb = 4\n", `{"a":[1,2,3],"b":4}`},
+		{"nested multi-line array", "a = [\n  [1, 2],\n  [\"x\"],\n]\n", `{"a":[[1,2],["x"]]}`},
+		{"inline table", "a = {b = 1, c.d = \"x\"}\n", `{"a":{"b":1,"c":{"d":"x"}}}`},
+		{"literal string", "a = 'C:\\path'\n", `{"a":"C:\\path"}`},
+		{"escapes", "a = \"tab\\tquote\\\"\"\n", `{"a":"tab\tquote\""}`},
+		{"numbers", "i = 1_000\nn = -5\nh = 0xff\nf = 2.5\ne = 1e3\n", `{"i":1000,"n":-5,"h":255,"f":2.5,"e":1000}`},
+		{"empty array", "a = []\n", `{"a":[]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := decodeTOML([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("decodeTOML(%q): %v", tt.input, err)
+			}
+			if got := treeJSON(t, tree); got != tt.want {
+				t.Errorf("decodeTOML(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  ConfigFormat
+		input   string
+		wantErr string
+	}{
+		{"yaml anchor", FormatYAML, "a: &x 1\n", "anchors"},
+		{"yaml alias", FormatYAML, "a: *x\n", "anchors"},
+		{"yaml literal block", FormatYAML, "a: |\n  text\n", "multi-line strings"},
+		{"yaml folded block", FormatYAML, "a: >\n  text\n", "multi-line strings"},
+		{"yaml mapping in a list", FormatYAML, "a:\n  - b: 1\n", "mappings inside lists"},
+		{"yaml tab indentation", FormatYAML, "a:\n\tb: 1\n", "tabs"},
+		{"yaml value then deeper line", FormatYAML, "a: 1\n  b: 2\n", "unexpected indentation"},
+		{"yaml line without a key", FormatYAML, "a: 1\njust text\n", "expected \"key: value\""},
+		{"yaml unclosed flow list", FormatYAML, "a: [1, 2\n", "unclosed list"},
+		{"yaml bad quoted string", FormatYAML, "a: \"x\\q\"\n", "invalid quoted string"},
+		{"toml array of tables", FormatTOML, "[[a]]\nb = 1\n", "arrays of tables"},
+		{"toml multi-line basic string", FormatTOML, "a = \"\"\"\nx\n\"\"\"\n", "multi-line strings"},
+		{"toml multi-line literal string", FormatTOML, "a = '''x'''\n", "multi-line strings"},
+		{"toml infinity", FormatTOML, "a = inf\n", "infinite"},
+		{"toml nan", FormatTOML, "a = nan\n", "nan"},
+		{"toml date", FormatTOML, "a = 1979-05-27\n", "unsupported value"},
+		{"toml key set twice", FormatTOML, "a = 1\na = 2\n", "set twice"},
+		{"toml key used as a table", FormatTOML, "a = 1\n[a]\nb = 2\n", "not a table"},
+		{"toml unclosed header", FormatTOML, "[a\n", "unclosed table header"},
+		{"toml invalid key", FormatTOML, "a b = 1\n", "invalid key"},
+		{"toml missing value", FormatTOML, "a\n", "expected \"key = value\""},
+		{"toml unclosed array", FormatTOML, "a = [1, 2\n", "unclosed array"},
+		{"json trailing data", FormatJSON, "{} {}", "unexpected data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeConfig([]byte(tt.input), tt.format)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("decodeConfig(%q, %s) error = %v, want one mentioning %q", tt.input, tt.format, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	// Values that need quoting or escaping survive a write and a read
+	input := `{"plain":"text","quotes":"say \"hi\" and 'bye'","newline":"a\nb","hash":"x # y","colon":"k: v",
+		"unicode":"héllo ✓","empty":"","looks like a number":"42","looks like a bool":"true",
+		"int":7,"negative":-1,"float":0.25,"whole float":2.0,"bool":false,"list":["a",1,true],"empty list":[],
+		"nested":{"inner key":{"deep":"x"}},"empty table":{}}`
+	tree, err := decodeJSON([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []ConfigFormat{FormatYAML, FormatTOML} {
+		t.Run(string(format), func(t *testing.T) {
+			encoded, err := encodeConfig(tree, format)
+			if err != nil {
+				t.Fatalf("encode: %v", err)
+			}
+			decoded, err := decodeConfig(encoded, format)
+			if err != nil {
+				t.Fatalf("decode: %v\n%s", err, encoded)
+			}
+			if !sameTree(t, decoded, tree) {
+				t.Errorf("round trip through %s\ngot  %s\nwant %s\nencoded:\n%s", format, treeJSON(t, decoded), treeJSON(t, tree), encoded)
+			}
+			// TOML has separate integer and float types
+			if format == FormatTOML && !strings.Contains(string(encoded), `"whole float" = 2.0`) {
+				t.Errorf("a whole float was not written as a float:\n%s", encoded)
+			}
+		})
+	}
+}
+
+func TestEncodeRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  ConfigFormat
+		input   string
+		wantErr string
+	}{
+		{"toml array of tables", FormatTOML, `{"a":[{"b":1}]}`, "arrays of tables"},
+		{"toml null in an array", FormatTOML, `{"a":[null]}`, "null values"},
+		{"yaml list inside a list", FormatYAML, `{"a":[[1]]}`, "lists inside lists"},
+		{"top level is not a table", FormatYAML, `[1]`, "table of settings"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := decodeJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = encodeConfig(tree, tt.format)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("encodeConfig(%s, %s) error = %v, want one mentioning %q", tt.input, tt.format, err, tt.wantErr)
+			}
+		})
+	}
+}
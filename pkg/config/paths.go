@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Configuration files, relative to the working directory. ConfigPath may be
+// replaced by a YAML or TOML file next to it; see ActiveConfigPath.
+const (
+	ConfigPath    = "configs/config.json"
+	SelectorsPath = "configs/selectors.json"
+	PromptsPath   = "configs/prompts.json"
+	TemplatesPath = "configs/templates.json"
+)
+
+var (
+	loadStatusMu sync.Mutex
+	loadStatus   = map[string]error{} // load result per file; nil means read from disk
+)
+
+// recordLoad remembers whether a file was read from disk or defaults were used
+func recordLoad(path string, err error) {
+	loadStatusMu.Lock()
+	defer loadStatusMu.Unlock()
+	loadStatus[path] = err
+}
+
+// FileSource describes a file or directory the application reads from
+type FileSource struct {
+	Name   string
+	Path   string // absolute path
+	Exists bool
+	// FromDisk reports whether the file's content is in effect; false means
+	// built-in defaults were used instead. Only set for configuration files.
+	FromDisk bool
+	Err      error // why the defaults were used, if they were
+}
+
+// Sources lists the configuration files, cookies file and output directory in
+// effect, loading any configuration file that hasn't been loaded yet
+func Sources() []FileSource {
+	cfg, _ := LoadDynamicConfig()
+	GetSelectors()
+	GetPrompts()
+	GetTemplates()
+
+	loadStatusMu.Lock()
+	defer loadStatusMu.Unlock()
+
+	configFile := func(name, path string) FileSource {
+		source := describePath(name, path)
+		err, loaded := loadStatus[path]
+		source.FromDisk = loaded && err == nil
+		source.Err = err
+		return source
+	}
+
+	return []FileSource{
+		configFile("Config", ActiveConfigPath()),
+		configFile("Selectors", SelectorsPath),
+		configFile("Prompts", PromptsPath),
+		configFile("Templates", TemplatesPath),
+		describePath("Cookies", cfg.GetCookiesPath()),
+		describePath("Output dir", cfg.Files.OutputDir),
+	}
+}
+
+// describePath resolves path against the working directory and checks it exists
+func describePath(name, path string) FileSource {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	_, statErr := os.Stat(absPath)
+	return FileSource{Name: name, Path: absPath, Exists: statErr == nil}
+}
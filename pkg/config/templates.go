@@ -0,0 +1,104 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Templates maps a template name to a prompt containing {placeholders}
+type Templates map[string]string
+
+var globalTemplates Templates
+
+// placeholderPattern matches {name} placeholders inside a template
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// GetTemplates loads and returns prompt templates
+func GetTemplates() (Templates, error) {
+	if globalTemplates == nil {
+		templates, err := loadTemplatesFromFile()
+		recordLoad(TemplatesPath, err)
+		if err != nil {
+			return templates, err
+		}
+		globalTemplates = templates
+	}
+	return globalTemplates, nil
+}
+
+// loadTemplatesFromFile loads prompt templates
+func loadTemplatesFromFile() (Templates, error) {
+	data, err := os.ReadFile(TemplatesPath)
+	if err != nil {
+		return getDefaultTemplates(), fmt.Errorf("failed to read templates file: %v", err)
+	}
+
+	var templates Templates
+	if err := json.Unmarshal(data, &templates); err != nil {
+		return getDefaultTemplates(), fmt.Errorf("failed to parse templates file: %v", err)
+	}
+
+	return templates, nil
+}
+
+// getDefaultTemplates returns default prompt templates when templates file is not available
+func getDefaultTemplates() Templates {
+	return Templates{
+		"test":    "Write a unit test for {file}",
+		"explain": "Explain {topic} to a beginner",
+		"review":  "Review {file} and point out bugs, edge cases and style issues",
+	}
+}
+
+// Names returns the template names in alphabetical order
+func (t Templates) Names() []string {
+	names := make([]string, 0, len(t))
+	for name := range t {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Placeholders returns the distinct placeholders of a template in order of appearance
+func (t Templates) Placeholders(name string) []string {
+	var placeholders []string
+	seen := make(map[string]bool)
+	for _, m := range placeholderPattern.FindAllStringSubmatch(t[name], -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			placeholders = append(placeholders, m[1])
+		}
+	}
+	return placeholders
+}
+
+// Fill substitutes args into the named template. Placeholders are filled in
+// order of appearance and the last one takes all remaining args, so
+// "/t explain Go channels" fills {topic} with "Go channels".
+func (t Templates) Fill(name string, args []string) (string, error) {
+	template, ok := t[name]
+	if !ok {
+		return "", fmt.Errorf("unknown template: %s", name)
+	}
+
+	placeholders := t.Placeholders(name)
+	if len(args) < len(placeholders) {
+		return "", fmt.Errorf("template %s needs: {%s}", name, strings.Join(placeholders, "} {"))
+	}
+
+	result := template
+	for i, placeholder := range placeholders {
+		value := args[i]
+		if i == len(placeholders)-1 {
+			value = strings.Join(args[i:], " ")
+		}
+		result = strings.ReplaceAll(result, "{"+placeholder+"}", value)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,293 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The TOML support covers what a config file needs: [tables], dotted keys,
+// strings, numbers, booleans, arrays (over several lines too) and inline
+// tables. Arrays of tables, multi-line strings and dates are rejected.
+
+// bareTOMLKey matches keys that need no quotes
+var bareTOMLKey = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// decodeTOML parses a TOML document into a tree of *orderedMap,
+// []interface{} and scalars
+func decodeTOML(data []byte) (interface{}, error) {
+	root := newOrderedMap()
+	current := root
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		number := i + 1
+		text := strings.TrimSpace(stripComment(lines[i], false))
+		// An array may continue over several lines
+		for openBrackets(text) && i+1 < len(lines) {
+			i++
+			text += " " + strings.TrimSpace(stripComment(lines[i], false))
+		}
+		if text == "" {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[[") {
+			return nil, fmt.Errorf("line %d: arrays of tables are not supported", number)
+		}
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") {
+				return nil, fmt.Errorf("line %d: unclosed table header", number)
+			}
+			path, err := splitTOMLKey(text[1 : len(text)-1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", number, err)
+			}
+			if current, err = tomlTable(root, path); err != nil {
+				return nil, fmt.Errorf("line %d: %v", number, err)
+			}
+			continue
+		}
+
+		if err := setTOMLKey(current, text); err != nil {
+			return nil, fmt.Errorf("line %d: %v", number, err)
+		}
+	}
+	return root, nil
+}
+
+// setTOMLKey parses "key = value" into table
+func setTOMLKey(table *orderedMap, text string) error {
+	eq := scanUnquoted(text, func(i, depth int) bool { return text[i] == '=' && depth == 0 })
+	if eq < 0 {
+		return fmt.Errorf("expected \"key = value\"")
+	}
+	path, err := splitTOMLKey(text[:eq])
+	if err != nil {
+		return err
+	}
+	value, err := parseTOMLValue(strings.TrimSpace(text[eq+1:]))
+	if err != nil {
+		return err
+	}
+
+	parent, err := tomlTable(table, path[:len(path)-1])
+	if err != nil {
+		return err
+	}
+	key := path[len(path)-1]
+	if _, exists := parent.get(key); exists {
+		return fmt.Errorf("%s is set twice", key)
+	}
+	parent.set(key, value)
+	return nil
+}
+
+// tomlTable returns the table at path under table, creating missing ones
+func tomlTable(table *orderedMap, path []string) (*orderedMap, error) {
+	for _, key := range path {
+		value, ok := table.get(key)
+		if !ok {
+			child := newOrderedMap()
+			table.set(key, child)
+			table = child
+			continue
+		}
+		child, ok := value.(*orderedMap)
+		if !ok {
+			return nil, fmt.Errorf("%s is not a table", key)
+		}
+		table = child
+	}
+	return table, nil
+}
+
+// splitTOMLKey splits a dotted key into its parts, unquoting quoted ones
+func splitTOMLKey(text string) ([]string, error) {
+	var path []string
+	for _, part := range splitUnquoted(text, '.') {
+		part = strings.TrimSpace(part)
+		switch {
+		case strings.HasPrefix(part, `"`):
+			key, err := strconv.Unquote(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted key %s", part)
+			}
+			part = key
+		case strings.HasPrefix(part, "'") && strings.HasSuffix(part, "'") && len(part) >= 2:
+			part = part[1 : len(part)-1]
+		case !bareTOMLKey.MatchString(part):
+			return nil, fmt.Errorf("invalid key %q", strings.TrimSpace(text))
+		}
+		path = append(path, part)
+	}
+	return path, nil
+}
+
+// parseTOMLValue reads a string, boolean, number, array or inline table
+func parseTOMLValue(text string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(text, `"""`) || strings.HasPrefix(text, "'''"):
+		return nil, fmt.Errorf("multi-line strings are not supported; use \\n in a quoted string")
+	case strings.HasPrefix(text, `"`):
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", text)
+		}
+		return value, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("invalid string %s", text)
+		}
+		return text[1 : len(text)-1], nil
+	case text == "true":
+		return true, nil
+	case text == "false":
+		return false, nil
+	case strings.HasPrefix(text, "["):
+		if !strings.HasSuffix(text, "]") {
+			return nil, fmt.Errorf("unclosed array %s", text)
+		}
+		array := []interface{}{}
+		for _, item := range splitItems(text[1 : len(text)-1]) {
+			value, err := parseTOMLValue(item)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		return array, nil
+	case strings.HasPrefix(text, "{"):
+		if !strings.HasSuffix(text, "}") {
+			return nil, fmt.Errorf("unclosed inline table %s", text)
+		}
+		table := newOrderedMap()
+		for _, item := range splitItems(text[1 : len(text)-1]) {
+			if err := setTOMLKey(table, item); err != nil {
+				return nil, err
+			}
+		}
+		return table, nil
+	}
+
+	number := strings.ReplaceAll(text, "_", "")
+	if n, ok := parseInteger(number); ok {
+		return n, nil
+	}
+	switch strings.TrimPrefix(strings.TrimPrefix(number, "+"), "-") {
+	case "inf":
+		return nil, fmt.Errorf("infinite numbers are not supported")
+	case "nan":
+		return nil, fmt.Errorf("nan is not supported")
+	}
+	if f, err := strconv.ParseFloat(number, 64); err == nil && strings.ContainsAny(number, "0123456789") {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unsupported value %s", text)
+}
+
+// encodeTOML writes a tree as TOML: each table's own keys, then its
+// sub-tables under [dotted.headers]. Null values are left out, since TOML
+// has no null.
+func encodeTOML(root *orderedMap) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeTOMLTable(&buf, nil, root); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeTOMLTable(buf *bytes.Buffer, path []string, table *orderedMap) error {
+	for _, key := range table.keys {
+		value := table.values[key]
+		if _, isTable := value.(*orderedMap); isTable || value == nil {
+			continue
+		}
+		text, err := tomlValue(value)
+		if err != nil {
+			return fmt.Errorf("%s: %v", strings.Join(append(path, key), "."), err)
+		}
+		fmt.Fprintf(buf, "%s = %s\n", tomlKey(key), text)
+	}
+
+	for _, key := range table.keys {
+		child, ok := table.values[key].(*orderedMap)
+		if !ok {
+			continue
+		}
+		childPath := append(path[:len(path):len(path)], key)
+		names := make([]string, len(childPath))
+		for i, name := range childPath {
+			names[i] = tomlKey(name)
+		}
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(buf, "[%s]\n", strings.Join(names, "."))
+		if err := writeTOMLTable(buf, childPath, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func tomlKey(key string) string {
+	if bareTOMLKey.MatchString(key) {
+		return key
+	}
+	quoted, _ := json.Marshal(key)
+	return string(quoted)
+}
+
+// tomlValue writes a scalar or an array; strings use JSON escaping, which
+// TOML basic strings read back
+func tomlValue(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case string:
+		quoted, err := json.Marshal(value)
+		return string(quoted), err
+	case bool:
+		return strconv.FormatBool(value), nil
+	case json.Number:
+		if _, err := value.Int64(); err == nil {
+			return value.String(), nil
+		}
+		f, err := value.Float64()
+		if err != nil {
+			return "", err
+		}
+		return tomlFloat(f)
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case float64:
+		return tomlFloat(value)
+	case []interface{}:
+		items := make([]string, len(value))
+		for i, item := range value {
+			text, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			items[i] = text
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	case *orderedMap:
+		return "", fmt.Errorf("arrays of tables are not supported")
+	}
+	return "", fmt.Errorf("null values in arrays are not supported")
+}
+
+// tomlFloat writes a float so it reads back as a float, not an integer
+func tomlFloat(f float64) (string, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", fmt.Errorf("%v is not supported", f)
+	}
+	text := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(text, ".eE") {
+		text += ".0"
+	}
+	return text, nil
+}
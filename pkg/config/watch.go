@@ -0,0 +1,236 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay lets an editor finish saving before a changed file is read
+const reloadDelay = 200 * time.Millisecond
+
+// ValidateConfig checks a configuration for values the application cannot
+// work with and returns one error per problem
+func ValidateConfig(c *DynamicConfig) []error {
+	var problems []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Errorf(format, args...))
+		}
+	}
+
+	if u, err := url.Parse(c.ChatGPT.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		problems = append(problems, fmt.Errorf("chatgpt.base_url must be an absolute URL, got %q", c.ChatGPT.BaseURL))
+	}
+	check(c.ChatGPT.Timeout >= 0, "chatgpt.timeout must not be negative")
+	check(c.ChatGPT.WaitTimeout >= 0, "chatgpt.wait_timeout must not be negative")
+	check(c.ChatGPT.RetryAttempts >= 0 && c.ChatGPT.RetryBackoffMs >= 0, "chatgpt.retry_attempts and chatgpt.retry_backoff_ms must not be negative")
+	check(c.ChatGPT.ScrapeStrategy == "" || c.ChatGPT.ScrapeStrategy == "selector" || c.ChatGPT.ScrapeStrategy == "js",
+		"chatgpt.scrape_strategy must be \"selector\" or \"js\", got %q", c.ChatGPT.ScrapeStrategy)
+	check(c.ChatGPT.ResponseFormat == "" || c.ChatGPT.ResponseFormat == "text" || c.ChatGPT.ResponseFormat == "markdown",
+		"chatgpt.response_format must be \"text\" or \"markdown\", got %q", c.ChatGPT.ResponseFormat)
+	check(c.ChatGPT.RateLimit.Cooldown >= 0 && c.ChatGPT.RateLimit.MaxRetries >= 0 && c.ChatGPT.RateLimit.MaxWait >= 0,
+		"chatgpt.rate_limit values must not be negative")
+	check(!c.ChatGPT.Keepalive.Enabled || c.ChatGPT.Keepalive.Interval > 0,
+		"chatgpt.keepalive.interval must be positive when keepalive is enabled")
+	check(c.UI.SendMode == "" || c.UI.SendMode == "enter" || c.UI.SendMode == "double-enter",
+		"ui.send_mode must be \"enter\" or \"double-enter\", got %q", c.UI.SendMode)
+	check(c.UI.MaxInputKB >= 0, "ui.max_input_kb must not be negative")
+	check(c.Agent.MentionBudget >= 0, "agent.mention_budget must not be negative")
+	check(c.Agent.MaxExecOutputBytes >= 0, "agent.max_exec_output_bytes must not be negative")
+	check(c.Agent.MaxAutoSteps >= 0, "agent.max_auto_steps must not be negative")
+	check(c.Agent.SessionTTLHours >= 0, "agent.session_ttl_hours must not be negative")
+	check(c.Agent.MaxContentMatches >= 0, "agent.max_content_matches must not be negative")
+	check(c.Agent.CacheSize >= 0, "agent.cache_size must not be negative")
+	check(c.UI.PageSize >= 0, "ui.page_size must not be negative")
+	check(c.History.ScrollSteps >= 0, "history.scroll_steps must not be negative")
+	check(c.History.DisplayLimit >= 0, "history.display_limit must not be negative")
+
+	return problems
+}
+
+// WatchConfig watches the config, selectors and prompts files until ctx is
+// done. A changed file is parsed again and, if it is valid, held until the
+// next ApplyReloads, which puts it into effect: config.json is copied into
+// the loaded configuration, so every holder of it sees the change, and the
+// selectors and prompts caches are replaced. onChange is then called with the
+// configuration. Invalid files are logged and ignored.
+func WatchConfig(ctx context.Context, onChange func(*DynamicConfig)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch config: %v", err)
+	}
+
+	// Editors often save by replacing the file, which drops a watch on the
+	// file itself, so the directory is watched instead
+	watched := map[string]bool{}
+	for _, path := range []string{ActiveConfigPath(), SelectorsPath, PromptsPath} {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		watched[abs] = true
+		if err := watcher.Add(filepath.Dir(abs)); err != nil {
+			watcher.Close()
+			return fmt.Errorf("failed to watch config: %v", err)
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+
+		pending := map[string]bool{}
+		timer := time.NewTimer(reloadDelay)
+		timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				abs, _ := filepath.Abs(event.Name)
+				if !watched[abs] || !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+					continue
+				}
+				pending[abs] = true
+				timer.Reset(reloadDelay)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("config watcher: %v", err)
+			case <-timer.C:
+				for abs := range pending {
+					reloadFile(abs, onChange)
+				}
+				pending = map[string]bool{}
+			}
+		}
+	}()
+	return nil
+}
+
+// pendingReload holds the files parsed by the watcher until ApplyReloads puts
+// them into effect on the goroutine that uses the configuration
+type pendingReload struct {
+	config    *DynamicConfig
+	selectors *Selectors
+	prompts   *Prompts
+	onChange  func(*DynamicConfig)
+}
+
+var (
+	reloadMu sync.Mutex
+	reload   *pendingReload // nil when no change is waiting
+)
+
+// reloadFile parses a changed configuration file and, if it is valid, queues
+// it for ApplyReloads
+func reloadFile(abs string, onChange func(*DynamicConfig)) {
+	configPath := ActiveConfigPath()
+	var update func(r *pendingReload)
+	switch abs {
+	case absPath(configPath):
+		cfg, err := loadConfigFromFile()
+		if err != nil {
+			log.Printf("config: keeping the current %s: %v", configPath, err)
+			return
+		}
+		if problems := ValidateConfig(cfg); len(problems) > 0 {
+			log.Printf("config: keeping the current %s: %v", configPath, problems)
+			return
+		}
+		update = func(r *pendingReload) { r.config = cfg }
+	case absPath(SelectorsPath):
+		selectors, err := loadSelectorsFromFile()
+		if err != nil {
+			log.Printf("config: keeping the current %s: %v", SelectorsPath, err)
+			return
+		}
+		update = func(r *pendingReload) { r.selectors = selectors }
+	case absPath(PromptsPath):
+		prompts, err := loadPromptsFromFile()
+		if err != nil {
+			log.Printf("config: keeping the current %s: %v", PromptsPath, err)
+			return
+		}
+		update = func(r *pendingReload) { r.prompts = prompts }
+	default:
+		return
+	}
+
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	if reload == nil {
+		reload = &pendingReload{}
+	}
+	update(reload)
+	reload.onChange = onChange
+}
+
+// ApplyReloads puts the files changed since the last call into effect and
+// calls the watcher's onChange. It is called between messages by the
+// goroutine that uses the configuration, so no setting changes under a
+// running operation. It reports whether anything changed.
+func ApplyReloads() bool {
+	reloadMu.Lock()
+	r := reload
+	reload = nil
+	reloadMu.Unlock()
+	if r == nil {
+		return false
+	}
+
+	if r.config != nil {
+		current, _ := LoadDynamicConfig()
+		current.replace(r.config)
+		recordLoad(ActiveConfigPath(), nil)
+	}
+	if r.selectors != nil || r.prompts != nil {
+		cacheMu.Lock()
+		if r.selectors != nil {
+			globalSelectors = r.selectors
+			recordLoad(SelectorsPath, nil)
+		}
+		if r.prompts != nil {
+			globalPrompts = r.prompts
+			recordLoad(PromptsPath, nil)
+		}
+		cacheMu.Unlock()
+	}
+
+	if r.onChange != nil {
+		cfg, _ := LoadDynamicConfig()
+		r.onChange(cfg)
+	}
+	return true
+}
+
+// absPath returns path made absolute, or path itself if that fails
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// replace copies every setting of other into c
+func (c *DynamicConfig) replace(other *DynamicConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ChatGPT = other.ChatGPT
+	c.Browser = other.Browser
+	c.Files = other.Files
+	c.UI = other.UI
+	c.Agent = other.Agent
+	c.History = other.History
+	c.envOverrides = other.envOverrides
+}
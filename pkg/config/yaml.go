@@ -0,0 +1,323 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The YAML support covers what a config file needs: nested mappings, lists
+// of scalars in block or flow style, quoted and plain scalars and comments.
+// Anchors, multi-line scalars and mappings inside lists are rejected.
+
+// yamlLine is a non-blank line with its comment removed
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// decodeYAML parses a YAML document into a tree of *orderedMap,
+// []interface{} and scalars
+func decodeYAML(data []byte) (interface{}, error) {
+	p := &yamlParser{}
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		trimmed := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: indent with spaces, not tabs", i+1)
+		}
+		text := strings.TrimSpace(stripComment(trimmed, true))
+		if text == "" || text == "---" || text == "..." {
+			continue
+		}
+		p.lines = append(p.lines, yamlLine{number: i + 1, indent: len(raw) - len(trimmed), text: text})
+	}
+	if len(p.lines) == 0 {
+		return newOrderedMap(), nil
+	}
+
+	value, err := p.parseBlock(p.lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].number)
+	}
+	return value, nil
+}
+
+// parseBlock parses the mapping or list starting at the current line
+func (p *yamlParser) parseBlock(indent int) (interface{}, error) {
+	if isYAMLListItem(p.lines[p.pos].text) {
+		return p.parseList(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+// nested parses the block under a key or list item with nothing after it,
+// or returns nil if no more deeply indented block follows. A list may sit at
+// the same indentation as the key that owns it.
+func (p *yamlParser) nested(indent int, allowList bool) (interface{}, error) {
+	if p.pos == len(p.lines) {
+		return nil, nil
+	}
+	next := p.lines[p.pos]
+	if next.indent > indent || allowList && next.indent == indent && isYAMLListItem(next.text) {
+		return p.parseBlock(next.indent)
+	}
+	return nil, nil
+}
+
+func (p *yamlParser) parseMapping(indent int) (*orderedMap, error) {
+	mapping := newOrderedMap()
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && !isYAMLListItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		key, rest, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", line.number)
+		}
+		p.pos++
+
+		var value interface{}
+		var err error
+		switch {
+		case rest == "":
+			value, err = p.nested(indent, true)
+		case strings.HasPrefix(rest, "|") || strings.HasPrefix(rest, ">"):
+			err = fmt.Errorf("line %d: multi-line strings are not supported; use a quoted string with \\n", line.number)
+		case strings.HasPrefix(rest, "&") || strings.HasPrefix(rest, "*"):
+			err = fmt.Errorf("line %d: anchors and aliases are not supported", line.number)
+		default:
+			value, err = parseYAMLFlow(rest)
+			if err != nil {
+				err = fmt.Errorf("line %d: %v", line.number, err)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		mapping.set(key, value)
+
+		if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+			return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].number)
+		}
+	}
+	return mapping, nil
+}
+
+func (p *yamlParser) parseList(indent int) ([]interface{}, error) {
+	list := []interface{}{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLListItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		rest := strings.TrimSpace(strings.TrimPrefix(line.text, "-"))
+		p.pos++
+
+		if rest == "" {
+			value, err := p.nested(indent, false)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+			continue
+		}
+		if _, _, ok := splitYAMLKey(rest); ok {
+			return nil, fmt.Errorf("line %d: mappings inside lists are not supported", line.number)
+		}
+		value, err := parseYAMLFlow(rest)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line.number, err)
+		}
+		list = append(list, value)
+	}
+	return list, nil
+}
+
+func isYAMLListItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey splits "key: value" at the colon; the key may be quoted
+func splitYAMLKey(text string) (key, rest string, ok bool) {
+	colon := scanUnquoted(text, func(i, depth int) bool {
+		return text[i] == ':' && depth == 0 && (i+1 == len(text) || text[i+1] == ' ')
+	})
+	if colon <= 0 {
+		return "", "", false
+	}
+	key = strings.TrimSpace(text[:colon])
+	if key[0] == '"' || key[0] == '\'' {
+		unquoted, err := parseYAMLScalar(key)
+		if err != nil {
+			return "", "", false
+		}
+		key, _ = unquoted.(string)
+	}
+	return key, strings.TrimSpace(text[colon+1:]), true
+}
+
+// parseYAMLFlow parses a scalar or a one-line [list] or {mapping}
+func parseYAMLFlow(text string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(text, "["):
+		if !strings.HasSuffix(text, "]") {
+			return nil, fmt.Errorf("unclosed list %s", text)
+		}
+		list := []interface{}{}
+		for _, item := range splitItems(text[1 : len(text)-1]) {
+			value, err := parseYAMLFlow(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	case strings.HasPrefix(text, "{"):
+		if !strings.HasSuffix(text, "}") {
+			return nil, fmt.Errorf("unclosed mapping %s", text)
+		}
+		mapping := newOrderedMap()
+		for _, item := range splitItems(text[1 : len(text)-1]) {
+			key, rest, ok := splitYAMLKey(item)
+			if !ok {
+				return nil, fmt.Errorf("expected \"key: value\" in %s", text)
+			}
+			value, err := parseYAMLFlow(rest)
+			if err != nil {
+				return nil, err
+			}
+			mapping.set(key, value)
+		}
+		return mapping, nil
+	}
+	return parseYAMLScalar(text)
+}
+
+// parseYAMLScalar reads a quoted string, null, a boolean, a number or, for
+// anything else, a plain string
+func parseYAMLScalar(text string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(text, `"`):
+		value, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted string %s", text)
+		}
+		return value, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return nil, fmt.Errorf("invalid quoted string %s", text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	}
+
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	}
+	if n, ok := parseInteger(text); ok {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil && strings.ContainsAny(text, "0123456789") {
+		return f, nil
+	}
+	return text, nil
+}
+
+// parseInteger reads a decimal integer, or a hex or octal one with a 0x or
+// 0o prefix. A leading zero does not make a number octal.
+func parseInteger(text string) (int64, bool) {
+	base := 10
+	if lower := strings.ToLower(text); strings.HasPrefix(lower, "0x") || strings.HasPrefix(lower, "0o") || strings.HasPrefix(lower, "0b") {
+		base = 0
+	}
+	n, err := strconv.ParseInt(text, base, 64)
+	return n, err == nil
+}
+
+// plainYAMLKey matches keys that need no quotes
+var plainYAMLKey = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]*$`)
+
+// encodeYAML writes a tree in block style, quoting every string
+func encodeYAML(root *orderedMap) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeYAMLMapping(&buf, root, 0); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeYAMLMapping(buf *bytes.Buffer, mapping *orderedMap, indent int) error {
+	pad := strings.Repeat(" ", indent)
+	for _, key := range mapping.keys {
+		name := key
+		if !plainYAMLKey.MatchString(key) {
+			quoted, _ := json.Marshal(key)
+			name = string(quoted)
+		}
+
+		switch value := mapping.values[key].(type) {
+		case *orderedMap:
+			if len(value.keys) == 0 {
+				fmt.Fprintf(buf, "%s%s: {}\n", pad, name)
+				continue
+			}
+			fmt.Fprintf(buf, "%s%s:\n", pad, name)
+			if err := writeYAMLMapping(buf, value, indent+2); err != nil {
+				return err
+			}
+		case []interface{}:
+			if len(value) == 0 {
+				fmt.Fprintf(buf, "%s%s: []\n", pad, name)
+				continue
+			}
+			fmt.Fprintf(buf, "%s%s:\n", pad, name)
+			for _, item := range value {
+				text, err := yamlScalar(item)
+				if err != nil {
+					return fmt.Errorf("%s: %v", key, err)
+				}
+				fmt.Fprintf(buf, "%s  - %s\n", pad, text)
+			}
+		default:
+			text, err := yamlScalar(value)
+			if err != nil {
+				return fmt.Errorf("%s: %v", key, err)
+			}
+			fmt.Fprintf(buf, "%s%s: %s\n", pad, name, text)
+		}
+	}
+	return nil
+}
+
+// yamlScalar writes a scalar; strings are double-quoted, which JSON
+// escaping produces in a form YAML reads back
+func yamlScalar(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "null", nil
+	case string:
+		quoted, err := json.Marshal(value)
+		return string(quoted), err
+	case bool:
+		return strconv.FormatBool(value), nil
+	case json.Number:
+		return value.String(), nil
+	case int64:
+		return strconv.FormatInt(value, 10), nil
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64), nil
+	}
+	return "", fmt.Errorf("lists inside lists are not supported")
+}
@@ -0,0 +1,101 @@
+// Package errs defines the typed errors returned by the ChatGPT client so the
+// CLI can explain failures instead of echoing raw chromedp messages.
+package errs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// Sentinel errors every BrowserError unwraps to. Match them with errors.Is.
+var (
+	ErrSelectorNotFound    = errors.New("page element not found - the ChatGPT layout may have changed")
+	ErrTimeout             = errors.New("timed out waiting for ChatGPT")
+	ErrContextDead         = errors.New("browser session is closed")
+	ErrNoActiveChat        = errors.New("no saved chat is open yet - send a message first")
+	ErrEmptyResponse       = errors.New("received empty response from assistant")
+	ErrHeadless            = errors.New("browser is running headless, there is no window")
+	ErrBrowser             = errors.New("browser action failed")
+	ErrNotLoggedIn         = errors.New("ChatGPT session expired - the page is showing the login screen")
+	ErrChatMenuUnavailable = errors.New("the chat's sidebar menu has no such option - the chat may not be saved or listed yet")
+	ErrNoSuchTab           = errors.New("no open tab has that number")
+	ErrInvalidChatID       = errors.New("not a chat ID - IDs are letters, digits and dashes")
+
+	// ErrResponseTimeout is an answer that did not finish within
+	// chatgpt.timeout. It is also an ErrTimeout.
+	ErrResponseTimeout = fmt.Errorf("%w - the answer did not finish within chatgpt.timeout", ErrTimeout)
+)
+
+// BrowserError describes a failed client operation
+type BrowserError struct {
+	Op   string // what the client was doing, e.g. "send message"
+	Kind error  // one of the sentinel errors above
+	Err  error  // underlying cause, may be nil
+}
+
+// Error implements the error interface
+func (e *BrowserError) Error() string {
+	if e.Err == nil || e.Kind != ErrBrowser {
+		return fmt.Sprintf("%s: %v", e.Op, e.Kind)
+	}
+	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+// Unwrap exposes both the sentinel kind and the underlying cause
+func (e *BrowserError) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// New returns a BrowserError of the given kind without an underlying cause
+func New(op string, kind error) error {
+	return &BrowserError{Op: op, Kind: kind}
+}
+
+// Wrap classifies an error from a chromedp action and wraps it with the
+// operation name. It returns nil for nil and leaves typed errors unchanged.
+func Wrap(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var browserErr *BrowserError
+	if errors.As(err, &browserErr) {
+		return err
+	}
+
+	return &BrowserError{Op: op, Kind: Classify(err), Err: err}
+}
+
+// Classify maps a raw chromedp/CDP error to one of the sentinel errors
+func Classify(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, chromedp.ErrPollingTimeout):
+		return ErrTimeout
+	case errors.Is(err, context.Canceled), errors.Is(err, chromedp.ErrInvalidContext),
+		errors.Is(err, chromedp.ErrChannelClosed), errors.Is(err, chromedp.ErrInvalidTarget):
+		return ErrContextDead
+	case errors.Is(err, chromedp.ErrNoResults), errors.Is(err, chromedp.ErrNotVisible),
+		errors.Is(err, chromedp.ErrInvalidBoxModel), errors.Is(err, chromedp.ErrJSNull),
+		errors.Is(err, chromedp.ErrJSUndefined):
+		return ErrSelectorNotFound
+	}
+
+	// CDP protocol errors only carry a message
+	message := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(message, "could not find node"), strings.Contains(message, "no node"):
+		return ErrSelectorNotFound
+	case strings.Contains(message, "deadline exceeded"), strings.Contains(message, "timeout"):
+		return ErrTimeout
+	case strings.Contains(message, "context canceled"), strings.Contains(message, "target closed"):
+		return ErrContextDead
+	}
+	return ErrBrowser
+}
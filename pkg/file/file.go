@@ -3,6 +3,7 @@ package file
 import (
 	"encoding/json"
 	"os"
+	"path/filepath"
 )
 
 // WriteJSONFile writes data to a JSON file with proper formatting
@@ -23,4 +24,26 @@ func ReadJSONFile(filename string, data interface{}) error {
 	}
 	
 	return json.Unmarshal(fileData, data)
-}
\ No newline at end of file
+}
+
+// WriteFileAtomic writes data to a temporary file next to filename and renames
+// it over filename, so an interrupted write never leaves a half-written file
+func WriteFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
@@ -17,20 +17,80 @@ func FormatResponse(response string) string {
 	thoughtRegex := regexp.MustCompile(`^Thought for \d+s\s*`)
 	response = thoughtRegex.ReplaceAllString(response, "")
 	
+	// Fenced blocks are passed through byte-for-byte; only prose is reformatted
+	var formatted []string
+	for _, seg := range splitFences(response) {
+		if seg.fenced {
+			formatted = append(formatted, seg.text)
+			continue
+		}
+		if prose := formatProse(seg.text); prose != "" {
+			formatted = append(formatted, prose)
+		}
+	}
+	
+	return strings.Join(formatted, "\n\n")
+}
+
+// formatProse applies code detection and paragraph formatting to text outside fences
+func formatProse(text string) string {
 	// Only detect code blocks if they have VERY clear indicators
-	if strings.Contains(response, "```") || 
-	   (strings.Contains(response, "python") && (strings.Contains(response, "def ") || strings.Contains(response, "import ") || strings.Contains(response, "print("))) ||
-	   (strings.Contains(response, "javascript") && strings.Contains(response, "function")) {
-		response = formatCodeBlocks(response)
+	if (strings.Contains(text, "python") && (strings.Contains(text, "def ") || strings.Contains(text, "import ") || strings.Contains(text, "print("))) ||
+	   (strings.Contains(text, "javascript") && strings.Contains(text, "function")) {
+		text = formatCodeBlocks(text)
 	}
 	
 	// Skip inline code formatting for now to avoid false positives
-	// response = formatInlineCode(response)
+	// text = formatInlineCode(text)
 	
 	// Add proper line breaks for readability
-	response = formatParagraphs(response)
-	
-	return response
+	return formatParagraphs(text)
+}
+
+// segment is a run of response text that is either fenced code or prose
+type segment struct {
+	text   string
+	fenced bool
+}
+
+// fenceLine matches an opening or closing ``` / ~~~ fence line
+var fenceLine = regexp.MustCompile("^\\s*(```|~~~)")
+
+// splitFences splits text into prose and fenced-code segments. Fenced segments
+// include their fence lines and are never modified. An unterminated fence runs
+// to the end of the text.
+func splitFences(text string) []segment {
+	var segments []segment
+	var current []string
+	inFence := false
+	fence := ""
+
+	flush := func(fenced bool) {
+		if len(current) > 0 {
+			segments = append(segments, segment{text: strings.Join(current, "\n"), fenced: fenced})
+			current = nil
+		}
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		m := fenceLine.FindStringSubmatch(line)
+		switch {
+		case !inFence && m != nil:
+			flush(false)
+			inFence = true
+			fence = m[1]
+			current = append(current, line)
+		case inFence && m != nil && m[1] == fence && strings.TrimSpace(line) == fence:
+			current = append(current, line)
+			flush(true)
+			inFence = false
+		default:
+			current = append(current, line)
+		}
+	}
+	flush(inFence)
+
+	return segments
 }
 
 // formatCodeBlocks detects and formats multi-line code blocks
@@ -109,8 +169,13 @@ func formatParagraphs(text string) string {
 		if strings.HasPrefix(para, "-") || strings.HasPrefix(para, "*") || strings.HasPrefix(para, "•") {
 			formatted = append(formatted, formatList(para))
 		} else if strings.HasPrefix(para, "#") {
-			// Format headers
-			formatted = append(formatted, formatHeader(para))
+			// Format the header line; text right below it stays a paragraph
+			lines := strings.SplitN(para, "\n", 2)
+			header := formatHeader(lines[0])
+			if len(lines) > 1 {
+				header += "\n" + lines[1]
+			}
+			formatted = append(formatted, header)
 		} else {
 			// Regular paragraph
 			formatted = append(formatted, para)
@@ -143,16 +208,112 @@ func formatList(text string) string {
 	return strings.Join(formatted, "\n")
 }
 
-// formatHeader formats markdown-style headers
+// formatHeader formats a markdown-style header line, dropping the # markers
 func formatHeader(text string) string {
-	if strings.HasPrefix(text, "###") {
-		return ui.Blue + ui.Bold + text + ui.Reset
-	} else if strings.HasPrefix(text, "##") {
-		return ui.Purple + ui.Bold + text + ui.Reset
-	} else if strings.HasPrefix(text, "#") {
-		return ui.Cyan + ui.Bold + text + ui.Reset
+	level, title, ok := parseHeader(text)
+	if !ok {
+		return text
+	}
+	return headerColor(level) + ui.Bold + title + ui.Reset
+}
+
+// headerColor returns the color used for a header level
+func headerColor(level int) string {
+	switch level {
+	case 1:
+		return ui.Cyan
+	case 2:
+		return ui.Purple
+	default:
+		return ui.Blue
+	}
+}
+
+// headerLine matches a markdown ATX header such as "## Setup"
+var headerLine = regexp.MustCompile(`^\s{0,3}(#{1,6})\s+(.+?)\s*#*\s*$`)
+
+// parseHeader returns the level and title of a header line
+func parseHeader(line string) (int, string, bool) {
+	m := headerLine.FindStringSubmatch(line)
+	if m == nil {
+		return 0, "", false
+	}
+	return len(m[1]), m[2], true
+}
+
+// Header is a markdown header found in a response
+type Header struct {
+	Level int
+	Title string
+}
+
+// tocMinHeaders is how many headers an answer needs before it gets a TOC
+const tocMinHeaders = 3
+
+// ExtractHeaders returns the # and ## headers outside fenced code, in order
+func ExtractHeaders(text string) []Header {
+	var headers []Header
+	for _, seg := range splitFences(text) {
+		if seg.fenced {
+			continue
+		}
+		for _, line := range strings.Split(seg.text, "\n") {
+			if level, title, ok := parseHeader(line); ok && level <= 2 {
+				headers = append(headers, Header{Level: level, Title: title})
+			}
+		}
+	}
+	return headers
+}
+
+// TableOfContents builds a numbered list of the answer's headers, or returns
+// "" when there are too few headers for one to help
+func TableOfContents(text string) string {
+	headers := ExtractHeaders(text)
+	if len(headers) < tocMinHeaders {
+		return ""
+	}
+
+	// Indent ## under # only when the answer uses both levels
+	topLevel := headers[0].Level
+	for _, h := range headers {
+		if h.Level < topLevel {
+			topLevel = h.Level
+		}
+	}
+
+	var toc strings.Builder
+	toc.WriteString(ui.Bold + "Contents" + ui.Reset + "\n")
+	for i, h := range headers {
+		indent := strings.Repeat("   ", h.Level-topLevel)
+		toc.WriteString(fmt.Sprintf("  %s%s%2d.%s %s\n", indent, ui.Dim, i+1, ui.Reset, headerColor(h.Level)+h.Title+ui.Reset))
+	}
+	return strings.TrimRight(toc.String(), "\n")
+}
+
+// ExtractLastCodeBlock returns the language and content of the last fenced
+// code block in a response. An unterminated final fence counts as a block.
+func ExtractLastCodeBlock(response string) (lang, code string, ok bool) {
+	segments := splitFences(response)
+	for i := len(segments) - 1; i >= 0; i-- {
+		if !segments[i].fenced {
+			continue
+		}
+
+		lines := strings.Split(segments[i].text, "\n")
+		opening := fenceLine.FindStringSubmatch(lines[0])
+		info := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(lines[0]), opening[1]))
+		if fields := strings.Fields(info); len(fields) > 0 {
+			lang = fields[0]
+		}
+
+		body := lines[1:]
+		if n := len(body); n > 0 && strings.TrimSpace(body[n-1]) == opening[1] {
+			body = body[:n-1]
+		}
+		return lang, strings.Join(body, "\n"), true
 	}
-	return text
+	return "", "", false
 }
 
 // Helper function for sprintf (simple implementation)
@@ -0,0 +1,45 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatResponseKeepsFencedBlocks(t *testing.T) {
+	yamlBlock := "```yaml\n" +
+		"server:\n" +
+		"  port: 8080\n" +
+		"  routes:\n" +
+		"    - path: /api\n" +
+		"      handler: api\n" +
+		"\n" +
+		"\n" +
+		"    - path: /static   \n" +
+		"      handler: files\n" +
+		"\tindented: with a tab\n" +
+		"```"
+
+	tests := []struct {
+		name     string
+		response string
+		block    string
+		same     bool // plain prose around the block, so nothing should change
+	}{
+		{"yaml between paragraphs", "Here is the config:\n\n" + yamlBlock + "\n\nThat should work.", yamlBlock, true},
+		{"yaml right after a line of prose", "Config:\n" + yamlBlock + "\nDone.", yamlBlock, false},
+		{"tilde fence", "Example:\n\n~~~yaml\nkey:\n    nested: true\n\n  other: 1\n~~~\n\nEnd.", "~~~yaml\nkey:\n    nested: true\n\n  other: 1\n~~~", true},
+		{"unterminated fence", "Partial:\n\n```yaml\na:\n  b: 1\n\n    c: 2", "```yaml\na:\n  b: 1\n\n    c: 2", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatResponse(tt.response)
+			if !strings.Contains(got, tt.block) {
+				t.Errorf("FormatResponse changed the fenced block\ngot:\n%s\nwant it to contain:\n%s", got, tt.block)
+			}
+			if tt.same && got != tt.response {
+				t.Errorf("FormatResponse(%q) = %q, want it unchanged", tt.response, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package formatter
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// jsonResponse is the object written per response with --output-format json
+type jsonResponse struct {
+	Query     string `json:"query"`
+	Response  string `json:"response"`
+	Model     string `json:"model"`
+	ChatID    string `json:"chat_id"`
+	Timestamp string `json:"timestamp"`
+}
+
+// RenderJSONResponse renders a query and its plain response as a single-line
+// JSON object, so several responses form JSON Lines
+func RenderJSONResponse(query, response, model, chatID string, timestamp time.Time) (string, error) {
+	data, err := json.Marshal(jsonResponse{
+		Query:     query,
+		Response:  response,
+		Model:     model,
+		ChatID:    chatID,
+		Timestamp: timestamp.Format(time.RFC3339),
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// ansiEscape matches any CSI escape sequence, e.g. colors and cursor moves
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// StripANSI removes terminal escape sequences from text
+func StripANSI(text string) string {
+	return ansiEscape.ReplaceAllString(text, "")
+}
+
+// CopyToClipboard writes text to the system clipboard using the platform's
+// clipboard tool: pbcopy on macOS, clip on Windows, and wl-copy, xclip or
+// xsel on Linux, whichever is installed
+func CopyToClipboard(text string) error {
+	var candidates [][]string
+	switch runtime.GOOS {
+	case "darwin":
+		candidates = [][]string{{"pbcopy"}}
+	case "windows":
+		candidates = [][]string{{"clip"}}
+	default:
+		candidates = [][]string{
+			{"wl-copy"},
+			{"xclip", "-selection", "clipboard"},
+			{"xsel", "--clipboard", "--input"},
+		}
+	}
+
+	for _, args := range candidates {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("failed to copy with %s: %v", args[0], err)
+		}
+		return nil
+	}
+
+	var tools []string
+	for _, args := range candidates {
+		tools = append(tools, args[0])
+	}
+	if runtime.GOOS == "linux" {
+		return fmt.Errorf("no clipboard tool found (tried %s) - install xclip, xsel or wl-clipboard", strings.Join(tools, ", "))
+	}
+	return fmt.Errorf("no clipboard tool found (tried %s)", strings.Join(tools, ", "))
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// LineReader prints a prompt and returns the next input line; ok is false
+// when input is exhausted
+type LineReader func(prompt string) (line string, ok bool)
+
+var (
+	// autoConfirm answers every confirmation with yes (--yes, agent.auto_confirm)
+	autoConfirm bool
+	// confirmReader reads the answer; the CLI points it at its own input
+	// reader so buffered stdin isn't read from two places
+	confirmReader LineReader = stdinLineReader()
+)
+
+// stdinLineReader reads lines straight from stdin, for use before the CLI
+// has set up its input reader
+func stdinLineReader() LineReader {
+	stdin := bufio.NewReader(os.Stdin)
+	return func(prompt string) (string, bool) {
+		fmt.Fprint(statusOut(), prompt)
+		line, err := stdin.ReadString('\n')
+		if err != nil && line == "" {
+			return "", false
+		}
+		return strings.TrimRight(line, "\r\n"), true
+	}
+}
+
+// SetAutoConfirm makes Confirm answer yes without asking
+func SetAutoConfirm(enabled bool) {
+	autoConfirm = enabled
+}
+
+// AutoConfirm reports whether confirmations are answered automatically
+func AutoConfirm() bool {
+	return autoConfirm
+}
+
+// SetConfirmReader sets where Confirm reads its answers from
+func SetConfirmReader(reader LineReader) {
+	confirmReader = reader
+}
+
+// CanConfirm reports whether Confirm can get an answer: auto-confirm is on or
+// stdin is a terminal. Piped input would read end of input, which is no.
+func CanConfirm() bool {
+	return autoConfirm || term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// Confirm asks a yes/no question and reports whether the answer was y or yes.
+// Anything else, including end of input, is no. With auto-confirm on the
+// question is printed with its answer and nothing is read.
+func Confirm(question string) bool {
+	if autoConfirm {
+		fmt.Fprintln(statusOut(), question+" [y/N] "+Dim+"y (auto)"+Reset)
+		return true
+	}
+
+	line, ok := confirmReader(question + " [y/N] ")
+	if !ok {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
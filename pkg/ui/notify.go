@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// NotifyComplete rings the terminal bell and prints a highlighted line to
+// signal that a long generation finished. With desktop set it also shows a
+// desktop notification where a notifier is available.
+func NotifyComplete(elapsed time.Duration, desktop bool) {
+	message := fmt.Sprintf("Response ready (took %s)", elapsed.Round(time.Second))
+
+	fmt.Print("\a")
+	fmt.Println(Bold + "\033[7m" + " 🔔 " + message + " " + Reset)
+
+	if desktop {
+		sendDesktopNotification("GPT5-DEV", message)
+	}
+}
+
+// sendDesktopNotification shells out to the platform notifier, ignoring failures
+func sendDesktopNotification(title, message string) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.Command("notify-send", title, message)
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", fmt.Sprintf("display notification %q with title %q", message, title))
+	default:
+		return
+	}
+
+	// Notifications are best effort and must never block the CLI
+	go cmd.Run()
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProgressBar draws progress through a known number of steps on one line,
+// e.g. "[████░░░░░░] 4/10 (40%) Running: compile step", redrawn in place
+type ProgressBar struct {
+	total   int
+	current int
+	label   string
+}
+
+// NewProgressBar creates a progress bar for total steps. Nothing is drawn
+// until the first Increment.
+func NewProgressBar(total int) *ProgressBar {
+	return &ProgressBar{total: total}
+}
+
+// Increment counts one more step, labelled with what it is doing, and redraws the bar
+func (p *ProgressBar) Increment(label string) {
+	if p.current < p.total {
+		p.current++
+	}
+	p.label = strings.Join(strings.Fields(label), " ")
+	p.draw()
+}
+
+// Clear erases the bar so other output can be printed; the next Increment draws it again
+func (p *ProgressBar) Clear() {
+	if !quiet {
+		fmt.Print("\r\033[K")
+	}
+}
+
+// Done draws the bar a last time without a label and ends its line
+func (p *ProgressBar) Done() {
+	p.label = ""
+	p.draw()
+	if !quiet {
+		fmt.Println()
+	}
+}
+
+// draw renders the bar over the current line. The bar takes a third of the
+// terminal width and the label is cut to fit the rest.
+func (p *ProgressBar) draw() {
+	if quiet || p.total <= 0 {
+		return
+	}
+
+	termWidth := GetTerminalWidth()
+	width := termWidth / 3
+	if width < 10 {
+		width = 10
+	} else if width > 40 {
+		width = 40
+	}
+	filled := width * p.current / p.total
+
+	line := fmt.Sprintf("[%s%s] %d/%d (%d%%)", strings.Repeat("█", filled), strings.Repeat("░", width-filled),
+		p.current, p.total, p.current*100/p.total)
+	if p.label != "" {
+		line += " Running: " + p.label
+	}
+	if runes := []rune(line); len(runes) > termWidth-1 {
+		line = string(runes[:termWidth-2]) + "…"
+	}
+	fmt.Print("\r" + line + "\033[K")
+}
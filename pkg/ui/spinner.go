@@ -2,15 +2,27 @@ package ui
 
 import (
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// activeSpinners counts running spinners so RestoreTerminal knows to clear the line
+var activeSpinners int32
+
+// spinnerActive reports whether any spinner is drawing
+func spinnerActive() bool {
+	return atomic.LoadInt32(&activeSpinners) > 0
+}
+
 // Spinner represents a loading spinner
 type Spinner struct {
-	frames []string
-	delay  time.Duration
-	active bool
-	done   chan bool
+	frames  []string
+	delay   time.Duration
+	active  bool
+	done    chan bool
+	mu      sync.Mutex
+	message string
 }
 
 // NewSpinner creates a new spinner
@@ -40,13 +52,17 @@ func NewSquareSpinner() *Spinner {
 	}
 }
 
-// Start starts the spinner with a message
+// Start starts the spinner with a message, hiding the cursor while it runs.
+// In quiet mode nothing is drawn.
 func (s *Spinner) Start(message string) {
-	if s.active {
+	if s.active || quiet {
 		return
 	}
 	s.active = true
-	
+	s.setMessage(message)
+	atomic.AddInt32(&activeSpinners, 1)
+	fmt.Print(hideCursor)
+
 	go func() {
 		i := 0
 		for {
@@ -54,7 +70,7 @@ func (s *Spinner) Start(message string) {
 			case <-s.done:
 				return
 			default:
-				fmt.Printf("\r%s %s", s.frames[i%len(s.frames)], message)
+				fmt.Printf("\r%s %s\033[K", s.frames[i%len(s.frames)], s.currentMessage())
 				i++
 				time.Sleep(s.delay)
 			}
@@ -62,19 +78,34 @@ func (s *Spinner) Start(message string) {
 	}()
 }
 
-// Stop stops the spinner and clears the line
+// Stop stops the spinner, clears the line and shows the cursor again
 func (s *Spinner) Stop() {
 	if !s.active {
 		return
 	}
 	s.active = false
 	s.done <- true
-	fmt.Print("\r\033[K") // Clear the line
+	atomic.AddInt32(&activeSpinners, -1)
+	fmt.Print("\r\033[K" + showCursor) // Clear the line
 }
 
-// Update updates the spinner message
+// Update changes the message shown next to the spinner
 func (s *Spinner) Update(message string) {
 	if s.active {
-		fmt.Printf("\r%s", message)
+		s.setMessage(message)
 	}
-}
\ No newline at end of file
+}
+
+// setMessage replaces the message under the lock shared with the draw loop
+func (s *Spinner) setMessage(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.message = message
+}
+
+// currentMessage returns the message to draw
+func (s *Spinner) currentMessage() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.message
+}
@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// RenderTable lays headers and rows out in columns padded to the widest cell,
+// with a rule under the headers. Widths ignore color escapes, so colored cells
+// line up with plain ones. Rows shorter than headers are padded with empty cells.
+func RenderTable(headers []string, rows [][]string) string {
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = visibleLen(header)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i < len(widths) && visibleLen(cell) > widths[i] {
+				widths[i] = visibleLen(cell)
+			}
+		}
+	}
+
+	var out strings.Builder
+	writeRow := func(cells []string) {
+		for i, width := range widths {
+			cell := ""
+			if i < len(cells) {
+				cell = cells[i]
+			}
+			if i > 0 {
+				out.WriteString("  ")
+			}
+			out.WriteString(cell)
+			if i < len(widths)-1 {
+				out.WriteString(strings.Repeat(" ", width-visibleLen(cell)))
+			}
+		}
+		out.WriteString("\n")
+	}
+
+	writeRow(headers)
+	rules := make([]string, len(widths))
+	for i, width := range widths {
+		rules[i] = strings.Repeat("─", width)
+	}
+	writeRow(rules)
+	for _, row := range rows {
+		writeRow(row)
+	}
+	return out.String()
+}
+
+// visibleLen counts the characters of text a terminal shows, skipping escapes
+func visibleLen(text string) int {
+	return utf8.RuneCountInString(StripANSI(text))
+}
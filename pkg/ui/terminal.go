@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Cursor visibility escape sequences
+const (
+	hideCursor = "\033[?25l"
+	showCursor = "\033[?25h"
+)
+
+var (
+	restoreMu    sync.Mutex
+	restoreHooks = map[int]func(){}
+	nextHookID   int
+)
+
+// AddRestoreHook registers fn to run from RestoreTerminal, e.g. to leave raw
+// mode. The returned function unregisters it once the state is undone normally.
+func AddRestoreHook(fn func()) (remove func()) {
+	restoreMu.Lock()
+	defer restoreMu.Unlock()
+
+	id := nextHookID
+	nextHookID++
+	restoreHooks[id] = fn
+	return func() {
+		restoreMu.Lock()
+		defer restoreMu.Unlock()
+		delete(restoreHooks, id)
+	}
+}
+
+// RestoreTerminal puts the terminal back in a usable state after an abrupt
+// exit: it runs the restore hooks, clears a half-drawn spinner line, resets
+// colors and shows the cursor. It is safe to call more than once.
+func RestoreTerminal() {
+	restoreMu.Lock()
+	hooks := make([]func(), 0, len(restoreHooks))
+	for id, fn := range restoreHooks {
+		hooks = append(hooks, fn)
+		delete(restoreHooks, id)
+	}
+	restoreMu.Unlock()
+
+	for _, fn := range hooks {
+		fn()
+	}
+
+	if spinnerActive() {
+		fmt.Print("\r\033[K")
+	}
+	fmt.Print(Reset + showCursor)
+}
@@ -139,29 +139,52 @@ func PrintBanner() {
 	fmt.Println()
 }
 
+// quiet keeps stdout for machine-readable output: spinners don't draw and
+// status messages go to stderr
+var quiet bool
+
+// SetQuiet turns quiet output on or off
+func SetQuiet(enabled bool) {
+	quiet = enabled
+}
+
+// statusOut is where status messages are printed
+func statusOut() *os.File {
+	if quiet {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// Statusf prints undecorated status text where status messages go, so it
+// stays off stdout in quiet mode
+func Statusf(format string, args ...interface{}) {
+	fmt.Fprintf(statusOut(), format, args...)
+}
+
 // PrintSuccess prints a success message
 func PrintSuccess(message string) {
-	fmt.Println(Green + "✅ " + message + Reset)
+	fmt.Fprintln(statusOut(), Green+"✅ "+message+Reset)
 }
 
 // PrintError prints an error message
 func PrintError(message string) {
-	fmt.Println(Red + "❌ " + message + Reset)
+	fmt.Fprintln(statusOut(), Red+"❌ "+message+Reset)
 }
 
 // PrintWarning prints a warning message
 func PrintWarning(message string) {
-	fmt.Println(Yellow + "⚠️  " + message + Reset)
+	fmt.Fprintln(statusOut(), Yellow+"⚠️  "+message+Reset)
 }
 
 // PrintInfo prints an info message
 func PrintInfo(message string) {
-	fmt.Println(Blue + "💡 " + message + Reset)
+	fmt.Fprintln(statusOut(), Blue+"💡 "+message+Reset)
 }
 
 // PrintLoading prints a loading message
 func PrintLoading(message string) {
-	fmt.Println(Cyan + "⏳ " + message + Reset)
+	fmt.Fprintln(statusOut(), Cyan+"⏳ "+message+Reset)
 }
 
 // ClearScreen clears the terminal screen
@@ -169,8 +192,26 @@ func ClearScreen() {
 	fmt.Print("\033[2J\033[H")
 }
 
-// TypeText simulates typing effect for text output
+// typingEnabled switches the typing effect of TypeText on or off
+var typingEnabled = true
+
+// SetTyping turns the typing effect on or off for the rest of the session
+func SetTyping(enabled bool) {
+	typingEnabled = enabled
+}
+
+// TypingEnabled reports whether TypeText animates its output
+func TypingEnabled() bool {
+	return typingEnabled
+}
+
+// TypeText simulates typing effect for text output, or prints the text at
+// once when the effect is off
 func TypeText(text string, delay time.Duration) {
+	if !typingEnabled {
+		fmt.Print(text)
+		return
+	}
 	for _, char := range text {
 		fmt.Print(string(char))
 		time.Sleep(delay)
@@ -233,6 +274,23 @@ const (
 	CodeText = "\033[97m"      // Bright white text for code
 )
 
+// ansiSequence matches a single SGR escape sequence such as "\033[48;5;17m"
+var ansiSequence = regexp.MustCompile(`^\x1b\[[0-9;]*m$`)
+
+// CodeColors returns the code block background and text colors from the
+// "code_bg" and "code_fg" theme keys, falling back to the defaults when a
+// key is missing or isn't a valid ANSI sequence
+func CodeColors(colors map[string]string) (bg, fg string) {
+	bg, fg = NavyBlue, CodeText
+	if c, ok := colors["code_bg"]; ok && ansiSequence.MatchString(c) {
+		bg = c
+	}
+	if c, ok := colors["code_fg"]; ok && ansiSequence.MatchString(c) {
+		fg = c
+	}
+	return bg, fg
+}
+
 // Regex patterns for fence detection
 var (
 	fenceStart = regexp.MustCompile(`^\s*(` + "```" + `|~~~)\s*([A-Za-z0-9+#._-]*)\s*$`)